@@ -0,0 +1,100 @@
+package main
+
+import (
+	"booking/export"
+	"booking/models"
+	"booking/repository"
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// monthlyExportHour is the local hour on the first of the month when last month's export is sent
+const monthlyExportHour = 6
+
+// monthlyExport emails the reservations of the previous month to the bookkeeper
+type monthlyExport struct {
+	db      repository.DatabaseRepo
+	mail    chan<- models.MailData
+	to      string
+	format  export.Format
+	columns []export.Column
+	// property sends the email, the export itself covers every property
+	property int
+}
+
+// run exports the stays overlapping the month before now and queues the email
+func (e *monthlyExport) run(ctx context.Context, now time.Time) error {
+	property, err := e.db.GetPropertyByID(ctx, e.property)
+	if err != nil {
+		return err
+	}
+
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	from := thisMonth.AddDate(0, -1, 0)
+	to := thisMonth.AddDate(0, 0, -1)
+
+	buf := new(bytes.Buffer)
+	sheet, err := export.NewSheet(buf, e.format, e.columns)
+	if err != nil {
+		return err
+	}
+
+	filter := models.ReservationFilter{From: from, To: to}
+	if err := e.db.EachReservation(ctx, filter, sheet.Add); err != nil {
+		return err
+	}
+	if err := sheet.Close(); err != nil {
+		return err
+	}
+
+	month := from.Format("January 2006")
+	e.mail <- models.MailData{
+		To:      e.to,
+		From:    property.ContactEmail,
+		Subject: fmt.Sprintf("Reservations for %s", month),
+		Content: fmt.Sprintf("Attached are the reservations staying in %s.", month),
+		Attachments: []models.Attachment{{
+			Name:        e.format.FileName("reservations-" + from.Format("2006-01")),
+			ContentType: e.format.ContentType(),
+			Data:        buf.Bytes(),
+		}},
+	}
+
+	return nil
+}
+
+// nextMonthlyExport returns when the export following now is due
+func nextMonthlyExport(now time.Time) time.Time {
+	due := time.Date(now.Year(), now.Month(), 1, monthlyExportHour, 0, 0, 0, now.Location())
+	if !due.After(now) {
+		due = due.AddDate(0, 1, 0)
+	}
+	return due
+}
+
+// startMonthlyExport sends the export on the first of every month until ctx is done,
+// it is disabled when no address is configured
+func startMonthlyExport(ctx context.Context, e *monthlyExport) {
+	if e.to == "" {
+		return
+	}
+
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextMonthlyExport(time.Now())))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case now := <-timer.C:
+				if err := e.run(ctx, now); err != nil {
+					logrus.WithError(err).Error("cannot send the monthly reservation export")
+				}
+			}
+		}
+	}()
+}
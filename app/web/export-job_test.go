@@ -0,0 +1,57 @@
+package main
+
+import (
+	"booking/export"
+	"booking/mocks"
+	"booking/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextMonthlyExport(t *testing.T) {
+	tests := []struct {
+		now, expected time.Time
+	}{
+		{time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 11, 1, 5, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), time.Date(2026, 12, 1, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, nextMonthlyExport(test.now), test.now.String())
+	}
+}
+
+func TestMonthlyExport_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+
+	cols, _ := export.ParseColumns(nil)
+	mail := make(chan models.MailData, 1)
+	e := &monthlyExport{db: mockDB, mail: mail, to: "books@example.com", property: 1, format: export.CSV, columns: cols}
+
+	filter := models.ReservationFilter{
+		From: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+	}
+	mockDB.EXPECT().GetPropertyByID(gomock.Any(), 1).Return(models.Property{ID: 1, ContactEmail: "desk@example.com"}, nil)
+	mockDB.EXPECT().EachReservation(gomock.Any(), filter, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ models.ReservationFilter, fn func(models.Reservation) error) error {
+			return fn(models.Reservation{ID: 1, LastName: "Nguyen", Status: models.StatusCheckedOut})
+		})
+
+	err := e.run(context.Background(), time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	msg := <-mail
+	assert.Equal(t, "books@example.com", msg.To)
+	assert.Equal(t, "desk@example.com", msg.From)
+	assert.Equal(t, "Reservations for October 2026", msg.Subject)
+	assert.Equal(t, "reservations-2026-10.csv", msg.Attachments[0].Name)
+	assert.Contains(t, string(msg.Attachments[0].Data), "Nguyen")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"booking/config"
+	"booking/importer"
+	"booking/models"
+	"booking/repository"
+	sqldriver "booking/sql_driver"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const importUsage = `Usage: booking import [flags] file.csv
+
+Checks a CSV of reservations and reports the accepted and rejected rows.
+Nothing is saved unless -commit is given.
+
+Flags:
+`
+
+// runImport implements the import subcommand
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dbFlags := addDBFlags(fs)
+	commit := fs.Bool("commit", false, "Save the accepted rows instead of only checking the file")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), importUsage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one file")
+	}
+
+	if !dbFlags.valid() {
+		return errors.New("missing required flags -dbname and -dbuser")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rows, err := importer.Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	db, err := sqldriver.ConnectSQL(dbFlags.dsn())
+	if err != nil {
+		return err
+	}
+	defer db.SQL.Close()
+
+	report := models.ImportReport{Rows: rows, Committed: *commit}
+	repo := repository.NewPostgresRepo(&config.AppConfig{}, db)
+	if err := repo.ImportReservations(context.Background(), report.Rows, report.Committed); err != nil {
+		return err
+	}
+
+	printImportReport(os.Stdout, report)
+	return nil
+}
+
+func printImportReport(out io.Writer, report models.ImportReport) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tNAME\tROOM\tARRIVAL\tDEPARTURE\tRESULT")
+	for _, row := range report.Rows {
+		result := "accepted"
+		if row.Reservation.ID > 0 {
+			result = fmt.Sprintf("imported as %d", row.Reservation.ID)
+		}
+		if !row.Accepted() {
+			result = strings.Join(row.Errors, "; ")
+		}
+		res := row.Reservation
+		fmt.Fprintf(w, "%d\t%s %s\t%d\t%s\t%s\t%s\n", row.Line, res.FirstName, res.LastName, res.RoomID,
+			importDate(res.StartDate), importDate(res.EndDate), result)
+	}
+	w.Flush()
+
+	if report.Committed {
+		fmt.Fprintf(out, "%d reservation(s) imported, %d rejected\n", report.Accepted(), report.Rejected())
+	} else {
+		fmt.Fprintf(out, "dry run: %d reservation(s) accepted, %d rejected, run with -commit to save them\n", report.Accepted(), report.Rejected())
+	}
+}
+
+// importDate leaves dates that could not be parsed blank
+func importDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -2,18 +2,25 @@ package main
 
 import (
 	"booking/config"
+	"booking/export"
 	"booking/handlers"
 	"booking/helpers"
+	"booking/logging"
+	"booking/metrics"
 	"booking/models"
 	"booking/render"
 	"booking/repository"
 	sqldriver "booking/sql_driver"
+	"context"
 	"encoding/gob"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+	// the time zone database, for hosts and containers without one
+	_ "time/tzdata"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/sirupsen/logrus"
@@ -21,10 +28,27 @@ import (
 
 const PORT_NUMBER = ":8080"
 
+// mailQueueSize is the number of emails that can wait for the mail listener
+const mailQueueSize = 100
+
 var session *scs.SessionManager
 var app config.AppConfig
+var monthly = &monthlyExport{}
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+
 	db, err := run()
 	if err != nil {
 		logrus.Fatal(err)
@@ -34,7 +58,14 @@ func main() {
 	defer close(app.MailChan)
 
 	logrus.Info("Starting email listener")
-	listenForMail()
+	listenForMail(handlers.Repo.DB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	startPurgeJob(ctx, handlers.Repo.DB, app.CancelledRetention, purgeInterval)
+	monthly.db = handlers.Repo.DB
+	monthly.mail = app.MailChan
+	startMonthlyExport(ctx, monthly)
 
 	logrus.Infof("Starting application at port %v", PORT_NUMBER)
 
@@ -57,43 +88,73 @@ func run() (*sqldriver.DB, error) {
 
 	// setup flags
 	useCache := flag.Bool("cache", true, "Use template cache")
-	dbHost := flag.String("dbhost", "localhost", "Databse host")
-	dbName := flag.String("dbname", "", "Databse name")
-	dbUser := flag.String("dbuser", "", "Databse user")
-	dbPass := flag.String("dbpass", "", "Databse password")
-	dbPort := flag.String("dbport", "5432", "Databse port")
-	dbSSL := flag.String("dbssl", "disable", "Databse ssl settings (disable, prefer, require)")
+	dbFlags := addDBFlags(flag.CommandLine)
+	dbTimeout := flag.Duration("dbtimeout", 3*time.Second, "Timeout for a single database query")
+	sessionStore := flag.String("sessionstore", sessionStorePostgres, "Session store (postgres, memory)")
+	sessionCleanup := flag.Duration("sessioncleanup", 5*time.Minute, "Interval between removals of expired sessions from postgres")
+	enableMetrics := flag.Bool("metrics", true, "Expose prometheus metrics on /metrics")
+	exportTo := flag.String("exportto", "", "Email address receiving last month's reservations on the first of each month, empty disables it")
+	exportFormat := flag.String("exportformat", string(export.XLSX), "Format of the monthly export (csv, xlsx)")
+	exportColumns := flag.String("exportcolumns", strings.Join(export.DefaultColumns, ","), "Columns of the monthly export")
+	retention := flag.Duration("retention", 30*24*time.Hour, "How long cancelled reservations are kept before being purged, 0 keeps them forever")
+	timeZone := flag.String("timezone", "Local", "Time zone used when a property has none, e.g. Europe/Paris, deciding which day it is today")
+	propertyID := flag.Int("property", 1, "Property shown on the public site")
 
 	flag.Parse()
 
-	if *dbName == "" || *dbUser == "" {
+	logging.Setup()
+
+	if !dbFlags.valid() {
 		logrus.Error("Missing required flags")
 		os.Exit(1)
 	}
 
 	app = config.AppConfig{}
-
-	// session management
-	session = scs.New()
-	session.Lifetime = 24 * time.Hour
-	session.Cookie.Persist = true
-	session.Cookie.SameSite = http.SameSiteLaxMode
-	session.Cookie.Secure = false
-
-	app.Session = session
+	app.EnableMetrics = *enableMetrics
+	app.DBTimeout = *dbTimeout
+	app.CancelledRetention = *retention
+	app.PropertyID = *propertyID
+
+	var err error
+	if app.TimeZone, err = time.LoadLocation(*timeZone); err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", *timeZone, err)
+	}
+	monthly.to = *exportTo
+	monthly.property = *propertyID
+	if monthly.format, err = export.ParseFormat(*exportFormat); err != nil {
+		return nil, err
+	}
+	if monthly.columns, err = export.ParseColumns([]string{*exportColumns}); err != nil {
+		return nil, err
+	}
 
 	// connect to database
 	logrus.Info("Connecting to database...")
-	connectionStr := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s", *dbHost, *dbPort, *dbName, *dbUser, *dbPass, *dbSSL)
-	db, err := sqldriver.ConnectSQL(connectionStr)
+	db, err := sqldriver.ConnectSQL(dbFlags.dsn())
 	if err != nil {
 		logrus.WithError(err).Fatal("Cannot connect to database. Dying...")
 	}
 	logrus.Info("Connected to database!")
 
-	app.MailChan = make(chan models.MailData)
+	// session management
+	session, err = newSessionManager(*sessionStore, db.SQL, *sessionCleanup)
+	if err != nil {
+		return nil, err
+	}
+	app.Session = session
+
+	app.MailChan = make(chan models.MailData, mailQueueSize)
 
 	repoDB := repository.NewPostgresRepo(&app, db)
+	if app.EnableMetrics {
+		repoDB = repository.NewMetricsRepo(repoDB)
+		if err := metrics.RegisterDBStats(db.SQL); err != nil {
+			return nil, err
+		}
+		if err := metrics.RegisterMailQueue(func() int { return len(app.MailChan) }); err != nil {
+			return nil, err
+		}
+	}
 
 	tc, err := render.CreateTemplateCache()
 	if err != nil {
@@ -111,3 +172,32 @@ func run() (*sqldriver.DB, error) {
 
 	return db, nil
 }
+
+// dbFlags holds the command line flags describing the database connection
+type dbFlags struct {
+	host *string
+	name *string
+	user *string
+	pass *string
+	port *string
+	ssl  *string
+}
+
+func addDBFlags(fs *flag.FlagSet) *dbFlags {
+	return &dbFlags{
+		host: fs.String("dbhost", "localhost", "Databse host"),
+		name: fs.String("dbname", "", "Databse name"),
+		user: fs.String("dbuser", "", "Databse user"),
+		pass: fs.String("dbpass", "", "Databse password"),
+		port: fs.String("dbport", "5432", "Databse port"),
+		ssl:  fs.String("dbssl", "disable", "Databse ssl settings (disable, prefer, require)"),
+	}
+}
+
+func (f *dbFlags) valid() bool {
+	return *f.name != "" && *f.user != ""
+}
+
+func (f *dbFlags) dsn() string {
+	return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s", *f.host, *f.port, *f.name, *f.user, *f.pass, *f.ssl)
+}
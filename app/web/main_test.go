@@ -1,12 +1,22 @@
 package main
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestRun(t *testing.T) {
-	err := run()
+	// run needs a live postgres database, e.g. BOOKING_TEST_ARGS="-dbname=booking -dbuser=postgres"
+	args := os.Getenv("BOOKING_TEST_ARGS")
+	if args == "" {
+		t.Skip("BOOKING_TEST_ARGS not set, skipping test against a live database")
+	}
+	os.Args = append([]string{os.Args[0]}, strings.Fields(args)...)
+
+	db, err := run()
 	assert.NoError(t, err)
+	db.SQL.Close()
 }
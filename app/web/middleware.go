@@ -3,14 +3,53 @@ package main
 
 import (
 	"booking/helpers"
+	"booking/logging"
+	"booking/metrics"
+	"booking/repository"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/justinas/nosurf"
+	"github.com/sirupsen/logrus"
 )
 
+// LogRequest attaches a request scoped logger to the context and writes one structured entry per request.
+// It relies on middleware.RequestID running first.
 func LogRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		next.ServeHTTP(rw, r)
+		start := time.Now()
+		reqID := middleware.GetReqID(r.Context())
+
+		entry := logrus.WithField("request_id", reqID)
+		ctx := logging.WithLogger(r.Context(), entry)
+
+		rw.Header().Set(logging.RequestIDHeader, reqID)
+		ww := middleware.NewWrapResponseWriter(rw, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		route := ""
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		entry.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"route":       route,
+			"status":      status,
+			"bytes":       ww.BytesWritten(),
+			"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"remote_addr": r.RemoteAddr,
+		}).Info("request completed")
 	})
 }
 
@@ -37,3 +76,35 @@ func Auth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Metrics records the count and latency of requests per chi route pattern
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		// the pattern is only known once chi has routed the request
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// AuditActor records the logged in user in the request context so that repository changes are attributed to them
+func AuditActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := session.GetInt(r.Context(), "user_id")
+		next.ServeHTTP(w, r.WithContext(repository.WithActor(r.Context(), userID)))
+	})
+}
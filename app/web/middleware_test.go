@@ -2,8 +2,16 @@
 package main
 
 import (
+	"booking/logging"
+	"booking/metrics"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNoSurf(t *testing.T) {
@@ -17,3 +25,35 @@ func TestNoSurf(t *testing.T) {
 		t.Errorf("type is not http.Handler, but is %T", v)
 	}
 }
+
+func TestMetrics(t *testing.T) {
+	mux := chi.NewRouter()
+	mux.Use(Metrics)
+	mux.Get("/choose-room/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusSeeOther)
+	})
+
+	counter := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/choose-room/{id}", "303")
+	before := testutil.ToFloat64(counter)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/choose-room/1", nil))
+
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestLogRequest(t *testing.T) {
+	var ctxLogger interface{}
+	h := middleware.RequestID(LogRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxLogger = logging.FromContext(r.Context()).Data["request_id"]
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(logging.RequestIDHeader, "my-request-id")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, "my-request-id", rr.Header().Get(logging.RequestIDHeader))
+	assert.Equal(t, "my-request-id", ctxLogger)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"booking/migrations"
+	sqldriver "booking/sql_driver"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+const migrateUsage = `Usage: booking migrate [flags] up|down|status
+
+Commands:
+  up      apply pending migrations (all of them unless -steps is given)
+  down    roll back the latest migrations (one unless -steps is given)
+  status  list migrations and whether they are applied
+
+Flags:
+`
+
+// runMigrate implements the migrate subcommand
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dbFlags := addDBFlags(fs)
+	setName := fs.String("set", migrations.Schema.Name, "Migrations to run: schema, or seed for demo data")
+	steps := fs.Int("steps", 0, "Number of migrations to apply or roll back")
+	dryRun := fs.Bool("dry-run", false, "Print the SQL instead of executing it")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one command")
+	}
+
+	if !dbFlags.valid() {
+		return errors.New("missing required flags -dbname and -dbuser")
+	}
+
+	var set migrations.Set
+	switch *setName {
+	case migrations.Schema.Name:
+		set = migrations.Schema
+	case migrations.Seed.Name:
+		set = migrations.Seed
+	default:
+		return fmt.Errorf("unknown migration set %q", *setName)
+	}
+
+	db, err := sqldriver.ConnectSQL(dbFlags.dsn())
+	if err != nil {
+		return err
+	}
+	defer db.SQL.Close()
+
+	m, err := migrations.New(db.SQL, set, os.Stdout)
+	if err != nil {
+		return err
+	}
+	m.DryRun = *dryRun
+
+	ctx := context.Background()
+
+	// a dry run only tells what would change
+	would := ""
+	if m.DryRun {
+		would = "would be "
+	}
+
+	switch fs.Arg(0) {
+	case "up":
+		n, err := m.Up(ctx, *steps)
+		fmt.Printf("%d %s migration(s) %sapplied\n", n, set.Name, would)
+		return err
+	case "down":
+		n, err := m.Down(ctx, *steps)
+		fmt.Printf("%d %s migration(s) %srolled back\n", n, set.Name, would)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(os.Stdout, statuses)
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+}
+
+func printStatus(out io.Writer, statuses []migrations.Status) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, at := "pending", ""
+		if s.Applied {
+			state, at = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+	}
+	w.Flush()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"booking/repository"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// purgeInterval is how often the trash is checked for reservations past the retention period
+const purgeInterval = time.Hour
+
+// purgeCancelled permanently removes reservations cancelled more than retention ago
+func purgeCancelled(ctx context.Context, db repository.DatabaseRepo, retention time.Duration, now time.Time) {
+	n, err := db.PurgeCancelledReservations(ctx, now.Add(-retention))
+	if err != nil {
+		logrus.WithError(err).Error("cannot purge cancelled reservations")
+		return
+	}
+	if n > 0 {
+		logrus.WithField("count", n).Info("purged cancelled reservations")
+	}
+}
+
+// startPurgeJob runs purgeCancelled every interval until ctx is done, a zero retention disables it
+func startPurgeJob(ctx context.Context, db repository.DatabaseRepo, retention, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		purgeCancelled(ctx, db, retention, time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				purgeCancelled(ctx, db, retention, now)
+			}
+		}
+	}()
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"booking/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestPurgeCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	mockDB.EXPECT().PurgeCancelledReservations(gomock.Any(), now.Add(-retention)).Return(2, nil)
+	purgeCancelled(context.Background(), mockDB, retention, now)
+
+	// errors are logged, the job keeps running
+	mockDB.EXPECT().PurgeCancelledReservations(gomock.Any(), gomock.Any()).Return(0, errors.New("boom"))
+	purgeCancelled(context.Background(), mockDB, retention, now)
+}
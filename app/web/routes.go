@@ -3,6 +3,7 @@ package main
 import (
 	"booking/config"
 	"booking/handlers"
+	"booking/metrics"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,47 +13,104 @@ import (
 func routes(app *config.AppConfig) http.Handler {
 	mux := chi.NewRouter()
 
-	mux.Use(middleware.Logger)
+	mux.Use(middleware.RequestID)
 	mux.Use(LogRequest)
+	if app.EnableMetrics {
+		mux.Use(Metrics)
+	}
 	mux.Use(NoSurf)
 	mux.Use(session.LoadAndSave)
 
-	mux.Get("/", handlers.Repo.Home)
-	mux.Get("/about", handlers.Repo.About)
-	mux.Get("/generals-quarters", handlers.Repo.Generals)
-	mux.Get("/majors-suite", handlers.Repo.Majors)
-	mux.Get("/choose-room/{id}", handlers.Repo.ChooseRoom)
-	mux.Get("/book-room", handlers.Repo.BookRoom)
+	mux.Group(func(r chi.Router) {
+		r.Use(handlers.Repo.PublicPropertyScope)
+		r.Use(handlers.Repo.RoomTypesMenu)
 
-	mux.Get("/search-availability", handlers.Repo.Availability)
-	mux.Post("/search-availability", handlers.Repo.PostAvailability)
-	mux.Post("/search-availability-json", handlers.Repo.AvailabilityJSON)
-	mux.Get("/contact", handlers.Repo.Contact)
+		r.Get("/", handlers.Repo.Home)
+		r.Get("/about", handlers.Repo.About)
+		r.Get("/rooms/{id}", handlers.Repo.Room)
+		r.Get("/generals-quarters", handlers.Repo.Generals)
+		r.Get("/majors-suite", handlers.Repo.Majors)
+		r.Get("/choose-room/{id}", handlers.Repo.ChooseRoom)
+		r.Get("/book-room", handlers.Repo.BookRoom)
 
-	mux.Get("/make-reservation", handlers.Repo.Reservation)
-	mux.Post("/make-reservation", handlers.Repo.PostReservation)
-	mux.Get("/reservation-summary", handlers.Repo.ReservationSummary)
+		r.Get("/search-availability", handlers.Repo.Availability)
+		r.Post("/search-availability", handlers.Repo.PostAvailability)
+		r.Post("/search-availability-json", handlers.Repo.AvailabilityJSON)
+		r.Get("/contact", handlers.Repo.Contact)
 
-	mux.Get("/user/login", handlers.Repo.ShowLogin)
-	mux.Post("/user/login", handlers.Repo.PostLogin)
-	mux.Get("/user/logout", handlers.Repo.Logout)
+		r.Get("/make-reservation", handlers.Repo.Reservation)
+		r.Post("/make-reservation", handlers.Repo.PostReservation)
+		r.Get("/reservation-summary", handlers.Repo.ReservationSummary)
+
+		r.Get("/user/login", handlers.Repo.ShowLogin)
+		r.Post("/user/login", handlers.Repo.PostLogin)
+		r.Get("/user/logout", handlers.Repo.Logout)
+	})
+
+	if app.EnableMetrics {
+		mux.Handle("/metrics", metrics.Handler())
+	}
 
 	fileServer := http.FileServer(http.Dir("./static/"))
 	mux.Handle("/static/*", http.StripPrefix("/static", fileServer))
 
 	mux.Route("/admin", func(r chi.Router) {
 		//r.Use(Auth)
+		r.Use(AuditActor)
+		r.Use(handlers.Repo.AdminPropertyScope)
 		r.Get("/dashboard", handlers.Repo.AdminDashboard)
-		r.Get("/reservations-new", handlers.Repo.AdminNewReservation)
-		r.Get("/reservations-all", handlers.Repo.AdminAllReservation)
+		r.Get("/dashboard/occupancy", handlers.Repo.AdminOccupancyJSON)
+		r.Get("/dashboard/stays", handlers.Repo.AdminStaysJSON)
+		r.Get("/reservations", handlers.Repo.AdminReservations)
+		r.Get("/reservations/export", handlers.Repo.AdminExportReservations)
+		r.Get("/reservations/import", handlers.Repo.AdminImportReservations)
+		r.Post("/reservations/import", handlers.Repo.AdminPostImportReservations)
+		r.Get("/reservations/new", handlers.Repo.AdminNewReservation)
+		r.Post("/reservations/new", handlers.Repo.AdminPostNewReservation)
+		// the lists used to live on their own pages, keep old bookmarks working
+		r.Handle("/reservations-new", http.RedirectHandler("/admin/reservations?status=pending", http.StatusMovedPermanently))
+		r.Handle("/reservations-all", http.RedirectHandler("/admin/reservations", http.StatusMovedPermanently))
 		r.Get("/reservations-calendar", handlers.Repo.AdminReservationCalendar)
 		r.Post("/reservations-calendar", handlers.Repo.AdminPostReservationCalendar)
+		r.Get("/reservations-timeline", handlers.Repo.AdminCalendarTimeline)
+		r.Get("/calendar/restrictions", handlers.Repo.AdminCalendarJSON)
+		r.Post("/calendar/blocks", handlers.Repo.AdminCalendarBlock)
+		r.Post("/calendar/blocks/{id}/delete", handlers.Repo.AdminCalendarDeleteBlock)
+		r.Post("/calendar/reservations/{id}/move", handlers.Repo.AdminCalendarMove)
 
-		r.Get("/process-reservation/{src}/{id}/do", handlers.Repo.AdminProcessReservation)
-		r.Get("/delete-reservation/{src}/{id}/do", handlers.Repo.AdminDeleteReservation)
+		r.Get("/front-desk", handlers.Repo.AdminFrontDesk)
+		r.Get("/room-assignment", handlers.Repo.AdminRoomAssignment)
+		r.Post("/room-assignment/{id}", handlers.Repo.AdminPostRoomAssignment)
+		r.Get("/stay-rules", handlers.Repo.AdminStayRules)
+		r.Post("/stay-rules", handlers.Repo.AdminPostStayRule)
+		r.Post("/stay-rules/{id}/delete", handlers.Repo.AdminDeleteStayRule)
+		r.Get("/rates", handlers.Repo.AdminRates)
+		r.Post("/rates/{id}", handlers.Repo.AdminPostRate)
+		r.Get("/promo-codes", handlers.Repo.AdminPromoCodes)
+		r.Post("/promo-codes", handlers.Repo.AdminPostPromoCode)
+		r.Post("/promo-codes/{id}/active", handlers.Repo.AdminTogglePromoCode)
+		r.Get("/cancellation-policies", handlers.Repo.AdminCancellationPolicies)
+		r.Post("/cancellation-policies", handlers.Repo.AdminPostCancellationPolicy)
+		r.Post("/cancellation-policies/{id}/delete", handlers.Repo.AdminDeleteCancellationPolicy)
+
+		r.Post("/reservations/{src}/{id}/status", handlers.Repo.AdminTransitionReservation)
+		r.Post("/reservations/{src}/{id}/notes", handlers.Repo.AdminAddReservationNote)
+		r.Post("/reservations/{src}/{id}/email", handlers.Repo.AdminSendGuestEmail)
+		r.Post("/reservations/{src}/{id}/move", handlers.Repo.AdminMoveReservation)
+		r.Get("/outbox/{id}", handlers.Repo.AdminShowOutboxMail)
+		r.Post("/cancel-reservation/{src}/{id}", handlers.Repo.AdminCancelReservation)
+		r.Get("/reservations-trash", handlers.Repo.AdminTrashReservations)
+		r.Post("/restore-reservation/{id}", handlers.Repo.AdminRestoreReservation)
 
 		r.Get("/reservations/{src}/{id}/show", handlers.Repo.AdminShowReservation)
 		r.Post("/reservations/{src}/{id}", handlers.Repo.AdminPostReservation)
+
+		r.Get("/audit", handlers.Repo.AdminAuditLog)
+		r.Get("/audit/export", handlers.Repo.AdminAuditLogExport)
+
+		r.Get("/property", handlers.Repo.AdminPropertySettings)
+		r.Post("/property", handlers.Repo.AdminPostPropertySettings)
+		r.Post("/property/switch", handlers.Repo.AdminSwitchProperty)
 	})
 
 	return mux
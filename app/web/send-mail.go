@@ -1,7 +1,11 @@
 package main
 
 import (
+	"booking/logging"
+	"booking/metrics"
 	"booking/models"
+	"booking/repository"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -11,19 +15,40 @@ import (
 	mail "github.com/xhit/go-simple-mail/v2"
 )
 
-func listenForMail() {
+// listenForMail sends the queued emails, recording the outcome of those kept in the outbox
+func listenForMail(outbox repository.DatabaseRepo) {
 	go func() {
 		logrus.Info("listenForMail goroutine created")
 		defer logrus.Info("listenForMail destroyed")
 		for {
 			msg := <-app.MailChan
-			logrus.WithField("msg", msg).Info("listenForMail receives an email request")
-			sendMsg(msg)
+			ctx := mailContext(msg)
+			logging.FromContext(ctx).WithFields(logrus.Fields{
+				"to":      msg.To,
+				"subject": msg.Subject,
+			}).Info("listenForMail receives an email request")
+			err := sendMsg(ctx, msg)
+			if msg.ID > 0 {
+				if err := outbox.MarkMailSent(ctx, msg.ID, err); err != nil {
+					logging.FromContext(ctx).WithError(err).Error("cannot record the email in the outbox")
+				}
+			}
 		}
 	}()
 }
 
-func sendMsg(m models.MailData) {
+// mailContext carries the logger of the request email m was sent from, so that its logs can be matched
+// to the request
+func mailContext(m models.MailData) context.Context {
+	ctx := context.Background()
+	if m.RequestID != "" {
+		ctx = logging.WithLogger(ctx, logrus.WithField("request_id", m.RequestID))
+	}
+	return ctx
+}
+
+func sendMsg(ctx context.Context, m models.MailData) error {
+	log := logging.FromContext(ctx)
 	server := mail.NewSMTPClient()
 	server.Host = "localhost"
 	server.Port = 1025
@@ -33,11 +58,12 @@ func sendMsg(m models.MailData) {
 
 	client, err := server.Connect()
 	if err != nil {
-		logrus.WithError(err).Error("cannot connect server")
-		return
+		log.WithError(err).Error("cannot connect server")
+		metrics.MailFailures.Inc()
+		return err
 	}
 
-	logrus.Info("Connected to email server")
+	log.Info("Connected to email server")
 	email := mail.NewMSG()
 	email.SetFrom(m.From).AddTo(m.To).SetSubject(m.Subject)
 	if m.Template == "" {
@@ -45,18 +71,27 @@ func sendMsg(m models.MailData) {
 	} else {
 		data, err := ioutil.ReadFile(fmt.Sprintf("./email-templates/%s", m.Template))
 		if err != nil {
-			logrus.WithError(err).Error("cannot read file")
-			return
+			log.WithError(err).Error("cannot read file")
+			metrics.MailFailures.Inc()
+			return err
 		}
 		mailTemplate := string(data)
 		msgToSend := strings.Replace(mailTemplate, "[%body%]", m.Content, 1)
 		email.SetBody(mail.TextHTML, msgToSend)
 	}
 
+	for _, a := range m.Attachments {
+		email.Attach(&mail.File{Name: a.Name, MimeType: a.ContentType, Data: a.Data})
+	}
+
 	err = email.Send(client)
 	if err != nil {
-		logrus.WithError(err).Error("cannot send email")
-	} else {
-		logrus.Info("Email sent!")
+		log.WithError(err).Error("cannot send email")
+		metrics.MailFailures.Inc()
+		return err
 	}
+
+	log.Info("Email sent!")
+	metrics.MailSent.Inc()
+	return nil
 }
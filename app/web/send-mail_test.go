@@ -0,0 +1,19 @@
+package main
+
+import (
+	"booking/logging"
+	"booking/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailContext(t *testing.T) {
+	// the logs of an email sent from a request carry its id
+	ctx := mailContext(models.MailData{RequestID: "req-7"})
+	assert.Equal(t, "req-7", logging.FromContext(ctx).Data["request_id"])
+
+	// background jobs have no request
+	ctx = mailContext(models.MailData{})
+	assert.NotContains(t, logging.FromContext(ctx).Data, "request_id")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alexedwards/scs/postgresstore"
+	"github.com/alexedwards/scs/v2"
+	"github.com/alexedwards/scs/v2/memstore"
+)
+
+const (
+	sessionStoreMemory   = "memory"
+	sessionStorePostgres = "postgres"
+)
+
+// newSessionManager configures the session manager with the requested store. The postgres store
+// keeps sessions across restarts and shares them between instances, the memory store is meant
+// for development and tests.
+func newSessionManager(store string, db *sql.DB, cleanupInterval time.Duration) (*scs.SessionManager, error) {
+	s := scs.New()
+	s.Lifetime = 24 * time.Hour
+	s.Cookie.Persist = true
+	s.Cookie.SameSite = http.SameSiteLaxMode
+	s.Cookie.Secure = false
+
+	switch store {
+	case sessionStorePostgres:
+		// expired rows are removed by a background job every cleanupInterval
+		s.Store = postgresstore.NewWithCleanupInterval(db, cleanupInterval)
+	case sessionStoreMemory:
+		s.Store = memstore.New()
+	default:
+		return nil, fmt.Errorf("unknown session store %q, use %s or %s", store, sessionStorePostgres, sessionStoreMemory)
+	}
+
+	return s, nil
+}
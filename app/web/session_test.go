@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alexedwards/scs/postgresstore"
+	"github.com/alexedwards/scs/v2/memstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSessionManager(t *testing.T) {
+	s, err := newSessionManager(sessionStoreMemory, nil, 0)
+	assert.NoError(t, err)
+	assert.IsType(t, &memstore.MemStore{}, s.Store)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// a zero interval disables the cleanup job
+	s, err = newSessionManager(sessionStorePostgres, db, 0)
+	assert.NoError(t, err)
+	assert.IsType(t, &postgresstore.PostgresStore{}, s.Store)
+
+	_, err = newSessionManager("redis", nil, 0)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"booking/models"
 	"html/template"
+	"time"
 
 	"github.com/alexedwards/scs/v2"
 )
@@ -15,6 +16,16 @@ type AppConfig struct {
 	TemplateCache map[string]*template.Template
 	Session       *scs.SessionManager
 	MailChan      chan models.MailData
+	// EnableMetrics exposes prometheus metrics on /metrics
+	EnableMetrics bool
+	// DBTimeout bounds every database query
+	DBTimeout time.Duration
+	// CancelledRetention is how long cancelled reservations stay in the trash before being purged
+	CancelledRetention time.Duration
+	// TimeZone is used for properties without one, the day it is there is today for arrivals, departures and reports
+	TimeZone *time.Location
+	// PropertyID is the property shown on the public site
+	PropertyID int
 }
 
 func (a *AppConfig) GetTemplateCache() map[string]*template.Template {
@@ -0,0 +1,119 @@
+// Package dates handles the calendar dates of stays. A date is a day on the calendar of the property, with
+// no time of day and no time zone. Dates are stored in postgres date columns, which come back as midnight
+// UTC, so that is the time.Time a Date converts to and from.
+package dates
+
+import (
+	"errors"
+	"time"
+)
+
+// Layout is the format of dates in forms, query strings and JSON
+const Layout = "2006-01-02"
+
+// ErrInvalid is returned when parsing a string that is not a date in Layout
+var ErrInvalid = errors.New("invalid date, use YYYY-MM-DD")
+
+// Date is a day on the calendar, the zero value is January 1, year 1
+type Date struct {
+	t time.Time
+}
+
+// New returns the date of the day d of month m in year y, values out of range are normalized
+// the way time.Date does it, e.g. October 32 is November 1
+func New(y int, m time.Month, d int) Date {
+	return Date{t: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+// Of returns the date t falls on in its own location
+func Of(t time.Time) Date {
+	y, m, d := t.Date()
+	return New(y, m, d)
+}
+
+// Today returns the current date in loc, a nil loc is UTC
+func Today(loc *time.Location) Date {
+	return dateIn(time.Now(), loc)
+}
+
+// dateIn returns the date t falls on in loc, a nil loc is UTC
+func dateIn(t time.Time, loc *time.Location) Date {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return Of(t.In(loc))
+}
+
+// Parse reads a date in Layout
+func Parse(s string) (Date, error) {
+	t, err := time.Parse(Layout, s)
+	if err != nil {
+		return Date{}, ErrInvalid
+	}
+	return Date{t: t}, nil
+}
+
+// Time returns the date at midnight UTC, the way date columns store it
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// String formats the date in Layout
+func (d Date) String() string {
+	return d.t.Format(Layout)
+}
+
+// IsZero tells whether d is the zero date
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+// Weekday returns the day of the week of the date
+func (d Date) Weekday() time.Weekday {
+	return d.t.Weekday()
+}
+
+// AddDays returns the date n days after d, n may be negative
+func (d Date) AddDays(n int) Date {
+	return Date{t: d.t.AddDate(0, 0, n)}
+}
+
+// Sub returns the number of days from o to d, the nights of a stay arriving on o and leaving on d
+func (d Date) Sub(o Date) int {
+	// both are UTC midnights, a day is always 24 hours
+	return int(d.t.Sub(o.t) / (24 * time.Hour))
+}
+
+// Before tells whether d is before o
+func (d Date) Before(o Date) bool {
+	return d.t.Before(o.t)
+}
+
+// After tells whether d is after o
+func (d Date) After(o Date) bool {
+	return d.t.After(o.t)
+}
+
+// Nights returns the number of nights between the dates of two times, whatever their time of day
+// and location
+func Nights(start, end time.Time) int {
+	return Of(end).Sub(Of(start))
+}
+
+// Range is a stay or a period of nights, from Start up to but not including End. The day of departure
+// is not part of the stay, the room is free for the next guest to arrive that day; repository queries
+// look for clashes with the same rule.
+type Range struct {
+	Start Date
+	End   Date
+}
+
+// Nights is the number of nights in the range
+func (r Range) Nights() int {
+	return r.End.Sub(r.Start)
+}
+
+// String formats the range as its first date and the day of departure
+func (r Range) String() string {
+	return r.Start.String() + " to " + r.End.String()
+}
@@ -0,0 +1,124 @@
+package dates
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func location(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  Date
+		valid bool
+	}{
+		{"2026-10-19", New(2026, 10, 19), true},
+		{"2028-02-29", New(2028, 2, 29), true},
+		{"2026-12-31", New(2026, 12, 31), true},
+		{"2026-02-29", Date{}, false},
+		{"2026-02-30", Date{}, false},
+		{"2026-13-01", Date{}, false},
+		{"2026-1-5", Date{}, false},
+		{"05/01/2026", Date{}, false},
+		{"2026-10-19T00:00:00Z", Date{}, false},
+		{"", Date{}, false},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.in)
+		if !test.valid {
+			assert.Equal(t, ErrInvalid, err, test.in)
+			assert.True(t, got.IsZero(), test.in)
+			continue
+		}
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+		assert.Equal(t, test.in, got.String(), test.in)
+		assert.Equal(t, time.UTC, got.Time().Location(), test.in)
+	}
+}
+
+func TestDateIn(t *testing.T) {
+	auckland := location(t, "Pacific/Auckland")
+	angeles := location(t, "America/Los_Angeles")
+
+	tests := []struct {
+		name string
+		at   time.Time
+		loc  *time.Location
+		want Date
+	}{
+		{"utc", time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC), time.UTC, New(2026, 10, 19)},
+		{"nil is utc", time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC), nil, New(2026, 10, 19)},
+		{"ahead of utc", time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC), auckland, New(2026, 10, 20)},
+		{"behind utc", time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC), angeles, New(2026, 10, 19)},
+		{"new year ahead", time.Date(2026, 12, 31, 12, 0, 0, 0, time.UTC), auckland, New(2027, 1, 1)},
+		{"new year behind", time.Date(2027, 1, 1, 7, 59, 0, 0, time.UTC), angeles, New(2026, 12, 31)},
+		{"last second of the day", time.Date(2026, 10, 19, 23, 59, 59, 0, angeles), angeles, New(2026, 10, 19)},
+		{"first second of the day", time.Date(2026, 10, 20, 0, 0, 0, 0, angeles), angeles, New(2026, 10, 20)},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, dateIn(test.at, test.loc), test.name)
+	}
+}
+
+func TestNights(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+	york := location(t, "America/New_York")
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       int
+	}{
+		{"stored dates", New(2026, 10, 10).Time(), New(2026, 10, 13).Time(), 3},
+		{"same day", New(2026, 10, 10).Time(), New(2026, 10, 10).Time(), 0},
+		{"across the end of february", New(2028, 2, 27).Time(), New(2028, 3, 2).Time(), 4},
+		{"across new year", New(2026, 12, 30).Time(), New(2027, 1, 2).Time(), 3},
+		// a 23 hour night
+		{"spring forward", time.Date(2026, 3, 28, 0, 0, 0, 0, berlin), time.Date(2026, 3, 30, 0, 0, 0, 0, berlin), 2},
+		// a 25 hour night
+		{"fall back", time.Date(2026, 10, 24, 0, 0, 0, 0, berlin), time.Date(2026, 10, 26, 0, 0, 0, 0, berlin), 2},
+		{"spring forward in new york", time.Date(2026, 3, 7, 0, 0, 0, 0, york), time.Date(2026, 3, 9, 0, 0, 0, 0, york), 2},
+		// check-in in the afternoon, check-out in the morning
+		{"times of day", time.Date(2026, 10, 24, 15, 0, 0, 0, berlin), time.Date(2026, 10, 26, 10, 0, 0, 0, berlin), 2},
+		{"late arrival, early departure", time.Date(2026, 10, 10, 23, 0, 0, 0, time.UTC), time.Date(2026, 10, 12, 1, 0, 0, 0, time.UTC), 2},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Nights(test.start, test.end), test.name)
+	}
+}
+
+func TestDate_AddDays(t *testing.T) {
+	tests := []struct {
+		name string
+		from Date
+		days int
+		want Date
+	}{
+		{"next day", New(2026, 10, 19), 1, New(2026, 10, 20)},
+		{"previous day", New(2026, 10, 19), -1, New(2026, 10, 18)},
+		{"end of month", New(2026, 10, 31), 1, New(2026, 11, 1)},
+		{"leap day", New(2028, 2, 28), 1, New(2028, 2, 29)},
+		{"no leap day", New(2026, 2, 28), 1, New(2026, 3, 1)},
+		{"across spring forward", New(2026, 3, 28), 2, New(2026, 3, 30)},
+		{"across fall back", New(2026, 10, 24), 2, New(2026, 10, 26)},
+	}
+
+	for _, test := range tests {
+		got := test.from.AddDays(test.days)
+		assert.Equal(t, test.want, got, test.name)
+		assert.Equal(t, test.days, got.Sub(test.from), test.name)
+	}
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// formulaPrefixes start cells that spreadsheet programs evaluate when opening a CSV file
+const formulaPrefixes = "=+-@\t\r"
+
+func (c *csvWriter) writeRow(values []string, numbers []bool) error {
+	for i, v := range values {
+		// guest input such as "=HYPERLINK(...)" must show up as text, not run as a formula
+		if !numbers[i] && v != "" && strings.ContainsRune(formulaPrefixes, rune(v[0])) {
+			values[i] = "'" + v
+		}
+	}
+	return c.w.Write(values)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,166 @@
+// Package export writes reservations as CSV or XLSX spreadsheets for accounting
+package export
+
+import (
+	"booking/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat validates a format coming from user input
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case CSV, XLSX:
+		return f, nil
+	}
+	return "", models.NewValidationError("format", fmt.Sprintf("unknown export format %q, use csv or xlsx", s))
+}
+
+// ContentType is the MIME type of files in format f
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// FileName names an export file after its content and the format extension
+func (f Format) FileName(name string) string {
+	return name + "." + string(f)
+}
+
+// Column is one column of an export
+type Column struct {
+	Key    string
+	Header string
+	// Number columns are written as numbers in XLSX so they can be summed
+	Number bool
+	value  func(models.Reservation) string
+}
+
+const dateLayout = "2006-01-02"
+
+var columns = []Column{
+	{Key: "id", Header: "ID", Number: true, value: func(r models.Reservation) string { return strconv.Itoa(r.ID) }},
+	{Key: "guest", Header: "Guest", value: func(r models.Reservation) string { return r.FirstName + " " + r.LastName }},
+	{Key: "email", Header: "Email", value: func(r models.Reservation) string { return r.Email }},
+	{Key: "phone", Header: "Phone", value: func(r models.Reservation) string { return r.Phone }},
+	{Key: "room", Header: "Room", value: func(r models.Reservation) string { return r.Room.RoomName }},
+	{Key: "adults", Header: "Adults", Number: true, value: func(r models.Reservation) string { return strconv.Itoa(r.Adults) }},
+	{Key: "children", Header: "Children", Number: true, value: func(r models.Reservation) string { return strconv.Itoa(r.Children) }},
+	{Key: "arrival", Header: "Arrival", value: func(r models.Reservation) string { return r.StartDate.Format(dateLayout) }},
+	{Key: "departure", Header: "Departure", value: func(r models.Reservation) string { return r.EndDate.Format(dateLayout) }},
+	{Key: "nights", Header: "Nights", Number: true, value: func(r models.Reservation) string { return strconv.Itoa(r.Nights()) }},
+	{Key: "subtotal", Header: "Subtotal", Number: true, value: func(r models.Reservation) string { return r.Subtotal.String() }},
+	{Key: "discount", Header: "Discount", Number: true, value: func(r models.Reservation) string { return r.Discount.String() }},
+	{Key: "total", Header: "Total", Number: true, value: func(r models.Reservation) string { return r.Total.String() }},
+	{Key: "promo_code", Header: "Promo code", value: func(r models.Reservation) string { return r.PromoCode.Code }},
+	{Key: "cancellation_policy", Header: "Cancellation policy", value: func(r models.Reservation) string { return r.CancellationPolicy.Name }},
+	{Key: "penalty", Header: "Penalty", Number: true, value: func(r models.Reservation) string { return r.Penalty.String() }},
+	{Key: "refund", Header: "Refund", Number: true, value: func(r models.Reservation) string { return r.Refund.String() }},
+	{Key: "status", Header: "Status", value: func(r models.Reservation) string { return r.Status.Label() }},
+	{Key: "created", Header: "Booked", value: func(r models.Reservation) string { return r.CreatedAt.Format(dateLayout) }},
+}
+
+// DefaultColumns are exported when no columns are asked for
+var DefaultColumns = []string{"id", "guest", "room", "arrival", "departure", "nights", "status"}
+
+// Columns lists every column that can be exported
+func Columns() []Column {
+	return columns
+}
+
+// ParseColumns returns the columns named by keys in that order, keys may also be comma separated lists
+func ParseColumns(keys []string) ([]Column, error) {
+	var names []string
+	for _, k := range keys {
+		for _, name := range strings.Split(k, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	var cols []Column
+	for _, name := range names {
+		found := false
+		for _, c := range columns {
+			if c.Key == name {
+				cols = append(cols, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, models.NewValidationError("columns", fmt.Sprintf("unknown column %q", name))
+		}
+	}
+
+	return cols, nil
+}
+
+// rowWriter is implemented by each file format
+type rowWriter interface {
+	writeRow(values []string, numbers []bool) error
+	close() error
+}
+
+// Sheet streams reservations into a spreadsheet, rows are written as they are added
+type Sheet struct {
+	w       rowWriter
+	cols    []Column
+	numbers []bool
+}
+
+// NewSheet writes the header row of an export with cols to w
+func NewSheet(w io.Writer, format Format, cols []Column) (*Sheet, error) {
+	s := &Sheet{cols: cols, numbers: make([]bool, len(cols))}
+
+	switch format {
+	case CSV:
+		s.w = newCSVWriter(w)
+	case XLSX:
+		xw, err := newXLSXWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		s.w = xw
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.Header
+	}
+
+	return s, s.w.writeRow(headers, s.numbers)
+}
+
+// Add writes one reservation
+func (s *Sheet) Add(r models.Reservation) error {
+	values := make([]string, len(s.cols))
+	for i, c := range s.cols {
+		values[i] = c.value(r)
+		s.numbers[i] = c.Number
+	}
+	return s.w.writeRow(values, s.numbers)
+}
+
+// Close finishes the file, nothing may be added afterwards
+func (s *Sheet) Close() error {
+	return s.w.close()
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"archive/zip"
+	"booking/models"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var reservation = models.Reservation{
+	ID:        7,
+	FirstName: "=cmd",
+	LastName:  "<Nguyen> & Co",
+	Room:      models.Room{RoomName: "General's Quarters"},
+	StartDate: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+	EndDate:   time.Date(2026, 11, 4, 0, 0, 0, 0, time.UTC),
+	Status:    models.StatusConfirmed,
+}
+
+func TestParseColumns(t *testing.T) {
+	cols, err := ParseColumns(nil)
+	assert.NoError(t, err)
+	assert.Len(t, cols, len(DefaultColumns))
+
+	cols, err = ParseColumns([]string{"guest, nights", "status"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Guest", cols[0].Header)
+	assert.Equal(t, "Nights", cols[1].Header)
+	assert.Equal(t, "Status", cols[2].Header)
+
+	_, err = ParseColumns([]string{"password"})
+	assert.True(t, errors.Is(err, models.ErrValidation))
+}
+
+func TestSheet_CSV(t *testing.T) {
+	cols, _ := ParseColumns([]string{"id,guest,room,arrival,nights,status"})
+	buf := new(bytes.Buffer)
+
+	s, err := NewSheet(buf, CSV, cols)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Add(reservation))
+	assert.NoError(t, s.Close())
+
+	expected := "ID,Guest,Room,Arrival,Nights,Status\n" +
+		"7,'=cmd <Nguyen> & Co,General's Quarters,2026-11-01,3,Confirmed\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestSheet_XLSX(t *testing.T) {
+	cols, _ := ParseColumns([]string{"guest", "nights"})
+	buf := new(bytes.Buffer)
+
+	s, err := NewSheet(buf, XLSX, cols)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Add(reservation))
+	assert.NoError(t, s.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			data, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			sheet = string(data)
+		}
+	}
+
+	assert.Contains(t, sheet, `<t xml:space="preserve">=cmd &lt;Nguyen&gt; &amp; Co</t>`)
+	assert.Contains(t, sheet, "<c><v>3</v></c>")
+	assert.Contains(t, sheet, "</sheetData></worksheet>")
+}
@@ -58,3 +58,10 @@ func (f *Form) IsEmail(field string) bool {
 	}
 	return true
 }
+
+// ReservationRules checks the guest details of a reservation, for both the booking form and imports
+func (f *Form) ReservationRules() {
+	f.Require("first_name", "last_name", "email", "phone")
+	f.MinLength("first_name", 3)
+	f.IsEmail("email")
+}
@@ -92,3 +92,18 @@ func TestForm_GetError(t *testing.T) {
 	// should return no error
 	assert.Equal(t, "", f.Errors.Get("email"))
 }
+
+func TestForm_ReservationRules(t *testing.T) {
+	postedData := url.Values{}
+	postedData.Add("first_name", "Jo")
+	postedData.Add("last_name", "Nguyen")
+	postedData.Add("email", "not-an-email")
+
+	f := New(postedData)
+	f.ReservationRules()
+	assert.False(t, f.Valid())
+	assert.NotEmpty(t, f.Errors.Get("first_name"))
+	assert.NotEmpty(t, f.Errors.Get("email"))
+	assert.NotEmpty(t, f.Errors.Get("phone"))
+	assert.Empty(t, f.Errors.Get("last_name"))
+}
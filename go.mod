@@ -3,6 +3,8 @@ module booking
 go 1.16
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/alexedwards/scs/postgresstore v0.0.0-20211203064041-370cc303b69f
 	github.com/alexedwards/scs/v2 v2.5.0
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d
 	github.com/go-chi/chi/v5 v5.0.7
@@ -13,6 +15,7 @@ require (
 	github.com/jackc/pgx v3.6.2+incompatible
 	github.com/jackc/pgx/v4 v4.15.0
 	github.com/justinas/nosurf v1.1.1
+	github.com/prometheus/client_golang v1.12.2
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/xhit/go-simple-mail/v2 v2.11.0
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// assignmentDays is the number of days shown on the room assignment page
+const assignmentDays = 14
+
+// roomAssignment is a room type of the room assignment page with the reservations in its rooms
+type roomAssignment struct {
+	RoomType     models.RoomType
+	Reservations []models.Reservation
+}
+
+// roomAssignmentURL is the room assignment page starting on date, today when date is empty
+func roomAssignmentURL(date string) string {
+	if date == "" {
+		return "/admin/room-assignment"
+	}
+	return "/admin/room-assignment?" + url.Values{"from": {date}}.Encode()
+}
+
+// AdminRoomAssignment lists the reservations of the coming days by room type, with the room each one was
+// assigned, so that staff can move guests to another room of the type
+func (re *Repository) AdminRoomAssignment(w http.ResponseWriter, r *http.Request) {
+	from := dates.Of(re.today(r.Context()))
+	if date := r.URL.Query().Get("from"); date != "" {
+		d, err := dates.Parse(date)
+		if err != nil {
+			helpers.Error(w, r, models.NewValidationError("from", err.Error()))
+			return
+		}
+		from = d
+	}
+	period := dates.Range{Start: from, End: from.AddDays(assignmentDays)}
+
+	types, err := re.DB.RoomTypes(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	assignments := make([]roomAssignment, len(types))
+	byType := make(map[int]*roomAssignment, len(types))
+	for i, t := range types {
+		assignments[i].RoomType = t
+		byType[t.ID] = &assignments[i]
+	}
+
+	filter := models.ReservationFilter{From: period.Start.Time(), To: period.End.AddDays(-1).Time()}
+	err = re.DB.EachReservation(r.Context(), filter, func(res models.Reservation) error {
+		if a, ok := byType[res.RoomType.ID]; ok {
+			a.Reservations = append(a.Reservations, res)
+		}
+		return nil
+	})
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["assignments"] = assignments
+
+	stringMap := make(map[string]string)
+	stringMap["from"] = period.Start.String()
+	stringMap["to"] = period.End.AddDays(-1).String()
+	stringMap["previous"] = roomAssignmentURL(period.Start.AddDays(-assignmentDays).String())
+	stringMap["next"] = roomAssignmentURL(period.End.String())
+
+	render.RenderTemplate(w, r, "admin-room-assignment.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// AdminPostRoomAssignment moves a reservation to another room of its type for the same dates
+func (re *Repository) AdminPostRoomAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	roomID, err := strconv.Atoi(r.Form.Get("room_id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("room_id", "choose a room"))
+		return
+	}
+
+	res, err := re.DB.GetReservationByID(r.Context(), id)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	room, err := re.DB.GetRoomByID(r.Context(), roomID)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	if room.RoomTypeID != res.RoomType.ID {
+		helpers.Error(w, r, models.NewValidationError("room_id", "choose a room of the type the guest booked"))
+		return
+	}
+
+	back := roomAssignmentURL(r.Form.Get("from"))
+
+	err = re.DB.MoveReservation(r.Context(), id, roomID, res.StartDate, res.EndDate)
+	if errors.Is(err, models.ErrConflict) {
+		re.App.Session.Put(r.Context(), "error", "Room "+room.RoomName+" is already booked or blocked for these dates")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Reservation moved to room "+room.RoomName)
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
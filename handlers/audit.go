@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"booking/repository"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// auditExportLimit caps the number of rows written to a CSV export
+const auditExportLimit = 100000
+
+// auditFilterFromQuery reads the audit filters from the query string, dates are inclusive days
+func auditFilterFromQuery(q url.Values) (models.AuditFilter, error) {
+	var f models.AuditFilter
+	var err error
+
+	if v := q.Get("user_id"); v != "" {
+		if f.UserID, err = strconv.Atoi(v); err != nil {
+			return f, models.NewValidationError("user_id", "invalid user id")
+		}
+	}
+	if v := q.Get("entity_id"); v != "" {
+		if f.EntityID, err = strconv.Atoi(v); err != nil {
+			return f, models.NewValidationError("entity_id", "invalid entity id")
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := dates.Parse(v)
+		if err != nil {
+			return f, models.NewValidationError("from", "invalid start date")
+		}
+		f.From = from.Time()
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := dates.Parse(v)
+		if err != nil {
+			return f, models.NewValidationError("to", "invalid end date")
+		}
+		f.To = to.AddDays(1).Time()
+	}
+
+	f.Action = q.Get("action")
+	f.EntityType = q.Get("entity")
+
+	return f, nil
+}
+
+// AdminAuditLog shows the audit trail of admin changes
+func (re *Repository) AdminAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := auditFilterFromQuery(r.URL.Query())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	entries, err := re.DB.AuditLog(r.Context(), filter)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	stringMap := map[string]string{
+		"user_id":   q.Get("user_id"),
+		"action":    q.Get("action"),
+		"entity":    q.Get("entity"),
+		"entity_id": q.Get("entity_id"),
+		"from":      q.Get("from"),
+		"to":        q.Get("to"),
+		"query":     q.Encode(),
+	}
+
+	data := make(map[string]interface{})
+	data["entries"] = entries
+	data["actions"] = []string{
+		repository.AuditReservationUpdate,
+		repository.AuditReservationStatus,
+		repository.AuditReservationCancel,
+		repository.AuditReservationRestore,
+		repository.AuditReservationPurge,
+		repository.AuditReservationImport,
+		repository.AuditBlockCreate,
+		repository.AuditBlockDelete,
+	}
+	data["entities"] = []string{"reservations", "room_restrictions"}
+
+	render.RenderTemplate(w, r, "admin-audit.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// AdminAuditLogExport streams the filtered audit trail as CSV
+func (re *Repository) AdminAuditLogExport(w http.ResponseWriter, r *http.Request) {
+	filter, err := auditFilterFromQuery(r.URL.Query())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	filter.Limit = auditExportLimit
+
+	entries, err := re.DB.AuditLog(r.Context(), filter)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log-%s.csv"`, time.Now().Format("20060102")))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "time", "user_id", "user", "action", "entity", "entity_id", "before", "after"})
+	for _, e := range entries {
+		cw.Write([]string{
+			strconv.Itoa(e.ID),
+			e.CreatedAt.Format(time.RFC3339),
+			strconv.Itoa(e.UserID),
+			e.UserName,
+			e.Action,
+			e.EntityType,
+			strconv.Itoa(e.EntityID),
+			e.Before,
+			e.After,
+		})
+	}
+	cw.Flush()
+}
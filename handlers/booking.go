@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/forms"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// bookingStatuses are the statuses staff can book a reservation in
+var bookingStatuses = []models.ReservationStatus{models.StatusConfirmed, models.StatusPending}
+
+// stayFromForm reads the room and dates of a stay, adding what is wrong with them to the errors of f
+func stayFromForm(f *form.Form) (roomID int, start, end time.Time) {
+	var err error
+	if roomID, err = strconv.Atoi(f.Get("room_id")); err != nil || roomID < 1 {
+		f.Errors.Add("room_id", "Choose a room")
+	}
+	var arrival, departure dates.Date
+	if arrival, err = dates.Parse(f.Get("start_date")); err != nil {
+		f.Errors.Add("start_date", "Invalid date, use YYYY-MM-DD")
+	}
+	if departure, err = dates.Parse(f.Get("end_date")); err != nil {
+		f.Errors.Add("end_date", "Invalid date, use YYYY-MM-DD")
+	}
+	if f.Errors.Get("start_date") == "" && f.Errors.Get("end_date") == "" && !departure.After(arrival) {
+		f.Errors.Add("end_date", "Departure must be after arrival")
+	}
+	return roomID, arrival.Time(), departure.Time()
+}
+
+// guestsFromForm reads the number of adults and children of a stay, adding what is wrong with them to the
+// errors of f
+func guestsFromForm(f *form.Form) (adults, children int) {
+	adults, children, err := models.ParseGuests(f.Get("adults"), f.Get("children"))
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		f.Errors.Add(validationErr.Field, validationErr.Message)
+	}
+	return adults, children
+}
+
+// stayError shows why a stay was refused on the fields of f, it returns false for errors the form cannot show
+func stayError(f *form.Form, err error) bool {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.Is(err, models.ErrConflict):
+		f.Errors.Add("start_date", "The room is already booked or blocked for these dates")
+		return true
+	case errors.As(err, &validationErr) && validationErr.Field != "":
+		f.Errors.Add(validationErr.Field, validationErr.Message)
+		return true
+	}
+	return false
+}
+
+// AdminNewReservation shows the form staff book a room with, e.g. for phone bookings.
+// The room and dates can be filled in from the query string.
+func (re *Repository) AdminNewReservation(w http.ResponseWriter, r *http.Request) {
+	re.newReservationForm(w, r, form.New(r.URL.Query()))
+}
+
+// newReservationForm renders the booking form with the values and errors of f
+func (re *Repository) newReservationForm(w http.ResponseWriter, r *http.Request, f *form.Form) {
+	rooms, err := re.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["rooms"] = rooms
+	data["statuses"] = bookingStatuses
+
+	render.RenderTemplate(w, r, "admin-new-reservation.page.tmpl", &models.TemplateData{
+		Data: data,
+		Form: f,
+	})
+}
+
+// AdminPostNewReservation books a room for a guest. Staff may book stays the booking form would refuse,
+// but never a room that is already taken.
+func (re *Repository) AdminPostNewReservation(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	f.ReservationRules()
+	roomID, start, end := stayFromForm(f)
+	// staff may put more guests in a room than it sleeps, e.g. with an extra bed
+	adults, children := guestsFromForm(f)
+
+	status := models.ReservationStatus(f.Get("status"))
+	valid := false
+	for _, s := range bookingStatuses {
+		valid = valid || s == status
+	}
+	if !valid {
+		f.Errors.Add("status", "Choose a status")
+	}
+
+	if !f.Valid() {
+		re.newReservationForm(w, r, f)
+		return
+	}
+
+	res := models.Reservation{
+		FirstName: f.Get("first_name"),
+		LastName:  f.Get("last_name"),
+		Email:     f.Get("email"),
+		Phone:     f.Get("phone"),
+		StartDate: start,
+		EndDate:   end,
+		RoomID:    roomID,
+		Adults:    adults,
+		Children:  children,
+		Status:    status,
+	}
+
+	var err error
+	res.ID, err = re.DB.CreateReservation(r.Context(), res)
+	if err != nil {
+		if stayError(f, err) {
+			re.newReservationForm(w, r, f)
+			return
+		}
+		helpers.Error(w, r, err)
+		return
+	}
+
+	if f.Get("notify") == "1" {
+		re.sendMail(r.Context(), confirmationMail(models.PropertyFromContext(r.Context()), res))
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Reservation booked")
+	http.Redirect(w, r, showReservationURL("all", res.ID, nil), http.StatusSeeOther)
+}
+
+// AdminMoveReservation changes the room and dates of a reservation, the room must be free for the new stay
+func (re *Repository) AdminMoveReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+	src := chi.URLParam(r, "src")
+
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	roomID, start, end := stayFromForm(f)
+	if !f.Valid() {
+		re.showReservation(w, r, src, id, r.Form, f)
+		return
+	}
+
+	err = re.DB.MoveReservation(r.Context(), id, roomID, start, end)
+	if err != nil {
+		if stayError(f, err) {
+			re.showReservation(w, r, src, id, r.Form, f)
+			return
+		}
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Reservation moved")
+	http.Redirect(w, r, showReservationURL(src, id, r.Form), http.StatusSeeOther)
+}
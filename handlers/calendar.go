@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/forms"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxCalendarDays keeps the range of the calendar to what a browser draws comfortably
+const maxCalendarDays = 366
+
+// calendarMonths are the lengths of the timeline on offer, the first one is the default
+var calendarMonths = []int{3, 1, 2, 6}
+
+// calendarEntry is a reservation or a block as the timeline draws it, the end date is the day of departure
+type calendarEntry struct {
+	ID            int    `json:"id"`
+	Kind          string `json:"kind"`
+	ReservationID int    `json:"reservation_id,omitempty"`
+	Guest         string `json:"guest,omitempty"`
+	Status        string `json:"status,omitempty"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date"`
+}
+
+// calendarRoom is a row of the timeline
+type calendarRoom struct {
+	ID      int             `json:"id"`
+	Name    string          `json:"name"`
+	Entries []calendarEntry `json:"entries"`
+}
+
+// calendarChange answers the changes made on the timeline, with the id of what was created
+type calendarChange struct {
+	OK bool `json:"ok"`
+	ID int  `json:"id,omitempty"`
+}
+
+// calendarRangeFromQuery reads the days shown on the calendar, to is the first day after the range
+func calendarRangeFromQuery(q url.Values) (from, to time.Time, err error) {
+	first, err := dates.Parse(q.Get("from"))
+	if err != nil {
+		return from, to, models.NewValidationError("from", err.Error())
+	}
+	last, err := dates.Parse(q.Get("to"))
+	if err != nil {
+		return from, to, models.NewValidationError("to", err.Error())
+	}
+	if !last.After(first) {
+		return from, to, models.NewValidationError("to", "the range must end after it starts")
+	}
+	if last.Sub(first) > maxCalendarDays {
+		return from, to, models.NewValidationError("to", "the range cannot be longer than a year")
+	}
+	return first.Time(), last.Time(), nil
+}
+
+// formError returns the first error of f, in field order, as a validation error
+func formError(f *form.Form) error {
+	fields := make([]string, 0, len(f.Errors))
+	for field := range f.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return models.NewValidationError(fields[0], f.Errors.Get(fields[0]))
+}
+
+// AdminCalendarTimeline shows the rooms on a timeline over one or more months, starting with the month
+// in start (YYYY-MM). The reservations and blocks on it are loaded from AdminCalendarJSON.
+func (re *Repository) AdminCalendarTimeline(w http.ResponseWriter, r *http.Request) {
+	day := re.today(r.Context())
+	start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := r.URL.Query().Get("start"); v != "" {
+		var err error
+		if start, err = time.Parse("2006-01", v); err != nil {
+			helpers.Error(w, r, models.NewValidationError("start", "invalid month, use YYYY-MM"))
+			return
+		}
+	}
+
+	months := calendarMonths[0]
+	if v := r.URL.Query().Get("months"); v != "" {
+		months, _ = strconv.Atoi(v)
+		valid := false
+		for _, m := range calendarMonths {
+			valid = valid || m == months
+		}
+		if !valid {
+			helpers.Error(w, r, models.NewValidationError("months", "invalid number of months"))
+			return
+		}
+	}
+
+	page := func(start time.Time) string {
+		return "/admin/reservations-timeline?" + url.Values{
+			"start":  {start.Format("2006-01")},
+			"months": {strconv.Itoa(months)},
+		}.Encode()
+	}
+
+	stringMap := make(map[string]string)
+	stringMap["today"] = day.Format(dateLayout)
+	stringMap["start"] = start.Format("2006-01")
+	stringMap["from"] = start.Format(dateLayout)
+	stringMap["to"] = start.AddDate(0, months, 0).Format(dateLayout)
+	stringMap["previous"] = page(start.AddDate(0, -months, 0))
+	stringMap["next"] = page(start.AddDate(0, months, 0))
+
+	intMap := make(map[string]int)
+	intMap["months"] = months
+
+	data := make(map[string]interface{})
+	data["months"] = calendarMonths
+
+	render.RenderTemplate(w, r, "admin-reservations-timeline.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		IntMap:    intMap,
+		Data:      data,
+	})
+}
+
+// AdminCalendarJSON returns every room with the reservations and blocks between from and to
+func (re *Repository) AdminCalendarJSON(w http.ResponseWriter, r *http.Request) {
+	from, to, err := calendarRangeFromQuery(r.URL.Query())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	rooms, err := re.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	restrictions, err := re.DB.RestrictionsInRange(r.Context(), from, to)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	entries := make(map[int][]calendarEntry)
+	for _, rs := range restrictions {
+		entry := calendarEntry{
+			ID:        rs.ID,
+			Kind:      "block",
+			StartDate: rs.StartDate.Format(dateLayout),
+			EndDate:   rs.EndDate.Format(dateLayout),
+		}
+		if rs.ReservationID > 0 {
+			entry.Kind = "reservation"
+			entry.ReservationID = rs.ReservationID
+			entry.Guest = rs.Reservation.FirstName + " " + rs.Reservation.LastName
+			entry.Status = rs.Reservation.Status.Label()
+		}
+		entries[rs.RoomID] = append(entries[rs.RoomID], entry)
+	}
+
+	resp := struct {
+		From  string         `json:"from"`
+		To    string         `json:"to"`
+		Rooms []calendarRoom `json:"rooms"`
+	}{
+		From:  from.Format(dateLayout),
+		To:    to.Format(dateLayout),
+		Rooms: []calendarRoom{},
+	}
+	for _, room := range rooms {
+		resp.Rooms = append(resp.Rooms, calendarRoom{
+			ID:      room.ID,
+			Name:    room.RoomName,
+			Entries: append([]calendarEntry{}, entries[room.ID]...),
+		})
+	}
+
+	writeJSON(w, r, resp)
+}
+
+// AdminCalendarBlock blocks a room for the days dragged over on the timeline
+func (re *Repository) AdminCalendarBlock(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	roomID, start, end := stayFromForm(f)
+	if !f.Valid() {
+		helpers.Error(w, r, formError(f))
+		return
+	}
+
+	id, err := re.DB.CreateBlock(r.Context(), roomID, start, end)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, calendarChange{OK: true, ID: id})
+}
+
+// AdminCalendarDeleteBlock frees the days of a block removed on the timeline
+func (re *Repository) AdminCalendarDeleteBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid block id"))
+		return
+	}
+
+	if err := re.DB.DeleteBlockByID(r.Context(), id); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, calendarChange{OK: true})
+}
+
+// AdminCalendarMove moves a reservation dropped on other days or another room of the timeline
+func (re *Repository) AdminCalendarMove(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	roomID, start, end := stayFromForm(f)
+	if !f.Valid() {
+		helpers.Error(w, r, formError(f))
+		return
+	}
+
+	if err := re.DB.MoveReservation(r.Context(), id, roomID, start, end); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, calendarChange{OK: true})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"booking/forms"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// cancelReasonMaxLength keeps the cancellation reason to a short note
+const cancelReasonMaxLength = 255
+
+// AdminCancelReservation cancels a reservation and releases its room, the guest record is kept in the trash
+func (re *Repository) AdminCancelReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+	src := chi.URLParam(r, "src")
+
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	reason := strings.TrimSpace(r.Form.Get("reason"))
+	if len(reason) > cancelReasonMaxLength {
+		helpers.Error(w, r, models.NewValidationError("reason", fmt.Sprintf("reason must be at most %d characters", cancelReasonMaxLength)))
+		return
+	}
+
+	c, err := re.DB.CancelReservation(r.Context(), id, reason, re.today(r.Context()))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	re.App.Session.Put(r.Context(), "flash", cancelledMessage(models.PropertyFromContext(r.Context()), c))
+
+	http.Redirect(w, r, reservationsURL(src, r.Form), http.StatusSeeOther)
+}
+
+// cancelledMessage tells staff what the cancellation of a reservation charged and what is owed to the guest
+func cancelledMessage(p models.Property, c models.Cancellation) string {
+	if c.Penalty == 0 && c.Refund == 0 {
+		return "Reservation cancelled"
+	}
+	return fmt.Sprintf("Reservation cancelled, %s %s charged and %s %s to refund", p.Currency, c.Penalty,
+		p.Currency, c.Refund)
+}
+
+// AdminTrashReservations lists cancelled reservations that have not been purged yet
+func (re *Repository) AdminTrashReservations(w http.ResponseWriter, r *http.Request) {
+	reservations, err := re.DB.CancelledReservations(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["reservations"] = reservations
+
+	intMap := make(map[string]int)
+	intMap["retention_days"] = int(re.App.CancelledRetention.Hours() / 24)
+
+	render.RenderTemplate(w, r, "admin-trash-reservation.page.tmpl", &models.TemplateData{
+		Data:   data,
+		IntMap: intMap,
+	})
+}
+
+// AdminRestoreReservation brings a cancelled reservation back if its room is still free for the stay
+func (re *Repository) AdminRestoreReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+
+	err = re.DB.RestoreReservation(r.Context(), id)
+	if errors.Is(err, models.ErrConflict) {
+		re.App.Session.Put(r.Context(), "error", "The room is no longer available for these dates")
+		http.Redirect(w, r, "/admin/reservations-trash", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Reservation restored")
+	http.Redirect(w, r, fmt.Sprintf("/admin/reservations/all/%d/show", id), http.StatusSeeOther)
+}
+
+// AdminCancellationPolicies lists the cancellation policies of the property with the form adding one
+func (re *Repository) AdminCancellationPolicies(w http.ResponseWriter, r *http.Request) {
+	re.cancellationPoliciesPage(w, r, form.New(nil))
+}
+
+// cancellationPoliciesPage renders the cancellation policies with the values and errors of the new policy form f
+func (re *Repository) cancellationPoliciesPage(w http.ResponseWriter, r *http.Request, f *form.Form) {
+	policies, err := re.DB.CancellationPolicies(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["policies"] = policies
+
+	render.RenderTemplate(w, r, "admin-cancellation-policies.page.tmpl", &models.TemplateData{
+		Data: data,
+		Form: f,
+	})
+}
+
+// AdminPostCancellationPolicy adds a cancellation policy to the property
+func (re *Repository) AdminPostCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	f.Require("name")
+	policy := models.CancellationPolicy{
+		Name:          strings.TrimSpace(f.Get("name")),
+		NonRefundable: f.Get("non_refundable") == "1",
+	}
+	if !policy.NonRefundable {
+		f.Require("penalty_percent")
+		policy.FreeDays = formNumber(f, "free_days", 0, 365)
+		policy.PenaltyPercent = formNumber(f, "penalty_percent", 0, 100)
+	}
+	if len(policy.Name) > 255 {
+		f.Errors.Add("name", "Use at most 255 characters")
+	}
+
+	if !f.Valid() {
+		re.cancellationPoliciesPage(w, r, f)
+		return
+	}
+
+	if _, err := re.DB.CreateCancellationPolicy(r.Context(), policy); err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			f.Errors.Add("name", "There is a policy of that name already")
+			re.cancellationPoliciesPage(w, r, f)
+			return
+		}
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Cancellation policy added")
+	http.Redirect(w, r, "/admin/cancellation-policies", http.StatusSeeOther)
+}
+
+// AdminDeleteCancellationPolicy removes a cancellation policy of the property
+func (re *Repository) AdminDeleteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid cancellation policy id"))
+		return
+	}
+
+	if err := re.DB.DeleteCancellationPolicy(r.Context(), id); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Cancellation policy deleted")
+	http.Redirect(w, r, "/admin/cancellation-policies", http.StatusSeeOther)
+}
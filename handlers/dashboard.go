@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const dateLayout = dates.Layout
+
+// maxReportNights keeps custom report periods to a size the aggregate queries answer quickly
+const maxReportNights = 2 * 366
+
+// reportPeriodKeys are the periods offered on the dashboard, the first one is the default
+var reportPeriodKeys = []string{"month", "week", "last_month", "quarter", "year"}
+
+// location is the time zone of the property of ctx, the one of the server config when it has none
+func (re *Repository) location(ctx context.Context) *time.Location {
+	if loc := models.PropertyFromContext(ctx).Location(); loc != nil {
+		return loc
+	}
+	return re.App.TimeZone
+}
+
+// today is the current date in the time zone of the property, at midnight UTC the way reservation
+// dates are stored
+func (re *Repository) today(ctx context.Context) time.Time {
+	return dates.Today(re.location(ctx)).Time()
+}
+
+// startOfWeek returns the monday of the week of day
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// presetPeriod returns the named period around day, ok is false for unknown names
+func presetPeriod(key string, day time.Time) (models.ReportPeriod, bool) {
+	month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	p := models.ReportPeriod{Key: key}
+	switch key {
+	case "week":
+		p.Label, p.From = "This week", startOfWeek(day)
+		p.To = p.From.AddDate(0, 0, 7)
+	case "month":
+		p.Label, p.From, p.To = "This month", month, month.AddDate(0, 1, 0)
+	case "last_month":
+		p.Label, p.From, p.To = "Last month", month.AddDate(0, -1, 0), month
+	case "quarter":
+		p.Label = "This quarter"
+		p.From = time.Date(day.Year(), (day.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		p.To = p.From.AddDate(0, 3, 0)
+	case "year":
+		p.Label = "This year"
+		p.From = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		p.To = p.From.AddDate(1, 0, 0)
+	default:
+		return p, false
+	}
+	return p, true
+}
+
+// reportPeriodFromQuery reads the period of a report, either one of the presets or from and to dates.
+// The to date is the last night included, like the date pickers show it.
+func reportPeriodFromQuery(q url.Values, day time.Time) (models.ReportPeriod, error) {
+	if q.Get("from") == "" && q.Get("to") == "" {
+		key := q.Get("period")
+		if key == "" {
+			key = reportPeriodKeys[0]
+		}
+		p, ok := presetPeriod(key, day)
+		if !ok {
+			return p, models.NewValidationError("period", "unknown period")
+		}
+		return p, nil
+	}
+
+	from, err := dates.Parse(q.Get("from"))
+	if err != nil {
+		return models.ReportPeriod{}, models.NewValidationError("from", err.Error())
+	}
+	to, err := dates.Parse(q.Get("to"))
+	if err != nil {
+		return models.ReportPeriod{}, models.NewValidationError("to", err.Error())
+	}
+
+	p := models.ReportPeriod{
+		Key:   "custom",
+		Label: from.String() + " to " + to.String(),
+		From:  from.Time(),
+		To:    to.AddDays(1).Time(),
+	}
+	if to.Before(from) {
+		return p, models.NewValidationError("to", "the period must end after it starts")
+	}
+	if p.Nights() > maxReportNights {
+		return p, models.NewValidationError("to", "the period cannot be longer than two years")
+	}
+	return p, nil
+}
+
+// writeJSON answers with v encoded as JSON
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+// AdminDashboard shows today's and this week's movements and the occupancy and stays of the chosen period
+func (re *Repository) AdminDashboard(w http.ResponseWriter, r *http.Request) {
+	day := re.today(r.Context())
+	period, err := reportPeriodFromQuery(r.URL.Query(), day)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	todayMovements, err := re.DB.Movements(r.Context(), models.ReportPeriod{From: day, To: day.AddDate(0, 0, 1)})
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	week := startOfWeek(day)
+	weekMovements, err := re.DB.Movements(r.Context(), models.ReportPeriod{From: week, To: week.AddDate(0, 0, 7)})
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	occupancy, err := re.DB.Occupancy(r.Context(), period)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	stays, err := re.DB.StayStats(r.Context(), period)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	var periods []models.ReportPeriod
+	for _, key := range reportPeriodKeys {
+		p, _ := presetPeriod(key, day)
+		periods = append(periods, p)
+	}
+
+	data := make(map[string]interface{})
+	data["today"] = todayMovements
+	data["week"] = weekMovements
+	data["occupancy"] = occupancy
+	data["stays"] = stays
+	data["periods"] = periods
+
+	stringMap := make(map[string]string)
+	stringMap["period"] = period.Key
+	stringMap["from"] = period.From.Format(dateLayout)
+	stringMap["to"] = period.To.AddDate(0, 0, -1).Format(dateLayout)
+	stringMap["query"] = period.Query().Encode()
+
+	render.RenderTemplate(w, r, "admin-dashboard.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// AdminOccupancyJSON returns the occupancy of the period for the dashboard charts
+func (re *Repository) AdminOccupancyJSON(w http.ResponseWriter, r *http.Request) {
+	period, err := reportPeriodFromQuery(r.URL.Query(), re.today(r.Context()))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	occupancy, err := re.DB.Occupancy(r.Context(), period)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, occupancy)
+}
+
+// AdminStaysJSON returns the stay statistics and lead times of the period for the dashboard charts
+func (re *Repository) AdminStaysJSON(w http.ResponseWriter, r *http.Request) {
+	period, err := reportPeriodFromQuery(r.URL.Query(), re.today(r.Context()))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	stays, err := re.DB.StayStats(r.Context(), period)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, struct {
+		models.StayStats
+		AverageStay      float64      `json:"average_stay"`
+		CancellationRate float64      `json:"cancellation_rate"`
+		AverageDailyRate models.Money `json:"average_daily_rate"`
+	}{stays, stays.AverageStay(), stays.CancellationRate(), stays.AverageDailyRate()})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// noteMaxLength keeps notes to what fits on a run sheet
+const noteMaxLength = 1000
+
+// frontDeskSection is one of the lists of the front desk page
+type frontDeskSection struct {
+	Title        string
+	Reservations []models.Reservation
+}
+
+// frontDeskURL is the front desk page of date, today when date is empty
+func frontDeskURL(date string) string {
+	if date == "" {
+		return "/admin/front-desk"
+	}
+	return "/admin/front-desk?" + url.Values{"date": {date}}.Encode()
+}
+
+// AdminFrontDesk lists the arrivals, departures and in-house guests of a day, today unless a date is given.
+// With print=1 the list is rendered as a run sheet without the admin layout.
+func (re *Repository) AdminFrontDesk(w http.ResponseWriter, r *http.Request) {
+	day := re.today(r.Context())
+	if date := r.URL.Query().Get("date"); date != "" {
+		d, err := dates.Parse(date)
+		if err != nil {
+			helpers.Error(w, r, models.NewValidationError("date", err.Error()))
+			return
+		}
+		day = d.Time()
+	}
+
+	desk, err := re.DB.FrontDesk(r.Context(), day)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["sections"] = []frontDeskSection{
+		{Title: "Arrivals", Reservations: desk.Arrivals},
+		{Title: "Departures", Reservations: desk.Departures},
+		{Title: "In house", Reservations: desk.InHouse},
+	}
+
+	stringMap := make(map[string]string)
+	stringMap["date"] = day.Format(dateLayout)
+	stringMap["previous"] = frontDeskURL(day.AddDate(0, 0, -1).Format(dateLayout))
+	stringMap["next"] = frontDeskURL(day.AddDate(0, 0, 1).Format(dateLayout))
+
+	page := "admin-front-desk.page.tmpl"
+	if r.URL.Query().Get("print") == "1" {
+		page = "admin-front-desk-print.page.tmpl"
+	}
+
+	render.RenderTemplate(w, r, page, &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// AdminAddReservationNote saves a staff note on a reservation and returns to the page it was written on,
+// the reservation page when from is show
+func (re *Repository) AdminAddReservationNote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+	src := chi.URLParam(r, "src")
+
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	body := strings.TrimSpace(r.Form.Get("note"))
+	if body == "" {
+		helpers.Error(w, r, models.NewValidationError("note", "the note cannot be empty"))
+		return
+	}
+	if len(body) > noteMaxLength {
+		helpers.Error(w, r, models.NewValidationError("note", fmt.Sprintf("the note must be at most %d characters", noteMaxLength)))
+		return
+	}
+
+	_, err = re.DB.AddReservationNote(r.Context(), id, body)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	re.App.Session.Put(r.Context(), "flash", "Note added")
+
+	if r.Form.Get("from") == "show" {
+		http.Redirect(w, r, showReservationURL(src, id, r.Form), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, reservationsURL(src, r.Form), http.StatusSeeOther)
+}
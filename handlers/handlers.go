@@ -3,8 +3,10 @@ package handlers
 
 import (
 	"booking/config"
+	"booking/dates"
 	form "booking/forms"
 	"booking/helpers"
+	"booking/logging"
 	"booking/models"
 	"booking/render"
 	"booking/repository"
@@ -12,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -52,53 +55,129 @@ func (re *Repository) About(w http.ResponseWriter, r *http.Request) {
 	render.RenderTemplate(w, r, "about.page.tmpl", &models.TemplateData{})
 }
 
+// Room shows a room type of the property with the button checking its availability
+func (re *Repository) Room(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid room type id"))
+		return
+	}
+
+	t, err := re.DB.GetRoomTypeByID(r.Context(), id)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["type"] = t
+
+	render.RenderTemplate(w, r, "room.page.tmpl", &models.TemplateData{Data: data})
+}
+
+// Generals sends the links of the first room page, from before room types had pages of their own, to its type
 func (re *Repository) Generals(w http.ResponseWriter, r *http.Request) {
-	render.RenderTemplate(w, r, "generals.page.tmpl", &models.TemplateData{})
+	http.Redirect(w, r, "/rooms/1", http.StatusMovedPermanently)
 }
 
+// Majors sends the links of the second room page, from before room types had pages of their own, to its type
 func (re *Repository) Majors(w http.ResponseWriter, r *http.Request) {
-	render.RenderTemplate(w, r, "majors.page.tmpl", &models.TemplateData{})
+	http.Redirect(w, r, "/rooms/2", http.StatusMovedPermanently)
 }
 
 func (re *Repository) Availability(w http.ResponseWriter, r *http.Request) {
 	render.RenderTemplate(w, r, "search-availability.page.tmpl", &models.TemplateData{})
 }
 
+// parseStay reads the arrival and departure dates of a search, the departure must be after the arrival
+func parseStay(start, end string) (dates.Range, error) {
+	arrival, err := dates.Parse(start)
+	if err != nil {
+		return dates.Range{}, models.NewValidationError("start", "invalid arrival date")
+	}
+	departure, err := dates.Parse(end)
+	if err != nil {
+		return dates.Range{}, models.NewValidationError("end", "invalid departure date")
+	}
+	if !departure.After(arrival) {
+		return dates.Range{}, models.NewValidationError("end", "departure must be after arrival")
+	}
+	return dates.Range{Start: arrival, End: departure}, nil
+}
+
 func (re *Repository) PostAvailability(w http.ResponseWriter, r *http.Request) {
 	start := r.Form.Get("start")
 	end := r.Form.Get("end")
 
-	layout := "2006-01-02"
-	startDate, err := time.Parse(layout, start)
+	stay, err := parseStay(start, end)
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
-	endDate, err := time.Parse(layout, end)
+	startDate, endDate := stay.Start.Time(), stay.End.Time()
+
+	adults, children, err := models.ParseGuests(r.Form.Get("adults"), r.Form.Get("children"))
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
-	rooms, err := re.DB.SearchAvailabilityForAllRooms(startDate, endDate)
+	rules, err := re.DB.StayRules(r.Context())
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
-	if len(rooms) == 0 {
-		re.App.Session.Put(r.Context(), "error", "No availability")
+	// the rules of every room type refuse the stay before searching
+	now := re.now(r.Context())
+	if err := rules.Check(0, stay, now); err != nil {
+		re.App.Session.Put(r.Context(), "error", ruleMessage(err))
+		http.Redirect(w, r, SEARCH_AVAIABILITY_URL, http.StatusSeeOther)
+		return
+	}
+
+	// only the types sleeping every guest are offered
+	types, err := re.DB.SearchAvailabilityByType(r.Context(), startDate, endDate, adults+children)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	var available []models.TypeAvailability
+	var refused error
+	for _, t := range types {
+		if t.Available == 0 {
+			continue
+		}
+		if err := rules.Check(t.RoomType.ID, stay, now); err != nil {
+			if refused == nil {
+				refused = err
+			}
+			continue
+		}
+		available = append(available, t)
+	}
+
+	if len(available) == 0 {
+		msg := "No availability"
+		if refused != nil {
+			msg = ruleMessage(refused)
+		}
+		re.App.Session.Put(r.Context(), "error", msg)
 		http.Redirect(w, r, SEARCH_AVAIABILITY_URL, http.StatusSeeOther)
 		return
 	}
 
 	data := make(map[string]interface{})
-	data["rooms"] = rooms
+	data["types"] = available
 
 	res := models.Reservation{
 		StartDate: startDate,
 		EndDate:   endDate,
+		Adults:    adults,
+		Children:  children,
 	}
+	data["reservation"] = res
 
 	re.App.Session.Put(r.Context(), "reservation", res)
 
@@ -108,30 +187,49 @@ func (re *Repository) PostAvailability(w http.ResponseWriter, r *http.Request) {
 }
 
 type jsonResponse struct {
-	OK        bool   `json:"ok"`
-	Message   string `json:"message"`
-	RoomID    string `json:"room_id"`
+	OK         bool   `json:"ok"`
+	Message    string `json:"message"`
+	RoomTypeID string `json:"room_type_id"`
+	// Available is the number of rooms of the type left for the stay
+	Available int    `json:"available"`
 	StartDate string `json:"start_date"`
 	EndDate   string `json:"end_date"`
+	Adults    int    `json:"adults"`
+	Children  int    `json:"children"`
 }
 
 func (re *Repository) AvailabilityJSON(w http.ResponseWriter, r *http.Request) {
 	start := r.Form.Get("start")
 	end := r.Form.Get("end")
-	layout := "2006-01-02"
-	startDate, _ := time.Parse(layout, start)
-	endDate, _ := time.Parse(layout, end)
+	roomTypeID, _ := strconv.Atoi(r.Form.Get("room_type_id"))
 
-	roomID, _ := strconv.Atoi(r.Form.Get("room_id"))
+	stay, err := parseStay(start, end)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	startDate, endDate := stay.Start.Time(), stay.End.Time()
 
-	logrus.WithFields(logrus.Fields{
-		"start":   startDate,
-		"end":     endDate,
-		"room_id": roomID,
+	adults, children, err := models.ParseGuests(r.Form.Get("adults"), r.Form.Get("children"))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).WithFields(logrus.Fields{
+		"start":        startDate,
+		"end":          endDate,
+		"room_type_id": roomTypeID,
+		"guests":       adults + children,
 	}).Info("AvailabilityJSON info")
 
-	available, err := re.DB.SearchAvailabilityByDatesByRoomID(roomID, startDate, endDate)
+	rules, err := re.DB.StayRules(r.Context())
+	var types []models.TypeAvailability
+	if err == nil {
+		types, err = re.DB.SearchAvailabilityByType(r.Context(), startDate, endDate, adults+children)
+	}
 	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("cannot search availability")
 		resp := jsonResponse{
 			OK:      false,
 			Message: "Error connecting to database",
@@ -139,21 +237,40 @@ func (re *Repository) AvailabilityJSON(w http.ResponseWriter, r *http.Request) {
 
 		out, _ := json.MarshalIndent(resp, "", "    ")
 		w.Header().Set("Content-Type", "application/json")
+		if helpers.IsTimeout(err) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
 		w.Write(out)
 		return
 	}
 
+	available := 0
+	for _, t := range types {
+		if t.RoomType.ID == roomTypeID {
+			available = t.Available
+		}
+	}
+
+	// a stay the rules refuse is not available, the message tells which rule
+	message := ""
+	if refused := rules.Check(roomTypeID, stay, re.now(r.Context())); refused != nil {
+		available, message = 0, ruleMessage(refused)
+	}
+
 	resp := jsonResponse{
-		OK:        available,
-		Message:   "",
-		StartDate: start,
-		EndDate:   end,
-		RoomID:    strconv.Itoa(roomID),
+		OK:         available > 0,
+		Message:    message,
+		StartDate:  start,
+		EndDate:    end,
+		RoomTypeID: strconv.Itoa(roomTypeID),
+		Available:  available,
+		Adults:     adults,
+		Children:   children,
 	}
 
 	out, err := json.MarshalIndent(resp, "", "     ")
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
@@ -173,30 +290,42 @@ func (re *Repository) Reservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	room, err := re.DB.GetRoomByID(res.RoomID)
+	roomType, err := re.DB.GetRoomTypeByID(r.Context(), res.RoomType.ID)
 	if err != nil {
 		re.App.Session.Put(r.Context(), "error", "room not found")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 		return
 	}
 
-	res.Room.RoomName = room.RoomName
+	res.RoomType = roomType
 
 	re.App.Session.Put(r.Context(), "reservation", res)
 
-	sd := res.StartDate.Format("2006-01-02")
-	ed := res.EndDate.Format("2006-01-02")
+	f := form.New(url.Values{
+		"adults":   {strconv.Itoa(res.Adults)},
+		"children": {strconv.Itoa(res.Children)},
+	})
+	q, err := re.quote(r.Context(), f, roomType, dates.Range{Start: dates.Of(res.StartDate), End: dates.Of(res.EndDate)})
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	re.reservationForm(w, r, res, q, f)
+}
 
+// reservationForm renders the booking form of res, priced by quote q, with the values and errors of f
+func (re *Repository) reservationForm(w http.ResponseWriter, r *http.Request, res models.Reservation, q models.Quote, f *form.Form) {
 	data := make(map[string]interface{})
 	data["reservation"] = res
-	stringMap := make(map[string]string)
-	stringMap["start_date"] = sd
-	stringMap["end_date"] = ed
+	data["quote"] = q
 
 	render.RenderTemplate(w, r, "make-reservation.page.tmpl", &models.TemplateData{
-		Form:      form.New(nil),
-		Data:      data,
-		StringMap: stringMap,
+		Form: f,
+		Data: data,
+		StringMap: map[string]string{
+			"start_date": res.StartDate.Format("2006-01-02"),
+			"end_date":   res.EndDate.Format("2006-01-02"),
+		},
 	})
 }
 
@@ -205,36 +334,41 @@ func (re *Repository) PostReservation(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		re.App.Session.Put(r.Context(), "error", "cannot parse form")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
-		logrus.Info("line 202")
 		return
 	}
 
 	sd := r.Form.Get("start_date")
 	ed := r.Form.Get("end_date")
-	layout := "2006-01-02"
-
-	startDate, err := time.Parse(layout, sd)
+	arrival, err := dates.Parse(sd)
 	if err != nil {
 		re.App.Session.Put(r.Context(), "error", "cannot parse start date")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 		return
 	}
 
-	endDate, err := time.Parse(layout, ed)
+	departure, err := dates.Parse(ed)
 	if err != nil {
 		re.App.Session.Put(r.Context(), "error", "cannot parse end date")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 		return
 	}
+	stay := dates.Range{Start: arrival, End: departure}
+	if stay.Nights() < 1 {
+		// an empty or reversed stay would be priced at nothing and overlap no other reservation
+		re.App.Session.Put(r.Context(), "error", "Departure must be after arrival")
+		http.Redirect(w, r, SEARCH_AVAIABILITY_URL, http.StatusSeeOther)
+		return
+	}
+	startDate, endDate := arrival.Time(), departure.Time()
 
-	roomID, err := strconv.Atoi(r.Form.Get("room_id"))
+	roomTypeID, err := strconv.Atoi(r.Form.Get("room_type_id"))
 	if err != nil {
 		re.App.Session.Put(r.Context(), "error", "invalid room id")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 		return
 	}
 
-	room, err := re.DB.GetRoomByID(roomID)
+	roomType, err := re.DB.GetRoomTypeByID(r.Context(), roomTypeID)
 	if err != nil {
 		re.App.Session.Put(r.Context(), "error", "invalid data")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
@@ -248,79 +382,86 @@ func (re *Repository) PostReservation(w http.ResponseWriter, r *http.Request) {
 		Email:     r.Form.Get("email"),
 		StartDate: startDate,
 		EndDate:   endDate,
-		RoomID:    roomID,
-		Room:      room,
+		Status:    models.StatusPending,
+		RoomType:  roomType,
 	}
 
 	f := form.New(r.PostForm)
 
-	f.Require("first_name", "last_name", "email", "phone")
-	f.MinLength("first_name", 3)
-	f.IsEmail("email")
+	// applying a promo code only prices the stay again, the guest details can still be blank
+	applying := f.Get("action") == "apply"
+	if !applying {
+		f.ReservationRules()
+	}
 
-	if !f.Valid() {
-		data := make(map[string]interface{})
-		data["reservation"] = reservation
-		render.RenderTemplate(w, r, "make-reservation.page.tmpl", &models.TemplateData{
-			Form: f,
-			Data: data,
-		})
-		return
+	reservation.Adults, reservation.Children = guestsFromForm(f)
+	if f.Errors.Get("adults") == "" && f.Errors.Get("children") == "" && !roomType.Fits(reservation) {
+		f.Errors.Add("adults", fmt.Sprintf("A %s sleeps at most %d guests", roomType.Name, roomType.MaxOccupancy))
 	}
-	newReservationID, err := re.DB.InsertReservation(reservation)
+
+	quote, err := re.quote(r.Context(), f, roomType, stay)
 	if err != nil {
-		re.App.Session.Put(r.Context(), "error", "cannot insert reservation into database")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		helpers.Error(w, r, err)
 		return
 	}
 
-	roomRestriction := models.RoomRestriction{
-		StartDate:     reservation.StartDate,
-		EndDate:       reservation.EndDate,
-		RoomID:        reservation.RoomID,
-		ReservationID: newReservationID,
-		RestrictionID: 1,
+	if applying || !f.Valid() {
+		re.reservationForm(w, r, reservation, quote, f)
+		return
 	}
+	reservation.Price(quote)
 
-	_, err = re.DB.InsertRoomRestriction(roomRestriction)
+	rules, err := re.DB.StayRules(r.Context())
 	if err != nil {
-		re.App.Session.Put(r.Context(), "error", "cannot insert room restriction into database")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		helpers.Error(w, r, err)
+		return
+	}
+	if err := rules.Check(roomType.ID, stay, re.now(r.Context())); err != nil {
+		re.App.Session.Put(r.Context(), "error", ruleMessage(err))
+		http.Redirect(w, r, SEARCH_AVAIABILITY_URL, http.StatusSeeOther)
 		return
 	}
 
-	// send notifications
-	htmlMsg := fmt.Sprintf(`
-		<strong>Reservation Confirmation</strong> <br>
-		Dear %s, <br>
-		This email confirms your reservation from %s to %s. <br>
-		Thank you for using our services! <br>
-	`, reservation.FirstName, reservation.StartDate.Format("2006-01-02"), reservation.EndDate.Format("2006-01-02"))
-
-	msg := models.MailData{
-		To:       reservation.Email,
-		From:     "me@email.com",
-		Subject:  "Reservation Confirmation",
-		Content:  htmlMsg,
-		Template: "basic.html",
+	// a room of the type is assigned to the reservation
+	booked, err := re.DB.ReserveRoomType(r.Context(), roomType.ID, reservation)
+	if errors.Is(err, models.ErrConflict) {
+		re.App.Session.Put(r.Context(), "error", "Sorry, the last "+roomType.Name+" was just booked for these dates")
+		http.Redirect(w, r, SEARCH_AVAIABILITY_URL, http.StatusSeeOther)
+		return
 	}
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Field == "promo_code" {
+		// the code reached a limit of uses since the stay was priced
+		f.Errors.Add("promo_code", validationErr.Message)
+		re.reservationForm(w, r, reservation, quote.WithPromo(models.PromoCode{}), f)
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("cannot insert reservation")
+		re.App.Session.Put(r.Context(), "error", "cannot insert reservation into database")
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		return
+	}
+	reservation = booked
 
-	re.App.MailChan <- msg
+	// send notifications
+	property := models.PropertyFromContext(r.Context())
+	re.sendMail(r.Context(), confirmationMail(property, reservation))
 
-	htmlMsg = fmt.Sprintf(`
+	htmlMsg := fmt.Sprintf(`
 		<strong>Reservation Notification</strong> <br>
-		A reservation has been made for %s from %s to %s
-	`, reservation.FirstName, reservation.StartDate.Format("2006-01-02"), reservation.EndDate.Format("2006-01-02"))
+		A reservation has been made for %s from %s to %s, %s
+	`, reservation.FirstName, reservation.StartDate.Format("2006-01-02"), reservation.EndDate.Format("2006-01-02"),
+		reservation.GuestSummary())
 
-	msg = models.MailData{
-		To:       "me@email.com",
-		From:     "me@email.com",
+	msg := models.MailData{
+		To:       property.ContactEmail,
+		From:     property.ContactEmail,
 		Subject:  "Reservation Confirmation",
 		Content:  htmlMsg,
 		Template: "basic.html",
 	}
-
-	re.App.MailChan <- msg
+	re.sendMail(r.Context(), msg)
 
 	re.App.Session.Put(r.Context(), "reservation", reservation)
 	http.Redirect(w, r, "/reservation-summary", http.StatusSeeOther)
@@ -329,7 +470,7 @@ func (re *Repository) PostReservation(w http.ResponseWriter, r *http.Request) {
 func (re *Repository) ReservationSummary(w http.ResponseWriter, r *http.Request) {
 	reservation, ok := re.App.Session.Get(r.Context(), "reservation").(models.Reservation)
 	if !ok {
-		logrus.Error("cannot load item from session")
+		logging.FromContext(r.Context()).Error("cannot load item from session")
 		re.App.Session.Put(r.Context(), "error", "Can't get reservation from session")
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 		return
@@ -352,44 +493,54 @@ func (re *Repository) ReservationSummary(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// ChooseRoom picks the room type of the stay being booked, a room of the type is assigned once it is booked
 func (re *Repository) ChooseRoom(w http.ResponseWriter, r *http.Request) {
-	roomID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	roomTypeID, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, models.NewValidationError("id", "invalid room id"))
 		return
 	}
 
 	res, ok := re.App.Session.Get(r.Context(), "reservation").(models.Reservation)
 	if !ok {
-		helpers.ServerError(w, errors.New("no session found"))
+		helpers.Error(w, r, errors.New("no session found"))
 		return
 	}
 
-	res.RoomID = roomID
+	res.RoomType = models.RoomType{ID: roomTypeID}
 
 	re.App.Session.Put(r.Context(), "reservation", res)
 	http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
 }
 
+// BookRoom starts booking a room type for the stay checked on its page
 func (re *Repository) BookRoom(w http.ResponseWriter, r *http.Request) {
-	roomID, _ := strconv.Atoi(r.URL.Query().Get("id"))
-	layout := "2006-01-02"
-	start := r.URL.Query().Get("s")
-	startDate, _ := time.Parse(layout, start)
-	end := r.URL.Query().Get("e")
-	endDate, _ := time.Parse(layout, end)
+	roomTypeID, _ := strconv.Atoi(r.URL.Query().Get("id"))
+	stay, err := parseStay(r.URL.Query().Get("s"), r.URL.Query().Get("e"))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	startDate, endDate := stay.Start.Time(), stay.End.Time()
+
+	adults, children, err := models.ParseGuests(r.URL.Query().Get("a"), r.URL.Query().Get("c"))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
 
-	room, err := re.DB.GetRoomByID(roomID)
+	roomType, err := re.DB.GetRoomTypeByID(r.Context(), roomTypeID)
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
 	res := models.Reservation{
-		RoomID:    roomID,
 		StartDate: startDate,
 		EndDate:   endDate,
-		Room:      room,
+		Adults:    adults,
+		Children:  children,
+		RoomType:  roomType,
 	}
 
 	re.App.Session.Put(r.Context(), "reservation", res)
@@ -405,7 +556,7 @@ func (re *Repository) PostLogin(w http.ResponseWriter, r *http.Request) {
 
 	err := r.ParseForm()
 	if err != nil {
-		logrus.WithError(err).Error("cannot parse form")
+		logging.FromContext(r.Context()).WithError(err).Error("cannot parse form")
 		re.App.Session.Put(r.Context(), "error", "cannot parse form")
 		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
 		return
@@ -424,9 +575,9 @@ func (re *Repository) PostLogin(w http.ResponseWriter, r *http.Request) {
 
 	email := r.Form.Get("email")
 	password := r.Form.Get("password")
-	id, _, err := re.DB.Authenticate(email, password)
+	id, _, err := re.DB.Authenticate(r.Context(), email, password)
 	if err != nil {
-		logrus.WithError(err).Error("failed to authenticate user")
+		logging.FromContext(r.Context()).WithError(err).Error("failed to authenticate user")
 		re.App.Session.Put(r.Context(), "error", "Invalid login credentials")
 		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
 		return
@@ -444,178 +595,151 @@ func (re *Repository) Logout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
 }
 
-func (re *Repository) AdminDashboard(w http.ResponseWriter, r *http.Request) {
-	render.RenderTemplate(w, r, "admin-dashboard.page.tmpl", &models.TemplateData{})
-}
-
-func (re *Repository) AdminNewReservation(w http.ResponseWriter, r *http.Request) {
-	reservations, err := re.DB.AllNewReservations()
-	if err != nil {
-		helpers.ServerError(w, err)
-		return
+// reservationsURL is the page an admin action returns to: the calendar month, the front desk day or
+// the list the reservation was opened from. v carries the y and m of the calendar or the date of the front desk.
+func reservationsURL(src string, v url.Values) string {
+	if year := v.Get("y"); year != "" {
+		return fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, v.Get("m"))
 	}
-
-	data := make(map[string]interface{})
-	data["reservations"] = reservations
-	render.RenderTemplate(w, r, "admin-new-reservation.page.tmpl", &models.TemplateData{Data: data})
-}
-
-func (re *Repository) AdminAllReservation(w http.ResponseWriter, r *http.Request) {
-	reservations, err := re.DB.AllReservations()
-	if err != nil {
-		helpers.ServerError(w, err)
-		return
+	if src == "front-desk" {
+		return frontDeskURL(v.Get("date"))
 	}
-
-	data := make(map[string]interface{})
-	data["reservations"] = reservations
-
-	render.RenderTemplate(w, r, "admin-all-reservation.page.tmpl", &models.TemplateData{Data: data})
+	if src == "trash" {
+		return "/admin/reservations-trash"
+	}
+	if src == "timeline" {
+		return "/admin/reservations-timeline"
+	}
+	if _, err := models.ParseReservationStatus(src); err == nil {
+		return "/admin/reservations?status=" + src
+	}
+	return "/admin/reservations"
 }
 
 func (re *Repository) AdminShowReservation(w http.ResponseWriter, r *http.Request) {
 	exploded := strings.Split(r.RequestURI, "/")
 	id, err := strconv.Atoi(exploded[4])
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
 		return
 	}
 
 	src := exploded[3]
 
-	logrus.WithFields(logrus.Fields{
+	logging.FromContext(r.Context()).WithFields(logrus.Fields{
 		"src": src,
 		"id":  id,
 	}).Info("GetURLInfo")
 
+	re.showReservation(w, r, src, id, r.URL.Query(), form.New(nil))
+}
+
+// showReservation renders the reservation page, with the errors of f when a form on it was rejected.
+// v carries where the reservation was opened from.
+func (re *Repository) showReservation(w http.ResponseWriter, r *http.Request, src string, id int, v url.Values, f *form.Form) {
 	stringMap := make(map[string]string)
 	stringMap["src"] = src
-
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
-	stringMap["month"] = month
-	stringMap["year"] = year
+	stringMap["month"] = v.Get("m")
+	stringMap["year"] = v.Get("y")
+	stringMap["date"] = v.Get("date")
+	stringMap["back"] = reservationsURL(src, v)
 
 	// get reservation from database
-	res, err := re.DB.GetReservationByID(id)
+	res, err := re.DB.GetReservationByID(r.Context(), id)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	timeline, err := re.DB.ReservationTimeline(r.Context(), id)
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
+		return
+	}
+
+	rooms, err := re.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
 		return
 	}
 
 	data := make(map[string]interface{})
 	data["reservation"] = res
+	data["rooms"] = rooms
+	data["statuses"] = models.ReservationStatuses
+	data["timeline"] = timeline
+	data["email_templates"] = emailTemplates()
+	// what cancelling today would cost, shown before staff confirm it
+	data["cancellation"] = res.Cancellation(dates.Of(re.today(r.Context())))
 
 	render.RenderTemplate(w, r, "admin-reservation-show.page.tmpl", &models.TemplateData{
 		StringMap: stringMap,
 		Data:      data,
-		Form:      form.New(nil),
+		Form:      f,
 	})
 }
 
+// AdminReservationCalendar shows a month of every room, the month is picked with the y and m parameters
 func (re *Repository) AdminReservationCalendar(w http.ResponseWriter, r *http.Request) {
 	// assume that there is no month/year specified
-	now := time.Now()
+	now := re.today(r.Context())
 	if r.URL.Query().Get("y") != "" {
 		year, _ := strconv.Atoi(r.URL.Query().Get("y"))
 		month, _ := strconv.Atoi(r.URL.Query().Get("m"))
 		now = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	}
 
-	data := make(map[string]interface{})
-	data["now"] = now
-
-	next := now.AddDate(0, 1, 0)
-	last := now.AddDate(0, -1, 0)
-
-	nextMonth := next.Format("01")
-	nextMonthYear := next.Format("2006")
-
-	lastMonth := last.Format("01")
-	lastMonthYear := last.Format("2006")
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	next := firstOfMonth.AddDate(0, 1, 0)
+	last := firstOfMonth.AddDate(0, -1, 0)
 
 	stringMap := make(map[string]string)
-	stringMap["next_month"] = nextMonth
-	stringMap["next_month_year"] = nextMonthYear
-	stringMap["last_month"] = lastMonth
-	stringMap["last_month_year"] = lastMonthYear
-	stringMap["this_month"] = now.Format("01")
-	stringMap["this_month_year"] = now.Format("2006")
-
-	// get the first and last days of the month
-	currentYear, currentMonth, _ := now.Date()
-	currentLocation := now.Location()
-	firstOfMonth := time.Date(currentYear, currentMonth, 1, 0, 0, 0, 0, currentLocation)
-	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
-
-	intMap := make(map[string]int)
-	intMap["days_in_month"] = lastOfMonth.Day()
-
-	rooms, err := re.DB.AllRooms()
+	stringMap["next_month"] = next.Format("01")
+	stringMap["next_month_year"] = next.Format("2006")
+	stringMap["last_month"] = last.Format("01")
+	stringMap["last_month_year"] = last.Format("2006")
+	stringMap["this_month"] = firstOfMonth.Format("01")
+	stringMap["this_month_year"] = firstOfMonth.Format("2006")
+
+	rooms, err := re.DB.AllRooms(r.Context())
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
-	data["rooms"] = rooms
-
-	for _, room := range rooms {
-		reservationMap := make(map[string]int)
-		blockMap := make(map[string]int)
-
-		for d := firstOfMonth; d.After(lastOfMonth) == false; d = d.AddDate(0, 0, 1) {
-			reservationMap[d.Format("2006-01-2")] = 0
-			blockMap[d.Format("2006-01-2")] = 0
-		}
-
-		restrictions, err := re.DB.GetRestrictionsForRoomByDate(room.ID, firstOfMonth, lastOfMonth)
-		if err != nil {
-			helpers.ServerError(w, err)
-			return
-		}
-
-		for _, rs := range restrictions {
-			if rs.ReservationID > 0 {
-				// It is a reservation
-				for d := rs.StartDate; d.After(rs.EndDate) == false; d = d.AddDate(0, 0, 1) {
-					reservationMap[d.Format("2006-01-2")] = rs.ReservationID
-				}
-			} else {
-				// It is a block
-				blockMap[rs.StartDate.Format("2006-01-2")] = rs.ID
-			}
-		}
-
-		data[fmt.Sprintf("reservation_map_%d", room.ID)] = reservationMap
-		data[fmt.Sprintf("block_map_%d", room.ID)] = blockMap
-
-		re.App.Session.Put(r.Context(), fmt.Sprintf("block_map_%d", room.ID), blockMap)
+	restrictions, err := re.DB.RestrictionsInRange(r.Context(), firstOfMonth, next)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
 	}
 
+	data := make(map[string]interface{})
+	data["now"] = firstOfMonth
+	data["calendar"] = models.NewCalendar(firstOfMonth, next, rooms, restrictions)
+
 	render.RenderTemplate(w, r, "admin-reservation-calendar.page.tmpl", &models.TemplateData{
 		StringMap: stringMap,
 		Data:      data,
-		IntMap:    intMap,
 	})
 }
 
 func (re *Repository) AdminPostReservation(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
 	exploded := strings.Split(r.RequestURI, "/")
 	id, err := strconv.Atoi(exploded[4])
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
 		return
 	}
 
 	src := exploded[3]
 
-	logrus.WithFields(logrus.Fields{
+	logging.FromContext(r.Context()).WithFields(logrus.Fields{
 		"src": src,
 		"id":  id,
 	}).Info("GetURLInfo")
@@ -623,9 +747,9 @@ func (re *Repository) AdminPostReservation(w http.ResponseWriter, r *http.Reques
 	stringMap := make(map[string]string)
 	stringMap["src"] = src
 
-	res, err := re.DB.GetReservationByID(id)
+	res, err := re.DB.GetReservationByID(r.Context(), id)
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
@@ -633,92 +757,91 @@ func (re *Repository) AdminPostReservation(w http.ResponseWriter, r *http.Reques
 	res.LastName = r.Form.Get("last_name")
 	res.Email = r.Form.Get("email")
 	res.Phone = r.Form.Get("phone")
+	if res.Adults, res.Children, err = models.ParseGuests(r.Form.Get("adults"), r.Form.Get("children")); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
 
-	err = re.DB.UpdateReservation(res)
+	err = re.DB.UpdateReservation(r.Context(), res)
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
 	re.App.Session.Put(r.Context(), "flash", "Changes saved")
 
-	month := r.Form.Get("month")
-	year := r.Form.Get("year")
-
-	if year == "" {
-		http.Redirect(w, r, "/admin/reservations-"+src, http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
-	}
-
+	http.Redirect(w, r, reservationsURL(src, r.Form), http.StatusSeeOther)
 }
 
-func (re *Repository) AdminProcessReservation(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+// AdminTransitionReservation moves a reservation to the status posted by the admin buttons
+func (re *Repository) AdminTransitionReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
 	src := chi.URLParam(r, "src")
-	_ = re.DB.UpdateProcessedForReservation(id, 1)
 
-	re.App.Session.Put(r.Context(), "flash", "Reservation marked as processed")
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
 
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
+	status, err := models.ParseReservationStatus(r.Form.Get("status"))
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
 
-	if year == "" {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-%s", src), http.StatusSeeOther)
+	// cancelling from the status list charges the same penalty as the cancel button
+	flash := "Reservation is now " + strings.ToLower(status.Label())
+	if status == models.StatusCancelled {
+		var c models.Cancellation
+		c, err = re.DB.CancelReservation(r.Context(), id, "", re.today(r.Context()))
+		flash = cancelledMessage(models.PropertyFromContext(r.Context()), c)
 	} else {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
+		err = re.DB.TransitionReservation(r.Context(), id, status)
+	}
+	if errors.Is(err, models.ErrConflict) {
+		re.App.Session.Put(r.Context(), "error", "This change is not allowed in the current status")
+		http.Redirect(w, r, fmt.Sprintf("/admin/reservations/%s/%d/show", src, id), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
 	}
 
-}
-
-func (re *Repository) AdminDeleteReservation(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	src := chi.URLParam(r, "src")
-	_ = re.DB.DeleteReservation(id)
-	re.App.Session.Put(r.Context(), "flash", "Reservation deleted")
-
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
+	re.App.Session.Put(r.Context(), "flash", flash)
 
-	if year == "" {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-%s", src), http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
-	}
+	http.Redirect(w, r, reservationsURL(src, r.Form), http.StatusSeeOther)
 }
 
+// AdminPostReservationCalendar saves the blocks ticked and unticked on a month of the calendar.
+// Every block shown on the page is posted as shown_block, those that were unticked are removed.
 func (re *Repository) AdminPostReservationCalendar(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		helpers.ServerError(w, err)
+		helpers.Error(w, r, err)
 		return
 	}
 
 	year, _ := strconv.Atoi(r.Form.Get("y"))
 	month, _ := strconv.Atoi(r.Form.Get("m"))
 
-	rooms, err := re.DB.AllRooms()
-	if err != nil {
-		helpers.ServerError(w, err)
-		return
-	}
-
 	form := form.New(r.PostForm)
 
-	for _, room := range rooms {
-		// Get the block map from the session. Loop through entire map, if we have an entry in the map
-		// that does not exist in our posted data, and if the restriction id > 0, then it is the block we need to remove
-		curMap := re.App.Session.Get(r.Context(), fmt.Sprintf("block_map_%d", room.ID)).(map[string]int)
-		for name, value := range curMap {
-			if value > 0 {
-				if !form.Has(fmt.Sprintf("remove_block_%d_%s", room.ID, name)) {
-					err := re.DB.DeleteBlockByID(value)
-					if err != nil {
-						logrus.WithError(err).Error("cannot delete block")
-					}
-				}
+	for _, value := range r.PostForm["shown_block"] {
+		blockID, err := strconv.Atoi(value)
+		if err != nil {
+			helpers.Error(w, r, models.NewValidationError("shown_block", "invalid block id"))
+			return
+		}
+		if !form.Has(fmt.Sprintf("block_%d", blockID)) {
+			err := re.DB.DeleteBlockByID(r.Context(), blockID)
+			if err != nil {
+				logging.FromContext(r.Context()).WithError(err).Error("cannot delete block")
 			}
-
 		}
 	}
 
@@ -727,10 +850,10 @@ func (re *Repository) AdminPostReservationCalendar(w http.ResponseWriter, r *htt
 		if strings.HasPrefix(name, "add_block_") {
 			exploded := strings.Split(name, "_")
 			roomID, _ := strconv.Atoi(exploded[2])
-			t, _ := time.Parse("2006-01-2", exploded[3])
-			err := re.DB.InsertBlockForRoom(roomID, t)
+			night, _ := dates.Parse(exploded[3])
+			err := re.DB.InsertBlockForRoom(r.Context(), roomID, night.Time())
 			if err != nil {
-				logrus.WithError(err).Error("cannot insert block")
+				logging.FromContext(r.Context()).WithError(err).Error("cannot insert block")
 			}
 		}
 	}
@@ -3,15 +3,19 @@ package handlers
 import (
 	"booking/mocks"
 	"booking/models"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -30,8 +34,6 @@ var tests = []struct {
 }{
 	{"home", "/", http.MethodGet, http.StatusOK},
 	{"about", "/about", http.MethodGet, http.StatusOK},
-	{"generals-quarters", "/generals-quarters", http.MethodGet, http.StatusOK},
-	{"majors-suite", "/majors-suite", http.MethodGet, http.StatusOK},
 	{"search-availability", "/search-availability", http.MethodGet, http.StatusOK},
 	{"contact", "/contact", http.MethodGet, http.StatusOK},
 
@@ -80,13 +82,14 @@ func TestRepository_Reservation(t *testing.T) {
 	mockDB := mocks.NewMockDatabaseRepo(ctrl)
 	Repo.DB = mockDB
 
-	mockDB.EXPECT().GetRoomByID(gomock.Any())
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1)
 
 	reservation := models.Reservation{
-		RoomID: 1,
-		Room: models.Room{
-			ID:       1,
-			RoomName: "General's Quarters",
+		StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+		RoomType: models.RoomType{
+			ID:   1,
+			Name: "General's Quarters",
 		},
 	}
 
@@ -122,7 +125,7 @@ func TestRepository_Reservation(t *testing.T) {
 	req = req.WithContext(ctx)
 	rr = httptest.NewRecorder()
 
-	mockDB.EXPECT().GetRoomByID(gomock.Any()).Return(models.Room{}, errors.New("room not found"))
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), gomock.Any()).Return(models.RoomType{}, errors.New("room not found"))
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusTemporaryRedirect {
@@ -145,8 +148,19 @@ func TestRepository_PostReservation(t *testing.T) {
 	mockDB := mocks.NewMockDatabaseRepo(ctrl)
 	Repo.DB = mockDB
 
-	mockDB.EXPECT().InsertReservation(gomock.Any())
-	mockDB.EXPECT().InsertRoomRestriction(gomock.Any())
+	generals := models.RoomType{ID: 1, Name: "General's Quarters", MaxOccupancy: 2}
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, res models.Reservation) (models.Reservation, error) {
+			assert.Equal(t, models.StatusPending, res.Status)
+			// without counts the guest books for one adult
+			assert.Equal(t, 1, res.Adults)
+			assert.Equal(t, 0, res.Children)
+			res.ID, res.RoomID = 41, 3
+			return res, nil
+		})
+	mockDB.EXPECT().QueueMail(gomock.Any(), gomock.Any())
 
 	reqBody := "start_date=2050-01-01"
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "end_date=2050-01-02")
@@ -154,7 +168,7 @@ func TestRepository_PostReservation(t *testing.T) {
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "last_name=Nguyen")
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "email=khanhnguyen@gmail.com")
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "phone=123456789")
-	reqBody = fmt.Sprintf("%s&%s", reqBody, "room_id=1")
+	reqBody = fmt.Sprintf("%s&%s", reqBody, "room_type_id=1")
 
 	/*
 		postedData := url.Values{}
@@ -164,7 +178,7 @@ func TestRepository_PostReservation(t *testing.T) {
 		postedData.Add("last_name", "Nguyen")
 		postedData.Add("email", "khanhnguyen@gmail.com")
 		postedData.Add("phone", "123456789")
-		postedData.Add("room_id", "1")
+		postedData.Add("room_type_id", "1")
 	*/
 
 	req, _ := http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(reqBody))
@@ -181,6 +195,57 @@ func TestRepository_PostReservation(t *testing.T) {
 		t.Errorf("reservation handler returns wrong response code for successful case: got %v, wanted: %v", rr.Code, http.StatusSeeOther)
 	}
 
+	// more guests than the room sleeps are refused on the form
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	req, _ = http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(reqBody+"&adults=2&children=1"))
+	req = req.WithContext(getCtx(req))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "A General&#39;s Quarters sleeps at most 2 guests")
+
+	// the rules refuse the stay, the guest searches again with the reason
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(models.StayRules{{RoomType: generals, MinNights: 3}}, nil)
+	req, _ = http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(reqBody))
+	ctx = getCtx(req)
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, SEARCH_AVAIABILITY_URL, rr.Header().Get("Location"))
+	assert.Equal(t, "Stays arriving on Sat 01 Jan 2050 must last at least 3 nights", session.GetString(ctx, "error"))
+
+	// a departure on or before the arrival is refused before the stay is priced or booked
+	for _, end := range []string{"2050-01-01", "2049-12-30"} {
+		body := strings.Replace(reqBody, "end_date=2050-01-02", "end_date="+end, 1)
+		req, _ = http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(body))
+		ctx = getCtx(req)
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusSeeOther, rr.Code, end)
+		assert.Equal(t, SEARCH_AVAIABILITY_URL, rr.Header().Get("Location"), end)
+		assert.Equal(t, "Departure must be after arrival", session.GetString(ctx, "error"), end)
+	}
+
+	// the last room of the type was booked in the meantime, the guest searches again
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).Return(models.Reservation{}, models.ErrConflict)
+	req, _ = http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(reqBody))
+	ctx = getCtx(req)
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, SEARCH_AVAIABILITY_URL, rr.Header().Get("Location"))
+	assert.Contains(t, session.GetString(ctx, "error"), "the last General's Quarters was just booked")
+
 	// Test for missing post body
 	req, _ = http.NewRequest(http.MethodPost, "/make-reservation", nil)
 	ctx = getCtx(req)
@@ -200,7 +265,7 @@ func TestRepository_PostReservation(t *testing.T) {
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "last_name=Nguyen")
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "email=khanhnguyen@gmail.com")
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "phone=123456789")
-	reqBody = fmt.Sprintf("%s&%s", reqBody, "room_id=1")
+	reqBody = fmt.Sprintf("%s&%s", reqBody, "room_type_id=1")
 
 	req, _ = http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(reqBody))
 	ctx = getCtx(req)
@@ -221,7 +286,7 @@ func TestRepository_PostReservation(t *testing.T) {
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "last_name=Nguyen")
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "email=khanhnguyen@gmail.com")
 	reqBody = fmt.Sprintf("%s&%s", reqBody, "phone=123456789")
-	reqBody = fmt.Sprintf("%s&%s", reqBody, "room_id=1")
+	reqBody = fmt.Sprintf("%s&%s", reqBody, "room_type_id=1")
 
 	req, _ = http.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(reqBody))
 	ctx = getCtx(req)
@@ -234,3 +299,1381 @@ func TestRepository_PostReservation(t *testing.T) {
 		t.Errorf("reservation handler returns wrong response code for invalid end date: got %v, wanted: %v", rr.Code, http.StatusTemporaryRedirect)
 	}
 }
+
+func TestRepository_AdminReservations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminReservations)
+
+	filter := models.ReservationFilter{
+		Status: models.StatusCheckedIn,
+		Search: "nguyen",
+		RoomID: 2,
+		From:   time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		Sort:   models.SortByName,
+		Desc:   true,
+		Page:   2,
+	}
+	mockDB.EXPECT().Reservations(gomock.Any(), filter).Return(models.ReservationPage{
+		Reservations: []models.Reservation{{ID: 1, LastName: "Nguyen", Status: models.StatusCheckedIn}},
+		Total:        30,
+		Page:         2,
+		PerPage:      25,
+	}, nil)
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return([]models.Room{{ID: 2, RoomName: "Major's Suite"}}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations?status=checked_in&q=nguyen&room=2&from=2026-11-01&sort=name&dir=desc&page=2", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "/admin/reservations/checked_in/1/show")
+	assert.Contains(t, rr.Body.String(), "page 2 of 2")
+	// the sort links keep the filters but go back to the first page
+	assert.Contains(t, rr.Body.String(), "/admin/reservations?from=2026-11-01&amp;q=nguyen&amp;room=2&amp;sort=departure&amp;status=checked_in")
+
+	// an unknown sort key never reaches the database
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations?sort=password", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// an unknown status never reaches the database
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations?status=processed", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// a query running past its deadline is reported as unavailable
+	mockDB.EXPECT().Reservations(gomock.Any(), models.ReservationFilter{}).Return(models.ReservationPage{}, context.DeadlineExceeded)
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	// any other database error is an internal error
+	mockDB.EXPECT().Reservations(gomock.Any(), models.ReservationFilter{}).Return(models.ReservationPage{}, errors.New("connection refused"))
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestRepository_AdminShowReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminShowReservation)
+
+	// a missing reservation renders the 404 page with an error id
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 42).Return(models.Reservation{}, fmt.Errorf("%w: no rows", models.ErrNotFound))
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations/all/42/show", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), "error ID")
+
+	// JSON clients get the same error as JSON
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 42).Return(models.Reservation{}, models.ErrNotFound)
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations/all/42/show", nil)
+	req.Header.Set("Accept", "application/json")
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"error_id"`)
+
+	// a malformed id is a bad request and never reaches the database
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations/all/abc/show", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_AdminAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	entries := []models.AuditEntry{
+		{ID: 1, UserID: 1, UserName: "Khanh Nguyen", Action: "reservation.cancel", EntityType: "reservations", EntityID: 3, Before: `{"id":3}`},
+	}
+
+	mockDB.EXPECT().AuditLog(gomock.Any(), models.AuditFilter{Action: "reservation.cancel", EntityID: 3}).Return(entries, nil)
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit?action=reservation.cancel&entity_id=3", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminAuditLog).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Khanh Nguyen")
+
+	mockDB.EXPECT().AuditLog(gomock.Any(), gomock.Any()).Return(entries, nil)
+	req = httptest.NewRequest(http.MethodGet, "/admin/audit/export", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminAuditLogExport).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "reservation.cancel")
+
+	// invalid filters never reach the database
+	req = httptest.NewRequest(http.MethodGet, "/admin/audit?from=yesterday", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminAuditLog).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// withURLParams adds chi route parameters to the request context
+func withURLParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestRepository_AdminCancelReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminCancelReservation)
+
+	mockDB.EXPECT().CancelReservation(gomock.Any(), 3, "guest called", gomock.Any()).Return(models.Cancellation{}, nil)
+	body := url.Values{"reason": {" guest called "}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/cancel-reservation/pending/3", strings.NewReader(body.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"src": "pending", "id": "3"})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations?status=pending", rr.Header().Get("Location"))
+
+	// a checked in reservation cannot be cancelled
+	mockDB.EXPECT().CancelReservation(gomock.Any(), 3, "", gomock.Any()).Return(models.Cancellation{}, fmt.Errorf("%w: checked in", models.ErrConflict))
+	req = httptest.NewRequest(http.MethodPost, "/admin/cancel-reservation/all/3", nil)
+	req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"src": "all", "id": "3"})
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestRepository_AdminRestoreReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminRestoreReservation)
+
+	mockDB.EXPECT().RestoreReservation(gomock.Any(), 3).Return(nil)
+	req := httptest.NewRequest(http.MethodPost, "/admin/restore-reservation/3", nil)
+	req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"id": "3"})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations/all/3/show", rr.Header().Get("Location"))
+
+	// the room has been booked since, the reservation stays in the trash
+	mockDB.EXPECT().RestoreReservation(gomock.Any(), 3).Return(fmt.Errorf("%w: room taken", models.ErrConflict))
+	req = httptest.NewRequest(http.MethodPost, "/admin/restore-reservation/3", nil)
+	req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"id": "3"})
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations-trash", rr.Header().Get("Location"))
+
+	mockDB.EXPECT().CancelledReservations(gomock.Any()).Return([]models.Reservation{{ID: 3, LastName: "Nguyen", CancelReason: "guest called"}}, nil)
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations-trash", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminTrashReservations).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "guest called")
+}
+
+func TestRepository_AdminTransitionReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminTransitionReservation)
+	post := func(status, year string) *httptest.ResponseRecorder {
+		body := url.Values{"status": {status}, "y": {year}, "m": {"11"}}
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations/all/3/status", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"src": "all", "id": "3"})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockDB.EXPECT().TransitionReservation(gomock.Any(), 3, models.StatusCheckedIn).Return(nil)
+	rr := post("checked_in", "")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations", rr.Header().Get("Location"))
+
+	mockDB.EXPECT().TransitionReservation(gomock.Any(), 3, models.StatusConfirmed).Return(nil)
+	rr = post("confirmed", "2026")
+	assert.Equal(t, "/admin/reservations-calendar?y=2026&m=11", rr.Header().Get("Location"))
+
+	// transitions the lifecycle does not allow send the admin back to the reservation
+	mockDB.EXPECT().TransitionReservation(gomock.Any(), 3, models.StatusCheckedOut).
+		Return(fmt.Errorf("%w: a Pending reservation cannot become Checked out", models.ErrConflict))
+	rr = post("checked_out", "")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations/all/3/show", rr.Header().Get("Location"))
+
+	rr = post("processed", "")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// cancelling from the status list works out the penalty on the same day as the cancel button
+	body := url.Values{"status": {"cancelled"}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/reservations/all/3/status", strings.NewReader(body.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx := models.WithProperty(getCtx(req), models.Property{ID: 1, Currency: "EUR"})
+	req = withURLParams(req.WithContext(ctx), map[string]string{"src": "all", "id": "3"})
+	mockDB.EXPECT().CancelReservation(gomock.Any(), 3, "", Repo.today(ctx)).
+		Return(models.Cancellation{Penalty: 10800, Refund: 10800}, nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "Reservation cancelled, EUR 108.00 charged and EUR 108.00 to refund", session.GetString(ctx, "flash"))
+}
+
+func TestRepository_AdminExportReservations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminExportReservations)
+
+	mockDB.EXPECT().EachReservation(gomock.Any(), models.ReservationFilter{Status: models.StatusConfirmed}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ models.ReservationFilter, fn func(models.Reservation) error) error {
+			return fn(models.Reservation{ID: 1, FirstName: "Khanh", LastName: "Nguyen", Status: models.StatusConfirmed})
+		})
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations/export?status=confirmed&columns=id&columns=guest", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "ID,Guest\n1,Khanh Nguyen\n", rr.Body.String())
+
+	// unknown formats and columns never reach the database
+	for _, query := range []string{"format=pdf", "columns=password"} {
+		req = httptest.NewRequest(http.MethodGet, "/admin/reservations/export?"+query, nil)
+		req = req.WithContext(getCtx(req))
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestRepository_AdminPostImportReservations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminPostImportReservations)
+	post := func(file string, commit bool) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if commit {
+			mw.WriteField("commit", "1")
+		}
+		fw, _ := mw.CreateFormFile("file", "reservations.csv")
+		fw.Write([]byte(file))
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations/import", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req = req.WithContext(getCtx(req))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	file := "first_name,last_name,email,phone,start_date,end_date,room_id\n" +
+		"Khanh,Nguyen,khanh@example.com,555-0100,2026-11-01,2026-11-03,1\n" +
+		"Jo,Smith,jo@example.com,555-0101,2026-11-01,2026-11-03,1\n"
+
+	mockDB.EXPECT().ImportReservations(gomock.Any(), gomock.Len(2), false).Return(nil)
+	rr := post(file, false)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Dry run")
+	assert.Contains(t, rr.Body.String(), "1 accepted, 1 rejected")
+
+	mockDB.EXPECT().ImportReservations(gomock.Any(), gomock.Len(2), true).
+		DoAndReturn(func(_ context.Context, rows []models.ImportRow, _ bool) error {
+			rows[0].Reservation.ID = 40
+			return nil
+		})
+	rr = post(file, true)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "/admin/reservations/all/40/show")
+
+	// files that cannot be read never reach the database
+	rr = post("first_name\nKhanh\n", false)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestReportPeriodFromQuery(t *testing.T) {
+	day := time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC) // a thursday
+
+	var periodTests = []struct {
+		query string
+		from  string
+		to    string
+	}{
+		{"", "2026-10-01", "2026-11-01"},
+		{"period=week", "2026-10-19", "2026-10-26"},
+		{"period=last_month", "2026-09-01", "2026-10-01"},
+		{"period=quarter", "2026-10-01", "2027-01-01"},
+		{"period=year", "2026-01-01", "2027-01-01"},
+		// the last night is included
+		{"from=2026-10-05&to=2026-10-05", "2026-10-05", "2026-10-06"},
+	}
+
+	for _, test := range periodTests {
+		q, _ := url.ParseQuery(test.query)
+		p, err := reportPeriodFromQuery(q, day)
+		assert.NoError(t, err, test.query)
+		assert.Equal(t, test.from, p.From.Format(dateLayout), test.query)
+		assert.Equal(t, test.to, p.To.Format(dateLayout), test.query)
+	}
+
+	for _, query := range []string{"period=decade", "from=2026-10-05", "from=2026-10-05&to=2026-10-01", "from=2020-01-01&to=2026-01-01"} {
+		q, _ := url.ParseQuery(query)
+		_, err := reportPeriodFromQuery(q, day)
+		assert.True(t, errors.Is(err, models.ErrValidation), query)
+	}
+}
+
+func TestRepository_AdminDashboard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	occupancy := models.OccupancyReport{
+		Rooms:   []models.RoomOccupancy{{RoomID: 1, RoomName: "Major Suite", Nights: 3, Available: 4, Rate: 0.75}},
+		Overall: models.RoomOccupancy{RoomName: "All rooms", Nights: 3, Available: 4, Rate: 0.75},
+	}
+	mockDB.EXPECT().Movements(gomock.Any(), gomock.Any()).Return(models.Movements{Arrivals: 2, Departures: 1}, nil).Times(2)
+	mockDB.EXPECT().Occupancy(gomock.Any(), gomock.Any()).Return(occupancy, nil)
+	mockDB.EXPECT().StayStats(gomock.Any(), gomock.Any()).Return(models.StayStats{Reservations: 2, Nights: 3, LeadTimes: models.NewLeadTimes()}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/dashboard?period=week", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminDashboard).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Major Suite")
+	assert.Contains(t, rr.Body.String(), "75%")
+	assert.Contains(t, rr.Body.String(), "1.5 nights")
+
+	mockDB.EXPECT().Occupancy(gomock.Any(), gomock.Any()).Return(occupancy, nil)
+	req = httptest.NewRequest(http.MethodGet, "/admin/dashboard/occupancy?from=2026-11-01&to=2026-11-04", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminOccupancyJSON).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"rate":0.75`)
+
+	mockDB.EXPECT().StayStats(gomock.Any(), gomock.Any()).Return(models.StayStats{Reservations: 2, Nights: 3, LeadTimes: models.NewLeadTimes()}, nil)
+	req = httptest.NewRequest(http.MethodGet, "/admin/dashboard/stays", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminStaysJSON).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"average_stay":1.5`)
+	assert.Contains(t, rr.Body.String(), `"lead_times":[`)
+
+	// bad periods never reach the database
+	req = httptest.NewRequest(http.MethodGet, "/admin/dashboard/stays?period=decade", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminStaysJSON).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_AdminFrontDesk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminFrontDesk)
+	day := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	desk := models.FrontDesk{
+		Date: day,
+		Arrivals: []models.Reservation{{
+			ID: 1, LastName: "Nguyen", StartDate: day, EndDate: day.AddDate(0, 0, 2), Status: models.StatusConfirmed,
+			Notes: []models.ReservationNote{{Body: "Needs a cot"}},
+		}},
+	}
+
+	mockDB.EXPECT().FrontDesk(gomock.Any(), day).Return(desk, nil)
+	req := httptest.NewRequest(http.MethodGet, "/admin/front-desk?date=2026-11-10", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Needs a cot")
+	assert.Contains(t, rr.Body.String(), "Check in")
+	assert.Contains(t, rr.Body.String(), "/admin/front-desk?date=2026-11-11")
+
+	mockDB.EXPECT().FrontDesk(gomock.Any(), day).Return(desk, nil)
+	req = httptest.NewRequest(http.MethodGet, "/admin/front-desk?date=2026-11-10&print=1", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Run sheet 2026-11-10")
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/front-desk?date=tomorrow", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_AdminAddReservationNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminAddReservationNote)
+	post := func(note string) *httptest.ResponseRecorder {
+		body := url.Values{"note": {note}, "date": {"2026-11-10"}}
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations/front-desk/3/notes", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"src": "front-desk", "id": "3"})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockDB.EXPECT().AddReservationNote(gomock.Any(), 3, "Late checkout").Return(1, nil)
+	rr := post(" Late checkout ")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/front-desk?date=2026-11-10", rr.Header().Get("Location"))
+
+	rr = post("  ")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockDB.EXPECT().AddReservationNote(gomock.Any(), 3, "Late checkout").Return(0, models.ErrNotFound)
+	rr = post("Late checkout")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestRepository_AdminSendGuestEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	emailTemplatePath = "../email-templates"
+	defer func() { emailTemplatePath = "./email-templates" }()
+
+	handler := http.HandlerFunc(Repo.AdminSendGuestEmail)
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		body.Set("date", "2026-11-10")
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations/front-desk/3/email", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"src": "front-desk", "id": "3"})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	res := models.Reservation{ID: 3, FirstName: "Khanh", Email: "khanh@example.com", Status: models.StatusConfirmed}
+
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 3).Return(res, nil)
+	mockDB.EXPECT().QueueMail(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg models.MailData) (int, error) {
+			assert.Equal(t, 3, msg.ReservationID)
+			assert.Equal(t, "khanh@example.com", msg.To)
+			assert.Equal(t, "basic.html", msg.Template)
+			// staff write plain text
+			assert.Equal(t, "Parking is at the back &lt;3<br>\nSee you", msg.Content)
+			return 9, nil
+		})
+	rr := post(url.Values{"subject": {"Parking"}, "body": {"Parking is at the back <3\nSee you"}, "template": {"basic.html"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations/front-desk/3/show?date=2026-11-10", rr.Header().Get("Location"))
+
+	// invalid emails show the reservation again with the errors and what was typed
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 3).Return(res, nil)
+	mockDB.EXPECT().ReservationTimeline(gomock.Any(), 3).Return(nil, nil)
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return(nil, nil)
+	rr = post(url.Values{"body": {"Parking is at the back"}, "template": {"../../etc/passwd"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "This field cannot be empty")
+	assert.Contains(t, rr.Body.String(), "Unknown email template")
+	assert.Contains(t, rr.Body.String(), "Parking is at the back")
+}
+
+func TestRepository_AdminReservationTimeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 3).Return(models.Reservation{ID: 3, Status: models.StatusConfirmed}, nil)
+	mockDB.EXPECT().ReservationTimeline(gomock.Any(), 3).Return([]models.TimelineEntry{
+		{Kind: models.TimelineEmail, Title: "Email to khanh@example.com", Body: "Parking", MailID: 9, MailStatus: models.MailFailed},
+		{Kind: models.TimelineNote, Title: "Note", Body: "Late arrival", UserName: "Khanh Nguyen"},
+	}, nil)
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return(nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations/all/3/show", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminShowReservation).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `href="/admin/outbox/9"`)
+	assert.Contains(t, rr.Body.String(), "Late arrival")
+
+	mockDB.EXPECT().GetOutboxMail(gomock.Any(), 9).Return(models.OutboxMail{
+		ID: 9, ReservationID: 3, To: "khanh@example.com", Subject: "Parking", Content: "<p>Hi</p>",
+		Status: models.MailFailed, Error: "connection refused",
+	}, nil)
+	req = httptest.NewRequest(http.MethodGet, "/admin/outbox/9", nil)
+	req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"id": "9"})
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminShowOutboxMail).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "connection refused")
+	assert.Contains(t, rr.Body.String(), `srcdoc="&lt;p&gt;Hi&lt;/p&gt;"`)
+}
+
+func TestRepository_AdminPostNewReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminPostNewReservation)
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations/new", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(getCtx(req))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	booking := func() url.Values {
+		return url.Values{
+			"room_id":    {"2"},
+			"start_date": {"2026-11-01"},
+			"end_date":   {"2026-11-02"},
+			"first_name": {"Khanh"},
+			"last_name":  {"Nguyen"},
+			"email":      {"khanh@example.com"},
+			"phone":      {"123456"},
+			"status":     {"confirmed"},
+			"adults":     {"3"},
+			"children":   {"2"},
+		}
+	}
+
+	// staff may book more guests than the room sleeps
+	mockDB.EXPECT().CreateReservation(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, res models.Reservation) (int, error) {
+			assert.Equal(t, 2, res.RoomID)
+			assert.Equal(t, 3, res.Adults)
+			assert.Equal(t, 2, res.Children)
+			assert.Equal(t, models.StatusConfirmed, res.Status)
+			assert.Equal(t, 1, res.Nights())
+			return 41, nil
+		})
+	rr := post(booking())
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations/all/41/show", rr.Header().Get("Location"))
+
+	// a taken room shows the form again with what was typed
+	mockDB.EXPECT().CreateReservation(gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("%w: taken", models.ErrConflict))
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return([]models.Room{{ID: 2, RoomName: "Major's Suite"}}, nil)
+	rr = post(booking())
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "The room is already booked or blocked for these dates")
+	assert.Contains(t, rr.Body.String(), `value="khanh@example.com"`)
+
+	// invalid stays never reach the database
+	body := booking()
+	body.Set("end_date", "2026-11-01")
+	body.Set("status", "checked_out")
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return(nil, nil)
+	rr = post(body)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Departure must be after arrival")
+	assert.Contains(t, rr.Body.String(), "Choose a status")
+}
+
+func TestRepository_AdminMoveReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminMoveReservation)
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		body.Set("y", "2026")
+		body.Set("m", "11")
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations/cal/3/move", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = withURLParams(req.WithContext(getCtx(req)), map[string]string{"src": "cal", "id": "3"})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	mockDB.EXPECT().MoveReservation(gomock.Any(), 3, 2, start, start.AddDate(0, 0, 3)).Return(nil)
+	rr := post(url.Values{"room_id": {"2"}, "start_date": {"2026-11-01"}, "end_date": {"2026-11-04"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations/cal/3/show?m=11&y=2026", rr.Header().Get("Location"))
+
+	// a clash shows the reservation again with the error on the move form
+	res := models.Reservation{ID: 3, RoomID: 1, StartDate: start, EndDate: start.AddDate(0, 0, 2), Status: models.StatusConfirmed}
+	mockDB.EXPECT().MoveReservation(gomock.Any(), 3, 2, start, start.AddDate(0, 0, 3)).Return(fmt.Errorf("%w: taken", models.ErrConflict))
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 3).Return(res, nil)
+	mockDB.EXPECT().ReservationTimeline(gomock.Any(), 3).Return(nil, nil)
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return([]models.Room{{ID: 1, RoomName: "General's Quarters"}, {ID: 2, RoomName: "Major's Suite"}}, nil)
+	rr = post(url.Values{"room_id": {"2"}, "start_date": {"2026-11-01"}, "end_date": {"2026-11-04"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "The room is already booked or blocked for these dates")
+	assert.Contains(t, rr.Body.String(), `value="2026-11-04"`)
+
+	// reservations that are over cannot be moved
+	mockDB.EXPECT().MoveReservation(gomock.Any(), 3, 2, start, start.AddDate(0, 0, 3)).
+		Return(models.NewValidationError("", "a checked out reservation cannot be moved"))
+	rr = post(url.Values{"room_id": {"2"}, "start_date": {"2026-11-01"}, "end_date": {"2026-11-04"}})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_AdminCalendarTimeline(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations-timeline?start=2026-11&months=2", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminCalendarTimeline).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `data-from="2026-11-01" data-to="2027-01-01"`)
+	assert.Contains(t, rr.Body.String(), `href="/admin/reservations-timeline?months=2&amp;start=2026-09"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations-timeline?months=5", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminCalendarTimeline).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_AdminCalendarJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return([]models.Room{{ID: 1, RoomName: "General's Quarters"}, {ID: 2, RoomName: "Major's Suite"}}, nil)
+	mockDB.EXPECT().RestrictionsInRange(gomock.Any(), from, to).Return([]models.RoomRestriction{
+		{ID: 7, RoomID: 1, ReservationID: 3, StartDate: from, EndDate: from.AddDate(0, 0, 2),
+			Reservation: models.Reservation{FirstName: "Khanh", LastName: "Nguyen", Status: models.StatusConfirmed}},
+		{ID: 8, RoomID: 1, StartDate: from.AddDate(0, 0, 5), EndDate: from.AddDate(0, 0, 6)},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/calendar/restrictions?from=2026-11-01&to=2026-12-01", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminCalendarJSON).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"from":"2026-11-01","to":"2026-12-01","rooms":[
+		{"id":1,"name":"General's Quarters","entries":[
+			{"id":7,"kind":"reservation","reservation_id":3,"guest":"Khanh Nguyen","status":"Confirmed","start_date":"2026-11-01","end_date":"2026-11-03"},
+			{"id":8,"kind":"block","start_date":"2026-11-06","end_date":"2026-11-07"}]},
+		{"id":2,"name":"Major's Suite","entries":[]}]}`, rr.Body.String())
+
+	// ranges are checked before anything is loaded
+	req = httptest.NewRequest(http.MethodGet, "/admin/calendar/restrictions?from=2026-11-01&to=2028-11-01", nil)
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminCalendarJSON).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_AdminCalendarChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	post := func(handler http.HandlerFunc, target string, params map[string]string, body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		req = withURLParams(req.WithContext(getCtx(req)), params)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	stay := url.Values{"room_id": {"2"}, "start_date": {"2026-11-01"}, "end_date": {"2026-11-04"}}
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	mockDB.EXPECT().CreateBlock(gomock.Any(), 2, start, start.AddDate(0, 0, 3)).Return(12, nil)
+	rr := post(Repo.AdminCalendarBlock, "/admin/calendar/blocks", nil, stay)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"ok":true,"id":12}`, rr.Body.String())
+
+	// the repository refuses overlaps, the timeline gets the error as JSON
+	mockDB.EXPECT().CreateBlock(gomock.Any(), 2, start, start.AddDate(0, 0, 3)).Return(0, fmt.Errorf("%w: taken", models.ErrConflict))
+	rr = post(Repo.AdminCalendarBlock, "/admin/calendar/blocks", nil, stay)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message"`)
+
+	rr = post(Repo.AdminCalendarBlock, "/admin/calendar/blocks", nil, url.Values{"room_id": {"2"}, "start_date": {"2026-11-04"}, "end_date": {"2026-11-01"}})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Departure must be after arrival")
+
+	mockDB.EXPECT().MoveReservation(gomock.Any(), 3, 2, start, start.AddDate(0, 0, 3)).Return(nil)
+	rr = post(Repo.AdminCalendarMove, "/admin/calendar/reservations/3/move", map[string]string{"id": "3"}, stay)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"ok":true}`, rr.Body.String())
+
+	mockDB.EXPECT().DeleteBlockByID(gomock.Any(), 12).Return(models.ErrNotFound)
+	rr = post(Repo.AdminCalendarDeleteBlock, "/admin/calendar/blocks/12/delete", map[string]string{"id": "12"}, url.Values{})
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestRepository_AdminReservationCalendar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	// one query for every room of the month
+	mockDB.EXPECT().AllRooms(gomock.Any()).Return([]models.Room{{ID: 1, RoomName: "General's Quarters"}, {ID: 2, RoomName: "Major's Suite"}}, nil)
+	mockDB.EXPECT().RestrictionsInRange(gomock.Any(), from, from.AddDate(0, 1, 0)).Return([]models.RoomRestriction{
+		{ID: 7, RoomID: 1, ReservationID: 3, StartDate: from, EndDate: from.AddDate(0, 0, 2)},
+		{ID: 9, RoomID: 2, StartDate: from.AddDate(0, 0, 9), EndDate: from.AddDate(0, 0, 11)},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations-calendar?y=2026&m=11", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminReservationCalendar).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	body := rr.Body.String()
+	assert.Equal(t, 2, strings.Count(body, `href="/admin/reservations/cal/3/show?y=2026&m=11"`))
+	assert.Contains(t, body, `name="shown_block"`)
+	assert.Equal(t, 1, strings.Count(body, `name="block_9"`))
+	assert.Contains(t, body, `name="add_block_1_2026-11-03"`)
+	assert.NotContains(t, body, `name="add_block_2_2026-11-10"`)
+
+	// unticked blocks are removed, ticked days are blocked
+	form := url.Values{
+		"y":                      {"2026"},
+		"m":                      {"11"},
+		"shown_block":            {"9", "12"},
+		"block_12":               {"1"},
+		"add_block_1_2026-11-20": {"1"},
+	}
+	mockDB.EXPECT().DeleteBlockByID(gomock.Any(), 9).Return(nil)
+	mockDB.EXPECT().InsertBlockForRoom(gomock.Any(), 1, from.AddDate(0, 0, 19)).Return(nil)
+	req = httptest.NewRequest(http.MethodPost, "/admin/reservations-calendar", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(getCtx(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminPostReservationCalendar).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/reservations-calendar?y=2026&m=11", rr.Header().Get("Location"))
+}
+
+func TestParseStay(t *testing.T) {
+	tests := []struct {
+		start, end string
+		field      string
+		nights     int
+	}{
+		{"2026-11-10", "2026-11-12", "", 2},
+		{"2026-03-28", "2026-03-30", "", 2},
+		{"2026-11-10", "2026-11-10", "end", 0},
+		{"2026-11-10", "2026-11-09", "end", 0},
+		{"2026-02-30", "2026-03-02", "start", 0},
+		{"2026-11-10", "", "end", 0},
+	}
+
+	for _, test := range tests {
+		stay, err := parseStay(test.start, test.end)
+		if test.field == "" {
+			assert.NoError(t, err, test.start+" "+test.end)
+			assert.Equal(t, test.nights, stay.Nights(), test.start+" "+test.end)
+			continue
+		}
+		var validationErr *models.ValidationError
+		if assert.True(t, errors.As(err, &validationErr), test.start+" "+test.end) {
+			assert.Equal(t, test.field, validationErr.Field, test.start+" "+test.end)
+		}
+	}
+}
+
+func TestRepository_AdminPropertyScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	var got models.Property
+	var switchable []models.Property
+	handler := Repo.AdminPropertyScope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = models.PropertyFromContext(r.Context())
+		switchable = models.UserPropertiesFromContext(r.Context())
+	}))
+	serve := func(userID, propertyID int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil)
+		ctx := getCtx(req)
+		if userID > 0 {
+			session.Put(ctx, "user_id", userID)
+		}
+		if propertyID > 0 {
+			session.Put(ctx, "property_id", propertyID)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req.WithContext(ctx))
+		return rr
+	}
+
+	lakeside := models.Property{ID: 2, Name: "Lakeside"}
+	town := models.Property{ID: 3, Name: "Town House"}
+
+	// the first property until the user switches
+	mockDB.EXPECT().UserProperties(gomock.Any(), 7).Return([]models.Property{lakeside, town}, nil)
+	serve(7, 0)
+	assert.Equal(t, lakeside, got)
+	assert.Len(t, switchable, 2)
+
+	mockDB.EXPECT().UserProperties(gomock.Any(), 7).Return([]models.Property{lakeside, town}, nil)
+	serve(7, 3)
+	assert.Equal(t, town, got)
+
+	// a property the user no longer manages is not kept
+	mockDB.EXPECT().UserProperties(gomock.Any(), 7).Return([]models.Property{lakeside}, nil)
+	serve(7, 3)
+	assert.Equal(t, lakeside, got)
+
+	got = models.Property{}
+	mockDB.EXPECT().UserProperties(gomock.Any(), 8).Return(nil, nil)
+	rr := serve(8, 0)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, models.Property{}, got)
+}
+
+func TestRepository_AdminSwitchProperty(t *testing.T) {
+	handler := http.HandlerFunc(Repo.AdminSwitchProperty)
+	post := func(id string) (*httptest.ResponseRecorder, context.Context) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/property/switch", strings.NewReader(url.Values{"property_id": {id}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx := models.WithUserProperties(getCtx(req), []models.Property{{ID: 2, Name: "Lakeside"}, {ID: 3, Name: "Town House"}})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req.WithContext(ctx))
+		return rr, ctx
+	}
+
+	rr, ctx := post("3")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/dashboard", rr.Header().Get("Location"))
+	assert.Equal(t, 3, session.GetInt(ctx, "property_id"))
+
+	rr, ctx = post("4")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, 0, session.GetInt(ctx, "property_id"))
+}
+
+func TestRepository_AdminPostPropertySettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	handler := http.HandlerFunc(Repo.AdminPostPropertySettings)
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/property", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx := models.WithProperty(getCtx(req), models.Property{ID: 2, Name: "Lakeside"})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req.WithContext(ctx))
+		return rr
+	}
+	settings := func() url.Values {
+		return url.Values{
+			"name":          {"Lakeside Lodge"},
+			"contact_email": {"lake@example.com"},
+			"time_zone":     {"Europe/Paris"},
+			"currency":      {"EUR"},
+			"brand_color":   {"#336699"},
+		}
+	}
+
+	mockDB.EXPECT().UpdateProperty(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, p models.Property) error {
+			assert.Equal(t, 2, p.ID)
+			assert.Equal(t, "Lakeside Lodge", p.Name)
+			assert.Equal(t, "lake@example.com", p.ContactEmail)
+			assert.Equal(t, "Europe/Paris", p.TimeZone)
+			return nil
+		})
+	rr := post(settings())
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/property", rr.Header().Get("Location"))
+
+	// invalid settings are shown again and never saved
+	body := settings()
+	body.Set("time_zone", "Mars/Olympus")
+	body.Set("currency", "euro")
+	body.Set("brand_color", "blue")
+	body.Set("logo_url", "javascript:alert(1)")
+	rr = post(body)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Unknown time zone")
+	assert.Contains(t, rr.Body.String(), "Use a three letter code such as EUR")
+	assert.Contains(t, rr.Body.String(), "Use a color such as #336699")
+	assert.Contains(t, rr.Body.String(), "Use an https:// address")
+	assert.Contains(t, rr.Body.String(), `value="Lakeside Lodge"`)
+}
+
+func TestRepository_AvailabilityByType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	start := time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	types := []models.TypeAvailability{
+		{RoomType: models.RoomType{ID: 3, Name: "Standard Double"}, Units: 4, Available: 2},
+		{RoomType: models.RoomType{ID: 4, Name: "Suite"}, Units: 1, Available: 0},
+	}
+	post := func(handler http.HandlerFunc, target string, body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(getCtx(req))
+		req.ParseForm()
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// only the types with rooms left are offered, searching for every guest
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().SearchAvailabilityByType(gomock.Any(), start, end, 3).Return(types, nil)
+	rr := post(Repo.PostAvailability, "/search-availability", url.Values{"start": {"2050-01-01"}, "end": {"2050-01-03"},
+		"adults": {"2"}, "children": {"1"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `href="/choose-room/3"`)
+	assert.Contains(t, rr.Body.String(), "2 rooms left")
+	assert.NotContains(t, rr.Body.String(), `href="/choose-room/4"`)
+	assert.Contains(t, rr.Body.String(), "For 2 adults, 1 child")
+
+	// a stay without adults is refused
+	rr = post(Repo.PostAvailability, "/search-availability", url.Values{"start": {"2050-01-01"}, "end": {"2050-01-03"},
+		"adults": {"0"}})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().SearchAvailabilityByType(gomock.Any(), start, end, 1).Return(types, nil)
+	rr = post(Repo.AvailabilityJSON, "/search-availability-json", url.Values{"start": {"2050-01-01"}, "end": {"2050-01-03"}, "room_type_id": {"3"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"ok": true`)
+	assert.Contains(t, rr.Body.String(), `"available": 2`)
+
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().SearchAvailabilityByType(gomock.Any(), start, end, 1).Return(types, nil)
+	rr = post(Repo.AvailabilityJSON, "/search-availability-json", url.Values{"start": {"2050-01-01"}, "end": {"2050-01-03"}, "room_type_id": {"4"}})
+	assert.Contains(t, rr.Body.String(), `"ok": false`)
+}
+
+func TestRepository_AvailabilityStayRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	start := time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	standard := models.RoomType{ID: 3, Name: "Standard Double"}
+	types := []models.TypeAvailability{{RoomType: standard, Units: 4, Available: 2}}
+	post := func(handler http.HandlerFunc, target string, body url.Values) (*httptest.ResponseRecorder, context.Context) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx := getCtx(req)
+		req = req.WithContext(ctx)
+		req.ParseForm()
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr, ctx
+	}
+	stay := url.Values{"start": {"2050-01-01"}, "end": {"2050-01-03"}, "room_type_id": {"3"}}
+
+	// a rule of every type refuses the stay before searching
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(models.StayRules{{ClosedToArrival: true}}, nil)
+	rr, ctx := post(Repo.PostAvailability, "/search-availability", stay)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "Arrivals are closed on Sat 01 Jan 2050", session.GetString(ctx, "error"))
+
+	// a rule of the only type left tells why nothing is offered
+	rules := models.StayRules{{RoomType: standard, MaxNights: 1}}
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(rules, nil)
+	mockDB.EXPECT().SearchAvailabilityByType(gomock.Any(), start, end, 1).Return(types, nil)
+	rr, ctx = post(Repo.PostAvailability, "/search-availability", stay)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "Stays arriving on Sat 01 Jan 2050 can last at most 1 night", session.GetString(ctx, "error"))
+
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(rules, nil)
+	mockDB.EXPECT().SearchAvailabilityByType(gomock.Any(), start, end, 1).Return(types, nil)
+	rr, _ = post(Repo.AvailabilityJSON, "/search-availability-json", stay)
+	assert.Contains(t, rr.Body.String(), `"ok": false`)
+	assert.Contains(t, rr.Body.String(), `"message": "Stays arriving on Sat 01 Jan 2050 can last at most 1 night"`)
+
+	// past arrivals are refused without any rule
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	rr, ctx = post(Repo.PostAvailability, "/search-availability", url.Values{"start": {"2020-01-01"}, "end": {"2020-01-03"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "Arrival cannot be in the past", session.GetString(ctx, "error"))
+}
+
+func TestRepository_AdminPostStayRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/stay-rules", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(getCtx(req))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(Repo.AdminPostStayRule).ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 3).Return(models.RoomType{ID: 3}, nil)
+	mockDB.EXPECT().CreateStayRule(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, rule models.StayRule) (int, error) {
+			assert.Equal(t, 3, rule.RoomType.ID)
+			assert.Equal(t, 2, rule.MinNights)
+			assert.True(t, rule.ClosedToArrival)
+			assert.Equal(t, models.Weekdays(0).With(time.Saturday), rule.Weekdays)
+			assert.Equal(t, time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC), rule.StartDate)
+			return 4, nil
+		})
+	rr := post(url.Values{"room_type_id": {"3"}, "start_date": {"2026-12-20"}, "end_date": {"2027-01-03"},
+		"weekday": {"6"}, "min_nights": {"2"}, "closed_to_arrival": {"1"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/stay-rules", rr.Header().Get("Location"))
+
+	// a rule restricting nothing, or the wrong way round, shows the form again
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil).Times(2)
+	mockDB.EXPECT().RoomTypes(gomock.Any()).Return(nil, nil).Times(2)
+	rr = post(url.Values{"start_date": {"2026-12-20"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Set at least one restriction")
+	rr = post(url.Values{"min_nights": {"5"}, "max_nights": {"2"}, "end_date": {"2026-12-01"}, "start_date": {"2026-12-20"}})
+	assert.Contains(t, rr.Body.String(), "The maximum cannot be below the minimum")
+	assert.Contains(t, rr.Body.String(), "Until must be after from")
+}
+
+func TestRepository_AdminRoomAssignment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	standard := models.RoomType{ID: 3, Name: "Standard Double", Rooms: []models.Room{
+		{ID: 11, RoomTypeID: 3, RoomName: "101"},
+		{ID: 12, RoomTypeID: 3, RoomName: "102"},
+	}}
+	mockDB.EXPECT().RoomTypes(gomock.Any()).Return([]models.RoomType{standard}, nil)
+	mockDB.EXPECT().EachReservation(gomock.Any(), models.ReservationFilter{
+		From: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 11, 14, 0, 0, 0, 0, time.UTC),
+	}, gomock.Any()).DoAndReturn(func(_ context.Context, _ models.ReservationFilter, fn func(models.Reservation) error) error {
+		return fn(models.Reservation{ID: 5, LastName: "Nguyen", RoomID: 12, RoomType: standard, Status: models.StatusConfirmed})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/admin/room-assignment?from=2026-11-01", nil)
+	req = req.WithContext(getCtx(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.AdminRoomAssignment).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `action="/admin/room-assignment/5"`)
+	assert.Contains(t, rr.Body.String(), `<option value="12" selected>102</option>`)
+
+	post := func(roomID string) (*httptest.ResponseRecorder, context.Context) {
+		body := url.Values{"room_id": {roomID}, "from": {"2026-11-01"}}
+		req := httptest.NewRequest(http.MethodPost, "/admin/room-assignment/5", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx := getCtx(req)
+		req = withURLParams(req.WithContext(ctx), map[string]string{"id": "5"})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(Repo.AdminPostRoomAssignment).ServeHTTP(rr, req)
+		return rr, ctx
+	}
+
+	start := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	res := models.Reservation{ID: 5, RoomID: 12, StartDate: start, EndDate: start.AddDate(0, 0, 2), RoomType: models.RoomType{ID: 3}}
+
+	// the same dates in another room of the type
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 5).Return(res, nil)
+	mockDB.EXPECT().GetRoomByID(gomock.Any(), 11).Return(models.Room{ID: 11, RoomTypeID: 3, RoomName: "101"}, nil)
+	mockDB.EXPECT().MoveReservation(gomock.Any(), 5, 11, res.StartDate, res.EndDate)
+	rr, _ = post("11")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/room-assignment?from=2026-11-01", rr.Header().Get("Location"))
+
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 5).Return(res, nil)
+	mockDB.EXPECT().GetRoomByID(gomock.Any(), 11).Return(models.Room{ID: 11, RoomTypeID: 3, RoomName: "101"}, nil)
+	mockDB.EXPECT().MoveReservation(gomock.Any(), 5, 11, res.StartDate, res.EndDate).Return(models.ErrConflict)
+	rr, ctx := post("11")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "Room 101 is already booked or blocked for these dates", session.GetString(ctx, "error"))
+
+	// a room of another type is refused
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 5).Return(res, nil)
+	mockDB.EXPECT().GetRoomByID(gomock.Any(), 1).Return(models.Room{ID: 1, RoomTypeID: 1, RoomName: "General's Quarters"}, nil)
+	rr, _ = post("1")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRepository_PostReservationPromoCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	generals := models.RoomType{ID: 1, Name: "General's Quarters", MaxOccupancy: 2, NightlyRate: 12000}
+	winter := models.PromoCode{ID: 5, Code: "WINTER10", Kind: models.DiscountPercent, Amount: 10, Active: true}
+	body := url.Values{"start_date": {"2050-01-01"}, "end_date": {"2050-01-03"}, "room_type_id": {"1"},
+		"first_name": {"Khanh"}, "last_name": {"Nguyen"}, "email": {"khanh@example.com"}, "phone": {"123456789"}}
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx := models.WithProperty(getCtx(req), models.Property{ID: 1, Currency: "EUR"})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(Repo.PostReservation).ServeHTTP(rr, req.WithContext(ctx))
+		return rr
+	}
+	with := func(extra url.Values) url.Values {
+		v := url.Values{}
+		for _, values := range []url.Values{body, extra} {
+			for key, value := range values {
+				v[key] = value
+			}
+		}
+		return v
+	}
+
+	// applying a code prices the stay again without booking it, before the guest details are filled in
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().GetPromoCode(gomock.Any(), "WINTER10").Return(winter, nil)
+	rr := post(url.Values{"start_date": {"2050-01-01"}, "end_date": {"2050-01-03"}, "room_type_id": {"1"},
+		"promo_code": {"winter10"}, "action": {"apply"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Promo code WINTER10, 10% off: -EUR 24.00")
+	assert.Contains(t, rr.Body.String(), "Total: EUR 216.00")
+	assert.NotContains(t, rr.Body.String(), "This field cannot be blank")
+
+	// the discount is recorded on the reservation
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().GetPromoCode(gomock.Any(), "WINTER10").Return(winter, nil)
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, res models.Reservation) (models.Reservation, error) {
+			assert.Equal(t, models.Money(24000), res.Subtotal)
+			assert.Equal(t, models.Money(2400), res.Discount)
+			assert.Equal(t, models.Money(21600), res.Total)
+			assert.Equal(t, 5, res.PromoCode.ID)
+			res.ID = 41
+			return res, nil
+		})
+	mockDB.EXPECT().QueueMail(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg models.MailData) (int, error) {
+			assert.Contains(t, msg.Content, "EUR 216.00 after 24.00 off with WINTER10")
+			return 1, nil
+		})
+	rr = post(with(url.Values{"promo_code": {"winter10"}}))
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+	// an unknown or expired code is shown on the form
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().GetPromoCode(gomock.Any(), "SUMMER").Return(models.PromoCode{}, models.ErrNotFound)
+	rr = post(with(url.Values{"promo_code": {"summer"}}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "This promo code does not exist")
+
+	expired := winter
+	expired.ValidUntil = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().GetPromoCode(gomock.Any(), "WINTER10").Return(expired, nil)
+	rr = post(with(url.Values{"promo_code": {"WINTER10"}}))
+	assert.Contains(t, rr.Body.String(), "This promo code has expired")
+
+	// the guest used the code already, found while booking
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().GetPromoCode(gomock.Any(), "WINTER10").Return(winter, nil)
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).Return(models.Reservation{}, models.ErrPromoUsedByGuest)
+	rr = post(with(url.Values{"promo_code": {"WINTER10"}}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "You have already used this promo code")
+	assert.Contains(t, rr.Body.String(), `value="khanh@example.com"`)
+}
+
+func TestRepository_AdminPostPromoCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/promo-codes", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(getCtx(req))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(Repo.AdminPostPromoCode).ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockDB.EXPECT().CreatePromoCode(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, c models.PromoCode) (int, error) {
+			assert.Equal(t, "SPRING", c.Code)
+			assert.Equal(t, models.DiscountFixed, c.Kind)
+			assert.Equal(t, 2050, c.Amount)
+			assert.Equal(t, 1, c.MaxUsesPerGuest)
+			assert.Equal(t, time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC), c.ValidUntil)
+			return 6, nil
+		})
+	rr := post(url.Values{"code": {"spring"}, "kind": {"fixed"}, "amount": {"20.50"}, "valid_until": {"2027-06-01"},
+		"max_uses_per_guest": {"1"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/promo-codes", rr.Header().Get("Location"))
+
+	// a percentage above 100, or a code the property has already, shows the form again
+	mockDB.EXPECT().PromoCodes(gomock.Any()).Return(nil, nil).Times(2)
+	mockDB.EXPECT().RoomTypes(gomock.Any()).Return(nil, nil).Times(2)
+	rr = post(url.Values{"code": {"half"}, "kind": {"percent"}, "amount": {"150"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Enter a number from 1 to 100")
+
+	mockDB.EXPECT().CreatePromoCode(gomock.Any(), gomock.Any()).Return(0, models.ErrConflict)
+	rr = post(url.Values{"code": {"spring"}, "kind": {"percent"}, "amount": {"10"}})
+	assert.Contains(t, rr.Body.String(), "There is a promo code SPRING already")
+}
+
+func TestRepository_PostReservationCancellationPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	generals := models.RoomType{ID: 1, Name: "General's Quarters", MaxOccupancy: 2, NightlyRate: 12000,
+		CancellationPolicyID: 2}
+	flexible := models.CancellationPolicy{ID: 2, Name: "Flexible", FreeDays: 7, PenaltyPercent: 50}
+	body := url.Values{"start_date": {"2050-01-01"}, "end_date": {"2050-01-03"}, "room_type_id": {"1"},
+		"first_name": {"Khanh"}, "last_name": {"Nguyen"}, "email": {"khanh@example.com"}, "phone": {"123456789"}}
+	req := httptest.NewRequest(http.MethodPost, "/make-reservation", strings.NewReader(body.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx := models.WithProperty(getCtx(req), models.Property{ID: 1, Currency: "EUR"})
+
+	// the reservation keeps the terms of the policy of its type when booked
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 1).Return(generals, nil)
+	mockDB.EXPECT().GetCancellationPolicy(gomock.Any(), 2).Return(flexible, nil)
+	mockDB.EXPECT().StayRules(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, res models.Reservation) (models.Reservation, error) {
+			assert.Equal(t, flexible, res.CancellationPolicy)
+			res.ID = 41
+			return res, nil
+		})
+	mockDB.EXPECT().QueueMail(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg models.MailData) (int, error) {
+			assert.Contains(t, msg.Content, "Cancellation: Free cancellation until Sat 25 Dec 2049, then 50% of the price is charged")
+			return 1, nil
+		})
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(Repo.PostReservation).ServeHTTP(rr, req.WithContext(ctx))
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+}
+
+func TestRepository_AdminPostCancellationPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/cancellation-policies", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(getCtx(req))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(Repo.AdminPostCancellationPolicy).ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockDB.EXPECT().CreateCancellationPolicy(gomock.Any(), models.CancellationPolicy{Name: "Flexible", FreeDays: 7,
+		PenaltyPercent: 50}).Return(2, nil)
+	rr := post(url.Values{"name": {" Flexible "}, "free_days": {"7"}, "penalty_percent": {"50"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/admin/cancellation-policies", rr.Header().Get("Location"))
+
+	// a non-refundable policy needs no penalty
+	mockDB.EXPECT().CreateCancellationPolicy(gomock.Any(), models.CancellationPolicy{Name: "Saver",
+		NonRefundable: true}).Return(3, nil)
+	rr = post(url.Values{"name": {"Saver"}, "non_refundable": {"1"}})
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+	// a penalty above 100%, or a name the property has already, shows the form again
+	mockDB.EXPECT().CancellationPolicies(gomock.Any()).Return(nil, nil).Times(2)
+	rr = post(url.Values{"name": {"Strict"}, "penalty_percent": {"150"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Enter a number from 0 to 100")
+
+	mockDB.EXPECT().CreateCancellationPolicy(gomock.Any(), gomock.Any()).Return(0, models.ErrConflict)
+	rr = post(url.Values{"name": {"Flexible"}, "penalty_percent": {"50"}})
+	assert.Contains(t, rr.Body.String(), "There is a policy of that name already")
+}
+
+func TestCancelledMessage(t *testing.T) {
+	p := models.Property{Currency: "EUR"}
+	assert.Equal(t, "Reservation cancelled", cancelledMessage(p, models.Cancellation{}))
+	assert.Equal(t, "Reservation cancelled, EUR 108.00 charged and EUR 108.00 to refund",
+		cancelledMessage(p, models.Cancellation{Penalty: 10800, Refund: 10800}))
+}
+
+func TestRepository_Room(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	Repo.DB = mockDB
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/rooms/"+id, nil)
+		ctx := models.WithProperty(getCtx(req), models.Property{ID: 1, Currency: "EUR"})
+		ctx = models.WithRoomTypes(ctx, []models.RoomType{{ID: 7, Name: "Garden Loft"}})
+		req = withURLParams(req.WithContext(ctx), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(Repo.Room).ServeHTTP(rr, req)
+		return rr
+	}
+
+	// the menu lists the types of the property, not the two suites the site started with
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 7).
+		Return(models.RoomType{ID: 7, Name: "Garden Loft", MaxOccupancy: 3, NightlyRate: 14000}, nil)
+	rr := get("7")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `href="/rooms/7">Garden Loft</a>`)
+	assert.Contains(t, rr.Body.String(), "From EUR 140.00 a night")
+	assert.NotContains(t, rr.Body.String(), "Major Suite")
+
+	// a type of another property is not found
+	mockDB.EXPECT().GetRoomTypeByID(gomock.Any(), 2).Return(models.RoomType{}, models.ErrNotFound)
+	rr = get("2")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	Repo.Generals(rr, httptest.NewRequest(http.MethodGet, "/generals-quarters", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/rooms/1", rr.Header().Get("Location"))
+}
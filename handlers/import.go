@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"booking/helpers"
+	"booking/importer"
+	"booking/models"
+	"booking/render"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// importMaxSize caps the size of an uploaded import file
+const importMaxSize = 5 << 20
+
+// AdminImportReservations shows the upload form for a CSV import
+func (re *Repository) AdminImportReservations(w http.ResponseWriter, r *http.Request) {
+	data := make(map[string]interface{})
+	data["columns"] = strings.Join(importer.Columns, ",")
+
+	render.RenderTemplate(w, r, "admin-import.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+}
+
+// AdminPostImportReservations checks an uploaded CSV of reservations and reports the accepted and rejected
+// rows. The accepted rows are only saved when the commit box is ticked, otherwise it is a dry run.
+func (re *Repository) AdminPostImportReservations(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, importMaxSize)
+	if err := r.ParseMultipartForm(importMaxSize); err != nil {
+		helpers.Error(w, r, models.NewValidationError("file", fmt.Sprintf("the file must be a CSV of at most %d MB", importMaxSize>>20)))
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("file", "choose a CSV file to import"))
+		return
+	}
+	defer file.Close()
+
+	rows, err := importer.Parse(file)
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("file", err.Error()))
+		return
+	}
+
+	report := models.ImportReport{Rows: rows, Committed: r.Form.Get("commit") == "1"}
+	err = re.DB.ImportReservations(r.Context(), report.Rows, report.Committed)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	if report.Committed {
+		re.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%d reservation(s) imported", report.Accepted()))
+	}
+
+	data := make(map[string]interface{})
+	data["columns"] = strings.Join(importer.Columns, ",")
+	data["report"] = report
+
+	render.RenderTemplate(w, r, "admin-import.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+}
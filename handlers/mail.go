@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"booking/forms"
+	"booking/helpers"
+	"booking/logging"
+	"booking/models"
+	"booking/render"
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// emailTemplatePath is where the HTML layouts of emails live, the mail listener reads them from there
+var emailTemplatePath = "./email-templates"
+
+// guestEmailMaxLength keeps composed emails to a reasonable size
+const guestEmailMaxLength = 10000
+
+// sendMail records msg in the outbox when it concerns a reservation, then hands it to the mail listener.
+// The email is still sent if it cannot be recorded, a missing outbox entry is better than a missing email.
+func (re *Repository) sendMail(ctx context.Context, msg models.MailData) {
+	msg.RequestID = middleware.GetReqID(ctx)
+	if msg.ReservationID > 0 {
+		id, err := re.DB.QueueMail(ctx, msg)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("cannot record email in the outbox")
+		}
+		msg.ID = id
+	}
+
+	re.App.MailChan <- msg
+}
+
+// confirmationMail is the email confirming a reservation to its guest, sent by property p
+func confirmationMail(p models.Property, res models.Reservation) models.MailData {
+	price := ""
+	if summary := res.PriceSummary(p.Currency); summary != "" {
+		price = fmt.Sprintf("The price of your stay is %s. <br>", html.EscapeString(summary))
+	}
+
+	htmlMsg := fmt.Sprintf(`
+		<strong>Reservation Confirmation</strong> <br>
+		Dear %s, <br>
+		This email confirms your reservation at %s from %s to %s for %s. <br>
+		%s
+		Cancellation: %s. <br>
+		Thank you for using our services! <br>
+	`, res.FirstName, html.EscapeString(p.Name), res.StartDate.Format("2006-01-02"), res.EndDate.Format("2006-01-02"),
+		res.GuestSummary(), price, html.EscapeString(res.CancellationPolicy.Terms(res.StartDate)))
+
+	return models.MailData{
+		ReservationID: res.ID,
+		To:            res.Email,
+		From:          p.ContactEmail,
+		Subject:       "Reservation Confirmation",
+		Content:       htmlMsg,
+		Template:      "basic.html",
+	}
+}
+
+// emailTemplates lists the layouts a composed email can be sent with
+func emailTemplates() []string {
+	paths, _ := filepath.Glob(filepath.Join(emailTemplatePath, "*.html"))
+
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+	return names
+}
+
+// showReservationURL is the page of the reservation, keeping where it was opened from
+func showReservationURL(src string, id int, v url.Values) string {
+	q := url.Values{}
+	for _, key := range []string{"y", "m", "date"} {
+		if v.Get(key) != "" {
+			q.Set(key, v.Get(key))
+		}
+	}
+
+	u := fmt.Sprintf("/admin/reservations/%s/%d/show", src, id)
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	return u
+}
+
+// AdminSendGuestEmail sends an email written by staff to the guest of a reservation
+func (re *Repository) AdminSendGuestEmail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid reservation id"))
+		return
+	}
+	src := chi.URLParam(r, "src")
+
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	f.Require("subject", "body")
+	if len(f.Get("body")) > guestEmailMaxLength {
+		f.Errors.Add("body", fmt.Sprintf("The email must be at most %d characters long", guestEmailMaxLength))
+	}
+
+	template := f.Get("template")
+	if template != "" {
+		known := false
+		for _, name := range emailTemplates() {
+			known = known || name == template
+		}
+		if !known {
+			f.Errors.Add("template", "Unknown email template")
+		}
+	}
+
+	if !f.Valid() {
+		re.showReservation(w, r, src, id, r.Form, f)
+		return
+	}
+
+	res, err := re.DB.GetReservationByID(r.Context(), id)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	// the body is plain text typed by staff
+	content := strings.ReplaceAll(html.EscapeString(strings.TrimSpace(f.Get("body"))), "\n", "<br>\n")
+
+	re.sendMail(r.Context(), models.MailData{
+		ReservationID: res.ID,
+		To:            res.Email,
+		From:          models.PropertyFromContext(r.Context()).ContactEmail,
+		Subject:       strings.TrimSpace(f.Get("subject")),
+		Content:       content,
+		Template:      template,
+	})
+
+	re.App.Session.Put(r.Context(), "flash", "Email sent to "+res.Email)
+	http.Redirect(w, r, showReservationURL(src, id, r.Form), http.StatusSeeOther)
+}
+
+// AdminShowOutboxMail shows an email of the outbox and whether it was delivered
+func (re *Repository) AdminShowOutboxMail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid email id"))
+		return
+	}
+
+	mail, err := re.DB.GetOutboxMail(r.Context(), id)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["mail"] = mail
+
+	render.RenderTemplate(w, r, "admin-outbox-mail.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/forms"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// quote prices a stay in room type t with the promo code typed on f, if any, on the cancellation policy of
+// the type. A code that cannot be redeemed is reported on the promo_code field of f and the stay priced
+// without it.
+func (re *Repository) quote(ctx context.Context, f *form.Form, t models.RoomType, stay dates.Range) (models.Quote, error) {
+	q, err := models.NewQuote(t, stay)
+	if err != nil {
+		return q, err
+	}
+	if t.CancellationPolicyID != 0 {
+		policy, err := re.DB.GetCancellationPolicy(ctx, t.CancellationPolicyID)
+		if err != nil {
+			return q, err
+		}
+		q.CancellationPolicy = policy
+	}
+
+	code := models.NormalizePromoCode(f.Get("promo_code"))
+	if code == "" {
+		return q, nil
+	}
+
+	promo, err := re.DB.GetPromoCode(ctx, code)
+	if errors.Is(err, models.ErrNotFound) {
+		f.Errors.Add("promo_code", "This promo code does not exist")
+		return q, nil
+	}
+	if err != nil {
+		return q, err
+	}
+
+	if err := promo.Check(t.ID, stay, dates.Of(re.today(ctx))); err != nil {
+		f.Errors.Add("promo_code", ruleMessage(err))
+		return q, nil
+	}
+	return q.WithPromo(promo), nil
+}
+
+// discountKinds are the kinds of discount offered on the promo codes form
+var discountKinds = []models.DiscountKind{models.DiscountPercent, models.DiscountFixed}
+
+// AdminPromoCodes lists the promo codes of the property with the form adding one
+func (re *Repository) AdminPromoCodes(w http.ResponseWriter, r *http.Request) {
+	re.promoCodesPage(w, r, form.New(nil))
+}
+
+// promoCodesPage renders the promo codes with the values and errors of the new code form f
+func (re *Repository) promoCodesPage(w http.ResponseWriter, r *http.Request, f *form.Form) {
+	codes, err := re.DB.PromoCodes(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	types, err := re.DB.RoomTypes(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["codes"] = codes
+	data["types"] = types
+	data["kinds"] = discountKinds
+
+	render.RenderTemplate(w, r, "admin-promo-codes.page.tmpl", &models.TemplateData{
+		Data: data,
+		Form: f,
+	})
+}
+
+// AdminPostPromoCode adds a promo code to the property
+func (re *Repository) AdminPostPromoCode(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	f.Require("code", "amount")
+	code := models.PromoCode{
+		Code:            models.NormalizePromoCode(f.Get("code")),
+		Kind:            models.DiscountKind(f.Get("kind")),
+		ValidFrom:       formDate(f, "valid_from"),
+		ValidUntil:      formDate(f, "valid_until"),
+		MinNights:       formNumber(f, "min_nights", 0, 365),
+		MaxUses:         formNumber(f, "max_uses", 0, 1000000),
+		MaxUsesPerGuest: formNumber(f, "max_uses_per_guest", 0, 1000),
+	}
+	code.RoomType.ID = formNumber(f, "room_type_id", 0, 1<<31-1)
+
+	switch code.Kind {
+	case models.DiscountPercent:
+		code.Amount = formNumber(f, "amount", 1, 100)
+	case models.DiscountFixed:
+		if f.Get("amount") != "" {
+			amount, err := models.ParseMoney(f.Get("amount"))
+			if err != nil || amount == 0 {
+				f.Errors.Add("amount", "Enter an amount such as 20.00")
+			}
+			code.Amount = int(amount)
+		}
+	default:
+		f.Errors.Add("kind", "Choose a kind of discount")
+	}
+
+	if len(code.Code) > 64 {
+		f.Errors.Add("code", "Use at most 64 characters")
+	}
+	if !code.ValidFrom.IsZero() && !code.ValidUntil.IsZero() && !code.ValidUntil.After(code.ValidFrom) {
+		f.Errors.Add("valid_until", "Until must be after from")
+	}
+	if f.Valid() && code.RoomType.ID != 0 {
+		// the room type must be one of the property
+		if _, err := re.DB.GetRoomTypeByID(r.Context(), code.RoomType.ID); err != nil {
+			f.Errors.Add("room_type_id", "Choose a room type")
+		}
+	}
+
+	if !f.Valid() {
+		re.promoCodesPage(w, r, f)
+		return
+	}
+
+	if _, err := re.DB.CreatePromoCode(r.Context(), code); err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			f.Errors.Add("code", "There is a promo code "+code.Code+" already")
+			re.promoCodesPage(w, r, f)
+			return
+		}
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Promo code "+code.Code+" added")
+	http.Redirect(w, r, "/admin/promo-codes", http.StatusSeeOther)
+}
+
+// AdminTogglePromoCode turns a promo code of the property on or off, guests can only redeem active codes
+func (re *Repository) AdminTogglePromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid promo code id"))
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	active := r.PostForm.Get("active") == "1"
+	if err := re.DB.SetPromoCodeActive(r.Context(), id, active); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	if active {
+		re.App.Session.Put(r.Context(), "flash", "Promo code turned on")
+	} else {
+		re.App.Session.Put(r.Context(), "flash", "Promo code turned off")
+	}
+	http.Redirect(w, r, "/admin/promo-codes", http.StatusSeeOther)
+}
+
+// AdminRates lists the room types of the property with the price of a night in each and the cancellation
+// policy they are booked on
+func (re *Repository) AdminRates(w http.ResponseWriter, r *http.Request) {
+	types, err := re.DB.RoomTypes(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	policies, err := re.DB.CancellationPolicies(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["types"] = types
+	data["policies"] = policies
+
+	render.RenderTemplate(w, r, "admin-rates.page.tmpl", &models.TemplateData{
+		Data: data,
+		Form: form.New(nil),
+	})
+}
+
+// AdminPostRate changes the nightly rate and cancellation policy of a room type of the property
+func (re *Repository) AdminPostRate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid room type id"))
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	rate, err := models.ParseMoney(r.PostForm.Get("nightly_rate"))
+	if err != nil {
+		re.App.Session.Put(r.Context(), "error", "Enter a rate such as 120.50")
+		http.Redirect(w, r, "/admin/rates", http.StatusSeeOther)
+		return
+	}
+
+	// no policy is free cancellation
+	policyID, _ := strconv.Atoi(r.PostForm.Get("cancellation_policy_id"))
+	if policyID != 0 {
+		// the policy must be one of the property
+		if _, err := re.DB.GetCancellationPolicy(r.Context(), policyID); err != nil {
+			helpers.Error(w, r, err)
+			return
+		}
+	}
+
+	if err := re.DB.SetRate(r.Context(), id, rate, policyID); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Rate saved")
+	http.Redirect(w, r, "/admin/rates", http.StatusSeeOther)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"booking/forms"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	currencyPattern   = regexp.MustCompile(`^[A-Z]{3}$`)
+	brandColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// PublicPropertyScope makes the public pages work on the property of the site, chosen when starting
+// the application
+func (re *Repository) PublicPropertyScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := re.DB.GetPropertyByID(r.Context(), re.App.PropertyID)
+		if err != nil {
+			helpers.Error(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(models.WithProperty(r.Context(), p)))
+	})
+}
+
+// RoomTypesMenu lists the room types of the property in the Rooms menu of the public pages
+func (re *Repository) RoomTypesMenu(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		types, err := re.DB.RoomTypes(r.Context())
+		if err != nil {
+			helpers.Error(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(models.WithRoomTypes(r.Context(), types)))
+	})
+}
+
+// AdminPropertyScope makes the admin pages work on the property the logged in user switched to, the
+// first one they manage until they switch
+func (re *Repository) AdminPropertyScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := re.App.Session.GetInt(r.Context(), "user_id")
+		if userID == 0 {
+			// nobody is logged in while the admin pages are open for development, show the site's property
+			re.PublicPropertyScope(next).ServeHTTP(w, r)
+			return
+		}
+
+		properties, err := re.DB.UserProperties(r.Context(), userID)
+		if err != nil {
+			helpers.Error(w, r, err)
+			return
+		}
+		if len(properties) == 0 {
+			helpers.Error(w, r, fmt.Errorf("%w: you do not manage any property", models.ErrUnauthorized))
+			return
+		}
+
+		current := properties[0]
+		chosen := re.App.Session.GetInt(r.Context(), "property_id")
+		for _, p := range properties {
+			if p.ID == chosen {
+				current = p
+			}
+		}
+
+		ctx := models.WithUserProperties(r.Context(), properties)
+		next.ServeHTTP(w, r.WithContext(models.WithProperty(ctx, current)))
+	})
+}
+
+// AdminSwitchProperty changes the property the admin pages work on, to one the user manages
+func (re *Repository) AdminSwitchProperty(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	id, _ := strconv.Atoi(r.Form.Get("property_id"))
+	for _, p := range models.UserPropertiesFromContext(r.Context()) {
+		if p.ID == id {
+			re.App.Session.Put(r.Context(), "property_id", id)
+			re.App.Session.Put(r.Context(), "flash", "Now managing "+p.Name)
+			http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+			return
+		}
+	}
+
+	helpers.Error(w, r, models.NewValidationError("property_id", "you do not manage this property"))
+}
+
+// AdminPropertySettings shows the settings of the current property
+func (re *Repository) AdminPropertySettings(w http.ResponseWriter, r *http.Request) {
+	p := models.PropertyFromContext(r.Context())
+	re.propertySettingsForm(w, r, form.New(url.Values{
+		"name":          {p.Name},
+		"contact_email": {p.ContactEmail},
+		"time_zone":     {p.TimeZone},
+		"currency":      {p.Currency},
+		"brand_color":   {p.BrandColor},
+		"logo_url":      {p.LogoURL},
+	}))
+}
+
+// propertySettingsForm renders the settings form with the values and errors of f
+func (re *Repository) propertySettingsForm(w http.ResponseWriter, r *http.Request, f *form.Form) {
+	render.RenderTemplate(w, r, "admin-property.page.tmpl", &models.TemplateData{
+		Form: f,
+	})
+}
+
+// AdminPostPropertySettings saves the settings of the current property
+func (re *Repository) AdminPostPropertySettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	f.Require("name", "contact_email", "time_zone", "currency")
+	f.IsEmail("contact_email")
+	if _, err := time.LoadLocation(f.Get("time_zone")); f.Get("time_zone") != "" && err != nil {
+		f.Errors.Add("time_zone", "Unknown time zone, use a name such as Europe/Paris")
+	}
+	if f.Get("currency") != "" && !currencyPattern.MatchString(f.Get("currency")) {
+		f.Errors.Add("currency", "Use a three letter code such as EUR")
+	}
+	if f.Get("brand_color") != "" && !brandColorPattern.MatchString(f.Get("brand_color")) {
+		f.Errors.Add("brand_color", "Use a color such as #336699")
+	}
+	logo := f.Get("logo_url")
+	if logo != "" && !strings.HasPrefix(logo, "/") && !strings.HasPrefix(logo, "https://") {
+		f.Errors.Add("logo_url", "Use an https:// address or a path such as /static/images/logo.png")
+	}
+
+	if !f.Valid() {
+		re.propertySettingsForm(w, r, f)
+		return
+	}
+
+	p := models.PropertyFromContext(r.Context())
+	p.Name = f.Get("name")
+	p.ContactEmail = f.Get("contact_email")
+	p.TimeZone = f.Get("time_zone")
+	p.Currency = f.Get("currency")
+	p.BrandColor = f.Get("brand_color")
+	p.LogoURL = logo
+
+	if err := re.DB.UpdateProperty(r.Context(), p); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Settings saved")
+	http.Redirect(w, r, "/admin/property", http.StatusSeeOther)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/export"
+	"booking/helpers"
+	"booking/logging"
+	"booking/models"
+	"booking/render"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// reservationFilterFromQuery reads the list filters, sort order and page from the query string
+func reservationFilterFromQuery(q url.Values) (models.ReservationFilter, error) {
+	var f models.ReservationFilter
+	var err error
+
+	if v := q.Get("status"); v != "" {
+		if f.Status, err = models.ParseReservationStatus(v); err != nil {
+			return f, err
+		}
+	}
+	if v := q.Get("room"); v != "" {
+		if f.RoomID, err = strconv.Atoi(v); err != nil {
+			return f, models.NewValidationError("room", "invalid room id")
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := dates.Parse(v)
+		if err != nil {
+			return f, models.NewValidationError("from", "invalid start date")
+		}
+		f.From = from.Time()
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := dates.Parse(v)
+		if err != nil {
+			return f, models.NewValidationError("to", "invalid end date")
+		}
+		f.To = to.Time()
+	}
+	if v := q.Get("sort"); v != "" {
+		valid := false
+		for _, key := range models.ReservationSortKeys {
+			valid = valid || key == v
+		}
+		if !valid {
+			return f, models.NewValidationError("sort", "invalid sort key")
+		}
+		f.Sort = v
+	}
+	if v := q.Get("page"); v != "" {
+		if f.Page, err = strconv.Atoi(v); err != nil || f.Page < 1 {
+			return f, models.NewValidationError("page", "invalid page")
+		}
+	}
+	if v := q.Get("per_page"); v != "" {
+		if f.PerPage, err = strconv.Atoi(v); err != nil || f.PerPage < 1 {
+			return f, models.NewValidationError("per_page", "invalid page size")
+		}
+	}
+
+	f.Search = strings.TrimSpace(q.Get("q"))
+	f.Desc = q.Get("dir") == "desc"
+
+	return f, nil
+}
+
+// withQuery returns the list URL with the current query string changed by set
+func withQuery(q url.Values, set map[string]string) string {
+	next := url.Values{}
+	for k, v := range q {
+		next[k] = v
+	}
+	for k, v := range set {
+		if v == "" {
+			next.Del(k)
+		} else {
+			next.Set(k, v)
+		}
+	}
+	return "/admin/reservations?" + next.Encode()
+}
+
+// AdminReservations lists reservations page by page with the filters and sort order given in the query string
+func (re *Repository) AdminReservations(w http.ResponseWriter, r *http.Request) {
+	filter, err := reservationFilterFromQuery(r.URL.Query())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	page, err := re.DB.Reservations(r.Context(), filter)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	rooms, err := re.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	src := string(filter.Status)
+	if src == "" {
+		src = "all"
+	}
+
+	stringMap := map[string]string{
+		"status": string(filter.Status),
+		"src":    src,
+		"q":      filter.Search,
+		"room":   q.Get("room"),
+		"from":   q.Get("from"),
+		"to":     q.Get("to"),
+		"sort":   filter.Sort,
+		"dir":    q.Get("dir"),
+		"prev":   withQuery(q, map[string]string{"page": strconv.Itoa(page.Page - 1)}),
+		"next":   withQuery(q, map[string]string{"page": strconv.Itoa(page.Page + 1)}),
+	}
+
+	// clicking a column header sorts by it, clicking it again flips the direction
+	sortLinks := make(map[string]string)
+	for _, key := range models.ReservationSortKeys {
+		dir := ""
+		if key == filter.Sort && !filter.Desc {
+			dir = "desc"
+		}
+		sortLinks[key] = withQuery(q, map[string]string{"sort": key, "dir": dir, "page": ""})
+	}
+
+	statusLinks := make(map[string]string)
+	statusLinks[""] = withQuery(q, map[string]string{"status": "", "page": ""})
+	for _, s := range models.ReservationStatuses {
+		statusLinks[string(s)] = withQuery(q, map[string]string{"status": string(s), "page": ""})
+	}
+
+	data := make(map[string]interface{})
+	data["page"] = page
+	data["rooms"] = rooms
+	data["statuses"] = models.ReservationStatuses
+	data["sort_links"] = sortLinks
+	data["status_links"] = statusLinks
+	data["export_columns"] = export.Columns()
+	defaultColumns := make(map[string]bool)
+	for _, key := range export.DefaultColumns {
+		defaultColumns[key] = true
+	}
+	data["default_columns"] = defaultColumns
+
+	render.RenderTemplate(w, r, "admin-reservations.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// AdminExportReservations streams every reservation matching the list filters as CSV or XLSX,
+// with the columns given in the query string
+func (re *Repository) AdminExportReservations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter, err := reservationFilterFromQuery(q)
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	format := export.CSV
+	if v := q.Get("format"); v != "" {
+		if format, err = export.ParseFormat(v); err != nil {
+			helpers.Error(w, r, err)
+			return
+		}
+	}
+
+	cols, err := export.ParseColumns(q["columns"])
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	name := format.FileName("reservations-" + re.today(r.Context()).Format(dateLayout))
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+
+	sheet, err := export.NewSheet(w, format, cols)
+	if err == nil {
+		err = re.DB.EachReservation(r.Context(), filter, sheet.Add)
+	}
+	if err == nil {
+		err = sheet.Close()
+	}
+	if err != nil {
+		// the file is partly sent already, all we can do is log and cut it short
+		logging.FromContext(r.Context()).WithError(err).Error("cannot export reservations")
+	}
+}
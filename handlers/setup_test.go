@@ -23,7 +23,13 @@ import (
 var app config.AppConfig
 var session *scs.Session
 var pathToTemplate = "./../templates"
-var functions = template.FuncMap{}
+var functions = template.FuncMap{
+	"humanDate":  render.HumanDate,
+	"formatDate": render.FormatDate,
+	"iterate":    render.Iterate,
+	"add":        render.Add,
+	"percent":    render.Percent,
+}
 
 func TestMain(m *testing.M) {
 	// What to put in the session
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"booking/dates"
+	"booking/forms"
+	"booking/helpers"
+	"booking/models"
+	"booking/render"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// now is the current time in the time zone of the property, the one the stay rules are read in
+func (re *Repository) now(ctx context.Context) time.Time {
+	if loc := re.location(ctx); loc != nil {
+		return time.Now().In(loc)
+	}
+	return time.Now().UTC()
+}
+
+// ruleMessage is what guests are told about a stay refused by the rules
+func ruleMessage(err error) string {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Message
+	}
+	return err.Error()
+}
+
+// weekdayOptions are the days offered on the stay rules form, monday first
+var weekdayOptions = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+	time.Saturday, time.Sunday}
+
+// AdminStayRules lists the stay rules of the property with the form adding one
+func (re *Repository) AdminStayRules(w http.ResponseWriter, r *http.Request) {
+	re.stayRulesPage(w, r, form.New(nil))
+}
+
+// stayRulesPage renders the stay rules with the values and errors of the new rule form f
+func (re *Repository) stayRulesPage(w http.ResponseWriter, r *http.Request, f *form.Form) {
+	rules, err := re.DB.StayRules(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+	types, err := re.DB.RoomTypes(r.Context())
+	if err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["rules"] = rules
+	data["types"] = types
+	data["weekdays"] = weekdayOptions
+	checked := make(map[string]bool)
+	for _, day := range f.Values["weekday"] {
+		checked[day] = true
+	}
+	data["checked_weekdays"] = checked
+
+	render.RenderTemplate(w, r, "admin-stay-rules.page.tmpl", &models.TemplateData{
+		Data: data,
+		Form: f,
+	})
+}
+
+// formNumber reads a whole number of field between min and max, empty is 0, and adds an error to f otherwise
+func formNumber(f *form.Form, field string, min, max int) int {
+	s := strings.TrimSpace(f.Get(field))
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		f.Errors.Add(field, fmt.Sprintf("Enter a number from %d to %d", min, max))
+	}
+	return n
+}
+
+// formDate reads an optional date of field, adding an error to f when it is not a date
+func formDate(f *form.Form, field string) time.Time {
+	if f.Get(field) == "" {
+		return time.Time{}
+	}
+	d, err := dates.Parse(f.Get(field))
+	if err != nil {
+		f.Errors.Add(field, "Invalid date, use YYYY-MM-DD")
+	}
+	return d.Time()
+}
+
+// AdminPostStayRule adds a stay rule to the property
+func (re *Repository) AdminPostStayRule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	f := form.New(r.PostForm)
+	rule := models.StayRule{
+		StartDate:         formDate(f, "start_date"),
+		EndDate:           formDate(f, "end_date"),
+		MinNights:         formNumber(f, "min_nights", 0, 365),
+		MaxNights:         formNumber(f, "max_nights", 0, 365),
+		ClosedToArrival:   f.Get("closed_to_arrival") == "1",
+		ClosedToDeparture: f.Get("closed_to_departure") == "1",
+		CutoffHour:        formNumber(f, "cutoff_hour", 0, 24),
+		MaxAdvanceDays:    formNumber(f, "max_advance_days", 0, 3650),
+	}
+	rule.RoomType.ID = formNumber(f, "room_type_id", 0, 1<<31-1)
+
+	for _, v := range f.Values["weekday"] {
+		day, err := strconv.Atoi(v)
+		if err != nil || day < 0 || day > 6 {
+			f.Errors.Add("weekday", "Unknown day")
+			continue
+		}
+		rule.Weekdays = rule.Weekdays.With(time.Weekday(day))
+	}
+
+	if !rule.StartDate.IsZero() && !rule.EndDate.IsZero() && !rule.EndDate.After(rule.StartDate) {
+		f.Errors.Add("end_date", "Until must be after from")
+	}
+	if rule.MinNights > 0 && rule.MaxNights > 0 && rule.MaxNights < rule.MinNights {
+		f.Errors.Add("max_nights", "The maximum cannot be below the minimum")
+	}
+	if f.Valid() && rule.Describe() == "" {
+		f.Errors.Add("min_nights", "Set at least one restriction")
+	}
+	if f.Valid() && rule.RoomType.ID != 0 {
+		// the room type must be one of the property
+		if _, err := re.DB.GetRoomTypeByID(r.Context(), rule.RoomType.ID); err != nil {
+			f.Errors.Add("room_type_id", "Choose a room type")
+		}
+	}
+
+	if !f.Valid() {
+		re.stayRulesPage(w, r, f)
+		return
+	}
+
+	if _, err := re.DB.CreateStayRule(r.Context(), rule); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Stay rule added")
+	http.Redirect(w, r, "/admin/stay-rules", http.StatusSeeOther)
+}
+
+// AdminDeleteStayRule removes a stay rule of the property
+func (re *Repository) AdminDeleteStayRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, r, models.NewValidationError("id", "invalid stay rule id"))
+		return
+	}
+
+	if err := re.DB.DeleteStayRule(r.Context(), id); err != nil {
+		helpers.Error(w, r, err)
+		return
+	}
+
+	re.App.Session.Put(r.Context(), "flash", "Stay rule deleted")
+	http.Redirect(w, r, "/admin/stay-rules", http.StatusSeeOther)
+}
@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"booking/logging"
+	"booking/models"
+	"booking/render"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// errorResponse is the body sent to JSON clients
+type errorResponse struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+	ErrorID string `json:"error_id"`
+}
+
+// Error logs err under a fresh error ID and writes the matching status code to the client,
+// as an HTML error page or as JSON for JSON routes. The error ID is shown to the user so that
+// a report can be matched with the log entry.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		logging.FromContext(r.Context()).Error("error is nil")
+		return
+	}
+
+	status, message := classify(err)
+	errorID := newErrorID()
+
+	log := logging.FromContext(r.Context()).WithError(err).WithFields(logrus.Fields{
+		"error_id": errorID,
+		"status":   status,
+	})
+	if status >= http.StatusInternalServerError {
+		log.WithField("stack", string(debug.Stack())).Error("server error")
+	} else {
+		log.Info("client error")
+	}
+
+	if wantsJSON(r) {
+		out, _ := json.MarshalIndent(errorResponse{Message: message, ErrorID: errorID}, "", "    ")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(out)
+		return
+	}
+
+	writeErrorPage(w, r, status, message, errorID)
+}
+
+// classify picks the status code and the message that is safe to show to the user
+func classify(err error) (int, string) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound, "The page or record you are looking for does not exist."
+	case errors.Is(err, models.ErrConflict):
+		return http.StatusConflict, "Your request conflicts with existing data, for example the room is no longer available for these dates."
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest, validationErr.Error()
+	case errors.Is(err, models.ErrValidation):
+		return http.StatusBadRequest, "The submitted data is not valid."
+	case errors.Is(err, models.ErrUnauthorized):
+		return http.StatusUnauthorized, "Please log in to continue."
+	case IsTimeout(err):
+		return http.StatusServiceUnavailable, "The service is busy right now, please try again in a moment."
+	default:
+		return http.StatusInternalServerError, "Something went wrong on our side."
+	}
+}
+
+func writeErrorPage(w http.ResponseWriter, r *http.Request, status int, message, errorID string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	err := render.RenderTemplate(w, r, "error.page.tmpl", &models.TemplateData{
+		StringMap: map[string]string{
+			"title":    http.StatusText(status),
+			"message":  message,
+			"error_id": errorID,
+		},
+		IntMap: map[string]int{
+			"status": status,
+		},
+	})
+	if err != nil {
+		// the status line is already sent, fall back to a plain body
+		w.Write([]byte(message + " Error ID: " + errorID))
+	}
+}
+
+// wantsJSON reports whether the client talks JSON rather than HTML
+func wantsJSON(r *http.Request) bool {
+	return strings.HasSuffix(r.URL.Path, "-json") ||
+		strings.HasPrefix(r.URL.Path, "/api/") ||
+		strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func newErrorID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -2,11 +2,12 @@ package helpers
 
 import (
 	"booking/config"
-	"fmt"
+	"booking/logging"
+	"context"
+	"errors"
 	"net/http"
-	"runtime/debug"
 
-	"github.com/sirupsen/logrus"
+	"github.com/jackc/pgx"
 )
 
 var app *config.AppConfig
@@ -15,19 +16,19 @@ func SetAppConfig(a *config.AppConfig) {
 	app = a
 }
 
-func ClientError(w http.ResponseWriter, status int) {
-	logrus.Infof("Client error code %v", status)
-	http.Error(w, http.StatusText(status), status)
+// ClientError answers with the given 4xx status using the same error page as Error
+func ClientError(w http.ResponseWriter, r *http.Request, status int) {
+	logging.FromContext(r.Context()).Infof("Client error code %v", status)
+	writeErrorPage(w, r, status, http.StatusText(status), "")
 }
 
-func ServerError(w http.ResponseWriter, err error) {
-	if err == nil {
-		logrus.Error("error is nil")
-		return
-	}
-	trace := fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
-	logrus.Error(trace)
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+// pgQueryCanceled is the postgres error code of a statement cancelled by the driver when its context expired
+const pgQueryCanceled = "57014"
+
+// IsTimeout reports whether err was caused by a context deadline, either directly or inside the postgres driver
+func IsTimeout(err error) bool {
+	var pgErr pgx.PgError
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled)
 }
 
 func IsAuthenticated(r *http.Request) bool {
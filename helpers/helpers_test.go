@@ -0,0 +1,22 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTimeout(t *testing.T) {
+	assert.True(t, IsTimeout(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+
+	// the driver cancels the statement on the server when the context expires
+	assert.True(t, IsTimeout(fmt.Errorf("query: %w", pgx.PgError{Code: "57014", Message: "canceling statement due to user request"})))
+
+	assert.False(t, IsTimeout(pgx.PgError{Code: "23505"}))
+	assert.False(t, IsTimeout(errors.New("connection refused")))
+	assert.False(t, IsTimeout(nil))
+}
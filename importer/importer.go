@@ -0,0 +1,131 @@
+// Package importer reads reservations from CSV files, e.g. when migrating from a spreadsheet
+package importer
+
+import (
+	"booking/dates"
+	"booking/forms"
+	"booking/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Columns are the CSV headers of an import file, in any order. status is optional and defaults
+// to confirmed, adults and children are optional and default to one adult, the other columns are required
+var Columns = []string{"first_name", "last_name", "email", "phone", "start_date", "end_date", "room_id", "status",
+	"adults", "children"}
+
+var requiredColumns = Columns[:7]
+
+// Parse reads the rows of a CSV import and checks each one with the rules of the booking form.
+// Rows with problems are returned with their errors, an error is only returned if the file cannot be read.
+func Parse(r io.Reader) ([]models.ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	// short rows are reported as missing fields rather than failing the whole file
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []models.ImportRow
+	// line numbers match the spreadsheet the file was saved from, the header being line 1
+	line := 1
+	for {
+		line++
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		values := url.Values{}
+		for _, name := range Columns {
+			if i, ok := index[name]; ok && i < len(record) {
+				values.Set(name, strings.TrimSpace(record[i]))
+			}
+		}
+
+		rows = append(rows, parseRow(line, values))
+	}
+
+	return rows, nil
+}
+
+// parseRow validates one line and builds its reservation
+func parseRow(line int, values url.Values) models.ImportRow {
+	row := models.ImportRow{Line: line}
+
+	f := form.New(values)
+	f.ReservationRules()
+
+	res := models.Reservation{
+		FirstName: values.Get("first_name"),
+		LastName:  values.Get("last_name"),
+		Email:     values.Get("email"),
+		Phone:     values.Get("phone"),
+		Status:    models.StatusConfirmed,
+	}
+
+	arrival, err := dates.Parse(values.Get("start_date"))
+	if err != nil {
+		f.Errors.Add("start_date", "Invalid date, use YYYY-MM-DD")
+	}
+	departure, err := dates.Parse(values.Get("end_date"))
+	if err != nil {
+		f.Errors.Add("end_date", "Invalid date, use YYYY-MM-DD")
+	}
+	res.StartDate, res.EndDate = arrival.Time(), departure.Time()
+	if f.Errors.Get("start_date") == "" && f.Errors.Get("end_date") == "" && !res.EndDate.After(res.StartDate) {
+		f.Errors.Add("end_date", "Departure must be after arrival")
+	}
+	if res.RoomID, err = strconv.Atoi(values.Get("room_id")); err != nil || res.RoomID < 1 {
+		f.Errors.Add("room_id", "Invalid room id")
+	}
+	if v := values.Get("status"); v != "" {
+		if res.Status, err = models.ParseReservationStatus(v); err != nil {
+			f.Errors.Add("status", "Unknown status")
+		}
+	}
+	adults, children, err := models.ParseGuests(values.Get("adults"), values.Get("children"))
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		f.Errors.Add(validationErr.Field, validationErr.Message)
+	}
+	res.Adults, res.Children = adults, children
+
+	row.Reservation = res
+
+	fields := make([]string, 0, len(f.Errors))
+	for field := range f.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		row.Errors = append(row.Errors, fmt.Sprintf("%s: %s", field, f.Errors.Get(field)))
+	}
+
+	return row
+}
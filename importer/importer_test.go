@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"booking/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	file := `room_id,first_name,last_name,email,phone,start_date,end_date,status
+1,Khanh,Nguyen,khanh@example.com,555-0100,2026-11-01,2026-11-03,
+2, Jo ,Smith,jo@example.com,555-0101,2026-11-01,2026-11-03,
+x,John,Doe,not-an-email,,2026-11-03,2026-11-01,processed
+1,Anna,Berg,anna@example.com,555-0102
+`
+	rows, err := Parse(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 4)
+
+	assert.True(t, rows[0].Accepted())
+	assert.Equal(t, 2, rows[0].Line)
+	assert.Equal(t, models.Reservation{
+		FirstName: "Khanh",
+		LastName:  "Nguyen",
+		Email:     "khanh@example.com",
+		Phone:     "555-0100",
+		StartDate: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC),
+		RoomID:    1,
+		Adults:    1,
+		Status:    models.StatusConfirmed,
+	}, rows[0].Reservation)
+
+	// same rules as the booking form
+	assert.Equal(t, []string{"first_name: This field must be at least 3 characters long"}, rows[1].Errors)
+
+	assert.Equal(t, []string{
+		"email: Invalid email address",
+		"end_date: Departure must be after arrival",
+		"phone: This field cannot be empty",
+		"room_id: Invalid room id",
+		"status: Unknown status",
+	}, rows[2].Errors)
+
+	// short rows are rejected, not the whole file
+	assert.Equal(t, 5, rows[3].Line)
+	assert.Contains(t, rows[3].Errors, "start_date: Invalid date, use YYYY-MM-DD")
+}
+
+func TestParse_Guests(t *testing.T) {
+	file := `room_id,first_name,last_name,email,phone,start_date,end_date,adults,children
+1,Khanh,Nguyen,khanh@example.com,555-0100,2026-11-01,2026-11-03,2,1
+1,Anna,Berg,anna@example.com,555-0102,2026-11-01,2026-11-03,0,
+1,John,Doe,john@example.com,555-0103,2026-11-01,2026-11-03,1,-2
+`
+	rows, err := Parse(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	assert.True(t, rows[0].Accepted())
+	assert.Equal(t, 2, rows[0].Reservation.Adults)
+	assert.Equal(t, 1, rows[0].Reservation.Children)
+	assert.Equal(t, []string{"adults: at least one adult must stay"}, rows[1].Errors)
+	assert.Equal(t, []string{"children: invalid number of children"}, rows[2].Errors)
+}
+
+func TestParse_Header(t *testing.T) {
+	_, err := Parse(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = Parse(strings.NewReader("first_name,last_name\nKhanh,Nguyen\n"))
+	assert.EqualError(t, err, `missing column "email"`)
+
+	rows, err := Parse(strings.NewReader(strings.Join(Columns, ",") + "\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+}
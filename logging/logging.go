@@ -0,0 +1,83 @@
+// Package logging provides request scoped loggers and PII redaction
+package logging
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ctxKey struct{}
+
+// RequestIDHeader is the header used to read and propagate request IDs
+const RequestIDHeader = "X-Request-Id"
+
+// Setup configures the standard logger for JSON output with PII redaction
+func Setup() {
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.AddHook(&RedactHook{})
+}
+
+// WithLogger returns a copy of ctx carrying the given logger
+func WithLogger(ctx context.Context, l *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx or the standard logger if there is none
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*logrus.Entry); ok {
+			return l
+		}
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// redactedFields are log fields holding guest contact details
+var redactedFields = map[string]func(string) string{
+	"email": RedactEmail,
+	"to":    RedactEmail,
+	"phone": RedactPhone,
+}
+
+// RedactHook masks guest emails and phone numbers before an entry is written
+type RedactHook struct{}
+
+func (h *RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *RedactHook) Fire(e *logrus.Entry) error {
+	for k, v := range e.Data {
+		redact, ok := redactedFields[k]
+		if !ok {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			e.Data[k] = redact(s)
+		}
+	}
+	e.Message = emailPattern.ReplaceAllStringFunc(e.Message, RedactEmail)
+	return nil
+}
+
+// RedactEmail keeps the first character of the local part and the domain
+func RedactEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// RedactPhone keeps the last three digits of a phone number
+func RedactPhone(phone string) string {
+	if len(phone) <= 3 {
+		return "***"
+	}
+	return "***" + phone[len(phone)-3:]
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	l := logrus.WithField("request_id", "abc")
+	ctx := WithLogger(context.Background(), l)
+	assert.Equal(t, l, FromContext(ctx))
+
+	// falls back to the standard logger
+	assert.NotNil(t, FromContext(context.Background()))
+}
+
+func TestRedactHook(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := logrus.New()
+	l.SetOutput(buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.AddHook(&RedactHook{})
+
+	l.WithFields(logrus.Fields{
+		"email":   "khanhnguyen@gmail.com",
+		"phone":   "123456789",
+		"room_id": 1,
+	}).Info("reservation for khanhnguyen@gmail.com")
+
+	var out map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "k***@gmail.com", out["email"])
+	assert.Equal(t, "***789", out["phone"])
+	assert.Equal(t, float64(1), out["room_id"])
+	assert.Equal(t, "reservation for k***@gmail.com", out["msg"])
+}
+
+func TestRedactEmail(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"abc@abc.com", "a***@abc.com"},
+		{"@abc.com", "***"},
+		{"not-an-email", "***"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.out, RedactEmail(test.in))
+	}
+}
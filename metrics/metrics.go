@@ -0,0 +1,122 @@
+// Package metrics holds the prometheus collectors exposed on /metrics
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "booking"
+
+var (
+	// HTTPRequests counts handled requests per chi route pattern
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latency per chi route pattern
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueryDuration observes the latency of each DatabaseRepo method
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Database query latency by repository method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 3},
+	}, []string{"method"})
+
+	// DBQueryErrors counts failed DatabaseRepo calls
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Number of failed database queries by repository method.",
+	}, []string{"method"})
+
+	// MailSent counts emails handed over to the SMTP server
+	MailSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mail",
+		Name:      "sent_total",
+		Help:      "Number of emails sent successfully.",
+	})
+
+	// MailFailures counts emails that could not be sent
+	MailFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mail",
+		Name:      "send_failures_total",
+		Help:      "Number of emails that failed to send.",
+	})
+
+	// ReservationsCreated counts reservations inserted into the database
+	ReservationsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_created_total",
+		Help:      "Number of reservations created.",
+	})
+
+	// ReservationsCancelled counts reservations removed by the admin
+	ReservationsCancelled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_cancelled_total",
+		Help:      "Number of reservations cancelled.",
+	})
+
+	// BlocksCreated counts owner blocks added from the calendar
+	BlocksCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "room_blocks_created_total",
+		Help:      "Number of owner blocks added to rooms.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		HTTPRequests,
+		HTTPDuration,
+		DBQueryDuration,
+		DBQueryErrors,
+		MailSent,
+		MailFailures,
+		ReservationsCreated,
+		ReservationsCancelled,
+		BlocksCreated,
+	)
+}
+
+// RegisterDBStats exposes the connection pool statistics of db
+func RegisterDBStats(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, "booking"))
+}
+
+// RegisterMailQueue exposes the number of emails waiting to be sent
+func RegisterMailQueue(depth func() int) error {
+	return prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "mail",
+		Name:      "queue_depth",
+		Help:      "Number of emails waiting in the mail channel.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// Handler returns the http handler serving the metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -1,3 +0,0 @@
-INSERT INTO public.rooms (room_name,created_at,updated_at) VALUES
-	 ('General''s Quarters','2022-01-02 00:00:00.000','2022-01-02 00:00:00.000'),
-	 ('Major Suite','2022-01-01 00:00:00.000','2022-01-01 00:00:00.000');
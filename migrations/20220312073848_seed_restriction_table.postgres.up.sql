@@ -1,4 +0,0 @@
-INSERT INTO "public"."restrictions"("id","restriction_name","created_at","updated_at")
-VALUES
-(1,E'Reservation',E'2022-01-01 00:00:00',E'2022-01-01 00:00:00'),
-(2,E'Owner Block',E'2022-03-08 00:00:00',E'2022-03-08 00:00:00');
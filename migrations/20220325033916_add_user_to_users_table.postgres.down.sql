@@ -1 +0,0 @@
-delete from users where email = 'admin@admin.com'
@@ -1,3 +0,0 @@
-INSERT INTO "public"."users"("first_name","last_name","email","password","access_level","created_at","updated_at")
-VALUES
-(E'Khanh',E'Nguyen',E'admin@admin.com',E'$2a$12$K31I59B2VpTqpmSxwYI9HO.h.9u5nN6XfugjRogZYDi7mohumgOc2',3,E'2022-01-01 00:00:00',E'2022-01-01 00:00:00');
@@ -0,0 +1,295 @@
+// Package migrations applies the SQL migrations embedded in the binary
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed schema/*.sql seed/*.sql
+var files embed.FS
+
+// Set is a group of migrations tracked in its own table
+type Set struct {
+	Name  string
+	Dir   string
+	Table string
+}
+
+var (
+	// Schema holds the tables, indexes and reference data the application needs to run
+	Schema = Set{Name: "schema", Dir: "schema", Table: "schema_migrations"}
+	// Seed holds demo data such as rooms and the admin user, it is never needed in production
+	Seed = Set{Name: "seed", Dir: "seed", Table: "seed_migrations"}
+)
+
+// Migration is a single version with its up and down scripts
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration has been applied
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations of set from the embedded files
+func Load(set Set) ([]Migration, error) {
+	return load(files, set.Dir)
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		parts := fileName.FindStringSubmatch(e.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		} else if m.Name != parts[2] {
+			return nil, fmt.Errorf("version %d is used by %q and %q", version, m.Name, parts[2])
+		}
+
+		if parts[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies one set of migrations to a database
+type Migrator struct {
+	DB         *sql.DB
+	Table      string
+	Migrations []Migration
+	// DryRun prints the scripts instead of executing them
+	DryRun bool
+	// Out receives progress messages and dry run output
+	Out io.Writer
+}
+
+// New returns a migrator for the embedded migrations of set
+func New(db *sql.DB, set Set, out io.Writer) (*Migrator, error) {
+	migrations, err := Load(set)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:         db,
+		Table:      set.Table,
+		Migrations: migrations,
+		Out:        out,
+	}, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	query := fmt.Sprintf(`create table if not exists %s (
+		version bigint primary key,
+		name varchar(255) not null,
+		applied_at timestamp not null
+	)`, m.Table)
+
+	_, err := m.DB.ExecContext(ctx, query)
+	return err
+}
+
+// tableExists tells whether the table tracking the migrations has been created
+func (m *Migrator) tableExists(ctx context.Context) (bool, error) {
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, "select to_regclass($1) is not null", m.Table).Scan(&exists)
+	return exists, err
+}
+
+// applied returns when each applied migration was applied. A dry run leaves the database untouched, a
+// missing table then means that nothing has been applied yet.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if m.DryRun {
+		exists, err := m.tableExists(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return map[int64]time.Time{}, nil
+		}
+	} else if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.QueryContext(ctx, fmt.Sprintf("select version, applied_at from %s", m.Table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+
+	return applied, rows.Err()
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, mg := range m.Migrations {
+		at, ok := applied[mg.Version]
+		statuses = append(statuses, Status{Migration: mg, Applied: ok, AppliedAt: at})
+	}
+
+	return statuses, nil
+}
+
+// Up applies pending migrations in version order, at most steps of them when steps > 0
+func (m *Migrator) Up(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mg := range m.Migrations {
+		if steps > 0 && count == steps {
+			break
+		}
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+
+		insert := fmt.Sprintf("insert into %s (version, name, applied_at) values ($1, $2, $3)", m.Table)
+		err := m.exec(ctx, "up", mg, mg.Up, insert, mg.Version, mg.Name, time.Now())
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down rolls back the latest applied migrations, steps of them (at least one)
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps < 1 {
+		steps = 1
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.Migrations) - 1; i >= 0 && count < steps; i-- {
+		mg := m.Migrations[i]
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+		if mg.Down == "" {
+			return count, fmt.Errorf("migration %d_%s cannot be rolled back, it has no down script", mg.Version, mg.Name)
+		}
+
+		remove := fmt.Sprintf("delete from %s where version = $1", m.Table)
+		err := m.exec(ctx, "down", mg, mg.Down, remove, mg.Version)
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// exec runs script and the bookkeeping statement in a single transaction
+func (m *Migrator) exec(ctx context.Context, direction string, mg Migration, script, record string, args ...interface{}) error {
+	if m.DryRun {
+		fmt.Fprintf(m.Out, "-- %s %d_%s (dry run)\n%s\n", direction, mg.Version, mg.Name, script)
+		return nil
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s %d_%s: %w", direction, mg.Version, mg.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording %d_%s: %w", mg.Version, mg.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(m.Out, "%s %d_%s\n", direction, mg.Version, mg.Name)
+	return nil
+}
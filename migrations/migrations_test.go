@@ -0,0 +1,177 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/jackc/pgx/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	for _, set := range []Set{Schema, Seed} {
+		migrations, err := Load(set)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+
+		for i, m := range migrations {
+			assert.NotEmpty(t, m.Up, "%d_%s", m.Version, m.Name)
+			assert.NotEmpty(t, m.Down, "%d_%s", m.Version, m.Name)
+			if i > 0 {
+				assert.Less(t, migrations[i-1].Version, m.Version)
+			}
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"bad name", fstest.MapFS{"m/create_rooms.up.sql": {Data: []byte("select 1")}}},
+		{"missing up", fstest.MapFS{"m/1_rooms.down.sql": {Data: []byte("select 1")}}},
+		{"version reused", fstest.MapFS{
+			"m/1_rooms.up.sql": {Data: []byte("select 1")},
+			"m/1_users.up.sql": {Data: []byte("select 1")},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := load(test.files, "m")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock, *bytes.Buffer) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	out := new(bytes.Buffer)
+	m := &Migrator{
+		DB:    db,
+		Table: "schema_migrations",
+		Migrations: []Migration{
+			{Version: 1, Name: "rooms", Up: "create table rooms ()", Down: "drop table rooms"},
+			{Version: 2, Name: "users", Up: "create table users ()", Down: "drop table users"},
+		},
+		Out: out,
+	}
+
+	return m, mock, out
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, mock, _ := testMigrator(t)
+
+	mock.ExpectExec("create table if not exists schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("select version, applied_at from schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("create table users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("insert into schema_migrations").WithArgs(2, "users", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := m.Up(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_UpRollsBackFailure(t *testing.T) {
+	m, mock, _ := testMigrator(t)
+
+	mock.ExpectExec("create table if not exists schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("select version, applied_at from schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("create table rooms").WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	n, err := m.Up(context.Background(), 0)
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	m, mock, _ := testMigrator(t)
+
+	mock.ExpectExec("create table if not exists schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("select version, applied_at from schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("drop table users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("delete from schema_migrations").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := m.Down(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	m, mock, out := testMigrator(t)
+	m.DryRun = true
+
+	// the tracking table is not created, without it nothing has been applied
+	mock.ExpectQuery(`select to_regclass\(\$1\) is not null`).WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	n, err := m.Up(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Contains(t, out.String(), "create table rooms ()")
+	assert.Contains(t, out.String(), "create table users ()")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	out.Reset()
+	mock.ExpectQuery(`select to_regclass\(\$1\) is not null`).WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("select version, applied_at from schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+
+	n, err = m.Up(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotContains(t, out.String(), "create table rooms ()")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestEmbedded runs every embedded migration up and down against a throwaway postgres database,
+// e.g. BOOKING_TEST_DSN="host=localhost dbname=booking_test user=postgres password=postgres"
+func TestEmbedded(t *testing.T) {
+	dsn := os.Getenv("BOOKING_TEST_DSN")
+	if dsn == "" {
+		t.Skip("BOOKING_TEST_DSN not set, skipping test against a live database")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, set := range []Set{Schema, Seed} {
+		m, err := New(db, set, new(bytes.Buffer))
+		assert.NoError(t, err)
+		_, err = m.Up(ctx, 0)
+		assert.NoError(t, err)
+	}
+
+	for _, set := range []Set{Seed, Schema} {
+		m, err := New(db, set, new(bytes.Buffer))
+		assert.NoError(t, err)
+		_, err = m.Down(ctx, len(m.Migrations))
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,60 @@
+-- Consolidates the former soda migrations. Every statement is guarded so that databases
+-- created with soda can adopt the built-in runner without changes.
+create table if not exists users (
+    id serial primary key,
+    first_name varchar(255) not null default '',
+    last_name varchar(255) not null default '',
+    email varchar(255) not null,
+    password varchar(60) not null,
+    access_level integer not null default 1,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create unique index if not exists users_email_idx on users (email);
+
+create table if not exists rooms (
+    id serial primary key,
+    room_name varchar(255) not null default '',
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create table if not exists restrictions (
+    id serial primary key,
+    restriction_name varchar(255) not null default '',
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create table if not exists reservations (
+    id serial primary key,
+    first_name varchar(255) not null default '',
+    last_name varchar(255) not null default '',
+    email varchar(255) not null,
+    phone varchar(255) not null default '',
+    start_date date not null,
+    end_date date not null,
+    room_id integer not null references rooms (id) on delete cascade on update cascade,
+    processed integer not null default 0,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create index if not exists reservations_email_idx on reservations (email);
+create index if not exists reservations_last_name_idx on reservations (last_name);
+
+create table if not exists room_restrictions (
+    id serial primary key,
+    start_date date not null,
+    end_date date not null,
+    room_id integer not null references rooms (id) on delete cascade on update cascade,
+    reservation_id integer null references reservations (id) on delete cascade on update cascade,
+    restriction_id integer not null references restrictions (id) on delete cascade on update cascade,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create index if not exists room_restrictions_start_date_end_date_idx on room_restrictions (start_date, end_date);
+create index if not exists room_restrictions_room_id_idx on room_restrictions (room_id);
+create index if not exists room_restrictions_reservation_id_idx on room_restrictions (reservation_id);
@@ -0,0 +1,8 @@
+-- restriction ids are referenced by the application: 1 is a reservation, 2 an owner block
+insert into restrictions (id, restriction_name, created_at, updated_at)
+values
+    (1, 'Reservation', '2022-01-01 00:00:00', '2022-01-01 00:00:00'),
+    (2, 'Owner Block', '2022-03-08 00:00:00', '2022-03-08 00:00:00')
+on conflict (id) do nothing;
+
+select setval(pg_get_serial_sequence('restrictions', 'id'), (select max(id) from restrictions));
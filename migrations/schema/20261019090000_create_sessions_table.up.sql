@@ -0,0 +1,8 @@
+-- session data for the postgres session store, see app/web/session.go
+create table if not exists sessions (
+    token text primary key,
+    data bytea not null,
+    expiry timestamptz not null
+);
+
+create index if not exists sessions_expiry_idx on sessions (expiry);
@@ -0,0 +1,26 @@
+create table if not exists audit_log (
+    id bigserial primary key,
+    -- no foreign key: the trail must outlive the users and records it mentions
+    user_id integer null,
+    action varchar(64) not null,
+    entity_type varchar(64) not null,
+    entity_id integer not null,
+    before_data jsonb null,
+    after_data jsonb null,
+    created_at timestamp not null
+);
+
+create index if not exists audit_log_created_at_idx on audit_log (created_at);
+create index if not exists audit_log_entity_idx on audit_log (entity_type, entity_id);
+create index if not exists audit_log_user_id_idx on audit_log (user_id);
+
+-- the audit trail is append-only
+create or replace function audit_log_append_only() returns trigger as $$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+drop trigger if exists audit_log_append_only on audit_log;
+create trigger audit_log_append_only before update or delete on audit_log
+    for each row execute procedure audit_log_append_only();
@@ -0,0 +1,5 @@
+drop index if exists reservations_cancelled_at_idx;
+
+alter table reservations
+    drop column if exists cancelled_at,
+    drop column if exists cancel_reason;
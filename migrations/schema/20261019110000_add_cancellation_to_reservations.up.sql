@@ -0,0 +1,5 @@
+alter table reservations
+    add column if not exists cancelled_at timestamp null,
+    add column if not exists cancel_reason text not null default '';
+
+create index if not exists reservations_cancelled_at_idx on reservations (cancelled_at);
@@ -0,0 +1,13 @@
+alter table reservations add column if not exists processed integer not null default 0;
+
+update reservations set processed = 1 where status not in ('pending', 'cancelled');
+
+drop index if exists reservations_status_idx;
+
+alter table reservations
+    drop constraint if exists reservations_status_check,
+    drop column if exists status,
+    drop column if exists confirmed_at,
+    drop column if exists checked_in_at,
+    drop column if exists checked_out_at,
+    drop column if exists no_show_at;
@@ -0,0 +1,18 @@
+alter table reservations
+    add column if not exists status varchar(20) not null default 'pending',
+    add column if not exists confirmed_at timestamp null,
+    add column if not exists checked_in_at timestamp null,
+    add column if not exists checked_out_at timestamp null,
+    add column if not exists no_show_at timestamp null;
+
+-- processed reservations were acknowledged by an admin, the closest status is confirmed
+update reservations set status = 'confirmed', confirmed_at = updated_at where processed = 1;
+update reservations set status = 'cancelled' where cancelled_at is not null;
+
+alter table reservations
+    add constraint reservations_status_check
+    check (status in ('pending', 'confirmed', 'checked_in', 'checked_out', 'cancelled', 'no_show'));
+
+alter table reservations drop column if exists processed;
+
+create index if not exists reservations_status_idx on reservations (status);
@@ -0,0 +1,10 @@
+create table if not exists reservation_notes (
+    id serial primary key,
+    reservation_id integer not null references reservations (id) on delete cascade on update cascade,
+    -- notes are kept when the user who wrote them is removed
+    user_id integer null references users (id) on delete set null,
+    body text not null,
+    created_at timestamp not null
+);
+
+create index if not exists reservation_notes_reservation_id_idx on reservation_notes (reservation_id, created_at);
@@ -0,0 +1,15 @@
+create table if not exists mail_outbox (
+    id serial primary key,
+    reservation_id integer null references reservations (id) on delete cascade on update cascade,
+    recipient varchar(255) not null,
+    sender varchar(255) not null,
+    subject varchar(255) not null,
+    content text not null,
+    template varchar(255) not null default '',
+    status varchar(16) not null default 'queued' check (status in ('queued', 'sent', 'failed')),
+    error text not null default '',
+    created_at timestamp not null,
+    sent_at timestamp null
+);
+
+create index if not exists mail_outbox_reservation_id_idx on mail_outbox (reservation_id, created_at);
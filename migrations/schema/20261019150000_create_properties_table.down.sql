@@ -0,0 +1,9 @@
+drop index if exists audit_log_property_id_idx;
+alter table audit_log drop column if exists property_id;
+
+drop table if exists user_properties;
+
+drop index if exists rooms_property_id_idx;
+alter table rooms drop column if exists property_id;
+
+drop table if exists properties;
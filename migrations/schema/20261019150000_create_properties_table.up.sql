@@ -0,0 +1,43 @@
+create table if not exists properties (
+    id serial primary key,
+    name varchar(255) not null,
+    contact_email varchar(255) not null,
+    time_zone varchar(64) not null default 'UTC',
+    currency char(3) not null default 'EUR',
+    brand_color varchar(7) not null default '',
+    logo_url varchar(255) not null default '',
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+-- the guesthouse that owned everything so far
+insert into properties (id, name, contact_email, created_at, updated_at)
+values (1, 'My Guesthouse', 'me@email.com', now(), now())
+on conflict (id) do nothing;
+
+select setval('properties_id_seq', (select max(id) from properties));
+
+-- rooms created without a property, such as the demo rooms of the seed, belong to the first one
+alter table rooms
+    add column if not exists property_id integer not null default 1
+    references properties (id) on delete cascade on update cascade;
+
+create index if not exists rooms_property_id_idx on rooms (property_id);
+
+create table if not exists user_properties (
+    user_id integer not null references users (id) on delete cascade on update cascade,
+    property_id integer not null references properties (id) on delete cascade on update cascade,
+    primary key (user_id, property_id)
+);
+
+insert into user_properties (user_id, property_id)
+select id, 1 from users
+on conflict do nothing;
+
+-- changes are listed in the audit log of the property they were made in, null for background jobs
+alter table audit_log
+    add column if not exists property_id integer null references properties (id) on delete set null;
+
+update audit_log set property_id = 1;
+
+create index if not exists audit_log_property_id_idx on audit_log (property_id, created_at);
@@ -0,0 +1,7 @@
+drop trigger if exists rooms_default_room_type on rooms;
+drop function if exists rooms_default_room_type();
+
+drop index if exists rooms_room_type_id_idx;
+alter table rooms drop column if exists room_type_id;
+
+drop table if exists room_types;
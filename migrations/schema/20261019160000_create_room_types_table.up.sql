@@ -0,0 +1,44 @@
+create table if not exists room_types (
+    id serial primary key,
+    property_id integer not null references properties (id) on delete cascade on update cascade,
+    name varchar(255) not null,
+    description text not null default '',
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create index if not exists room_types_property_id_idx on room_types (property_id);
+
+-- every room so far was booked on its own, it becomes a type of its own with the same id so that links
+-- to the room pages keep working
+insert into room_types (id, property_id, name, created_at, updated_at)
+select id, property_id, room_name, created_at, updated_at from rooms
+on conflict (id) do nothing;
+
+select setval('room_types_id_seq', coalesce((select max(id) from room_types), 0) + 1, false);
+
+alter table rooms
+    add column if not exists room_type_id integer null
+    references room_types (id) on delete restrict on update cascade;
+
+update rooms set room_type_id = id where room_type_id is null;
+
+alter table rooms alter column room_type_id set not null;
+
+create index if not exists rooms_room_type_id_idx on rooms (room_type_id);
+
+-- rooms inserted without a type, such as the demo rooms of the seed, get a type of their own
+create or replace function rooms_default_room_type() returns trigger as $$
+begin
+    if new.room_type_id is null then
+        insert into room_types (property_id, name, created_at, updated_at)
+        values (new.property_id, new.room_name, now(), now())
+        returning id into new.room_type_id;
+    end if;
+    return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists rooms_default_room_type on rooms;
+create trigger rooms_default_room_type before insert on rooms
+    for each row execute procedure rooms_default_room_type();
@@ -0,0 +1,7 @@
+alter table room_types drop constraint if exists room_types_max_occupancy_check;
+alter table room_types drop column if exists max_occupancy;
+
+alter table reservations drop constraint if exists reservations_guests_check;
+alter table reservations
+    drop column if exists children,
+    drop column if exists adults;
@@ -0,0 +1,14 @@
+-- reservations made so far did not record their guests, count them as one adult
+alter table reservations
+    add column if not exists adults integer not null default 1,
+    add column if not exists children integer not null default 0;
+
+alter table reservations
+    add constraint reservations_guests_check check (adults >= 1 and children >= 0);
+
+-- the most guests a room of the type sleeps, adults and children alike
+alter table room_types
+    add column if not exists max_occupancy integer not null default 2;
+
+alter table room_types
+    add constraint room_types_max_occupancy_check check (max_occupancy >= 1);
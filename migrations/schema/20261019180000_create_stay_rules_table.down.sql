@@ -0,0 +1 @@
+drop table if exists stay_rules;
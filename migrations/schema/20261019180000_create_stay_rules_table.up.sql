@@ -0,0 +1,26 @@
+-- a rule restricts the stays guests may book, for one room type or every type of the property, arriving
+-- or leaving on the dates from start_date up to but not including end_date (every date when null) that fall
+-- on the days of weekdays (a bit per day from sunday, every day when 0). Zero limits do not apply.
+create table if not exists stay_rules (
+    id serial primary key,
+    property_id integer not null references properties (id) on delete cascade on update cascade,
+    room_type_id integer null references room_types (id) on delete cascade on update cascade,
+    start_date date null,
+    end_date date null,
+    weekdays smallint not null default 0,
+    min_nights integer not null default 0,
+    max_nights integer not null default 0,
+    closed_to_arrival boolean not null default false,
+    closed_to_departure boolean not null default false,
+    cutoff_hour integer not null default 0,
+    max_advance_days integer not null default 0,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    constraint stay_rules_dates_check check (start_date is null or end_date is null or end_date > start_date),
+    constraint stay_rules_weekdays_check check (weekdays between 0 and 127),
+    constraint stay_rules_nights_check check (min_nights >= 0 and max_nights >= 0),
+    constraint stay_rules_cutoff_hour_check check (cutoff_hour between 0 and 24),
+    constraint stay_rules_max_advance_days_check check (max_advance_days >= 0)
+);
+
+create index if not exists stay_rules_property_id_idx on stay_rules (property_id);
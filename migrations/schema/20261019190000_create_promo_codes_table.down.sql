@@ -0,0 +1,15 @@
+drop index if exists reservations_promo_code_id_idx;
+
+alter table reservations
+    drop constraint if exists reservations_amounts_check,
+    drop column if exists promo_code,
+    drop column if exists promo_code_id,
+    drop column if exists total,
+    drop column if exists discount,
+    drop column if exists subtotal;
+
+drop table if exists promo_codes;
+
+alter table room_types
+    drop constraint if exists room_types_nightly_rate_check,
+    drop column if exists nightly_rate;
@@ -0,0 +1,44 @@
+-- prices are in the minor unit of the currency of the property, e.g. cents
+alter table room_types
+    add column if not exists nightly_rate integer not null default 0;
+
+alter table room_types
+    add constraint room_types_nightly_rate_check check (nightly_rate >= 0);
+
+-- a promo code can be redeemed on the days from valid_from up to but not including valid_until, for stays
+-- of room_type_id (every type when null) of at least min_nights. Zero limits do not apply.
+create table if not exists promo_codes (
+    id serial primary key,
+    property_id integer not null references properties (id) on delete cascade on update cascade,
+    code varchar(64) not null,
+    kind varchar(16) not null,
+    amount integer not null,
+    valid_from date null,
+    valid_until date null,
+    room_type_id integer null references room_types (id) on delete cascade on update cascade,
+    min_nights integer not null default 0,
+    max_uses integer not null default 0,
+    max_uses_per_guest integer not null default 0,
+    active boolean not null default true,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    constraint promo_codes_code_key unique (property_id, code),
+    constraint promo_codes_kind_check check (kind in ('percent', 'fixed')),
+    constraint promo_codes_amount_check check (amount > 0 and (kind <> 'percent' or amount <= 100)),
+    constraint promo_codes_dates_check check (valid_from is null or valid_until is null or valid_until > valid_from),
+    constraint promo_codes_limits_check check (min_nights >= 0 and max_uses >= 0 and max_uses_per_guest >= 0)
+);
+
+-- reservations made so far had no price. The code is kept as it was typed for the reports, the id counts
+-- the uses of codes still defined.
+alter table reservations
+    add column if not exists subtotal integer not null default 0,
+    add column if not exists discount integer not null default 0,
+    add column if not exists total integer not null default 0,
+    add column if not exists promo_code_id integer null references promo_codes (id) on delete set null on update cascade,
+    add column if not exists promo_code varchar(64) not null default '';
+
+alter table reservations
+    add constraint reservations_amounts_check check (subtotal >= 0 and discount >= 0 and total >= 0);
+
+create index if not exists reservations_promo_code_id_idx on reservations (promo_code_id);
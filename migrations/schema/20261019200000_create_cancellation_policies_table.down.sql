@@ -0,0 +1,12 @@
+alter table reservations
+    drop column if exists refund,
+    drop column if exists penalty,
+    drop column if exists cancel_non_refundable,
+    drop column if exists cancel_penalty_percent,
+    drop column if exists cancel_free_days,
+    drop column if exists cancellation_policy;
+
+alter table room_types
+    drop column if exists cancellation_policy_id;
+
+drop table if exists cancellation_policies;
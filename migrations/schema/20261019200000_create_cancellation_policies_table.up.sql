@@ -0,0 +1,30 @@
+-- cancelling is free until free_days days before arrival, penalty_percent of the price is charged from then
+-- on, non-refundable stays are charged in full
+create table if not exists cancellation_policies (
+    id serial primary key,
+    property_id integer not null references properties (id) on delete cascade on update cascade,
+    name varchar(255) not null,
+    free_days integer not null default 0,
+    penalty_percent integer not null default 100,
+    non_refundable boolean not null default false,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    constraint cancellation_policies_name_key unique (property_id, name),
+    constraint cancellation_policies_free_days_check check (free_days >= 0),
+    constraint cancellation_policies_penalty_check check (penalty_percent between 0 and 100)
+);
+
+-- room types without a policy are booked with free cancellation
+alter table room_types
+    add column if not exists cancellation_policy_id integer null
+        references cancellation_policies (id) on delete set null on update cascade;
+
+-- reservations keep a copy of the terms they were booked on, changing a policy does not change them.
+-- penalty and refund are recorded when the reservation is cancelled.
+alter table reservations
+    add column if not exists cancellation_policy varchar(255) not null default '',
+    add column if not exists cancel_free_days integer not null default 0,
+    add column if not exists cancel_penalty_percent integer not null default 0,
+    add column if not exists cancel_non_refundable boolean not null default false,
+    add column if not exists penalty integer not null default 0,
+    add column if not exists refund integer not null default 0;
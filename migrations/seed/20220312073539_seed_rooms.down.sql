@@ -0,0 +1 @@
+delete from rooms where room_name in ('General''s Quarters', 'Major Suite');
@@ -0,0 +1,7 @@
+insert into rooms (room_name, created_at, updated_at)
+select v.room_name, v.created_at, v.updated_at
+from (values
+    ('General''s Quarters', timestamp '2022-01-02 00:00:00', timestamp '2022-01-02 00:00:00'),
+    ('Major Suite', timestamp '2022-01-01 00:00:00', timestamp '2022-01-01 00:00:00')
+) as v (room_name, created_at, updated_at)
+where not exists (select 1 from rooms r where r.room_name = v.room_name);
@@ -0,0 +1,4 @@
+-- demo administrator used to log into the admin pages
+insert into users (first_name, last_name, email, password, access_level, created_at, updated_at)
+values ('Khanh', 'Nguyen', 'admin@admin.com', '$2a$12$K31I59B2VpTqpmSxwYI9HO.h.9u5nN6XfugjRogZYDi7mohumgOc2', 3, '2022-01-01 00:00:00', '2022-01-01 00:00:00')
+on conflict (email) do nothing;
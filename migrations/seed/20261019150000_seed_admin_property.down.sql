@@ -0,0 +1 @@
+delete from user_properties where user_id in (select id from users where email = 'admin@admin.com');
@@ -0,0 +1,4 @@
+-- the demo administrator manages the first property
+insert into user_properties (user_id, property_id)
+select id, 1 from users where email = 'admin@admin.com'
+on conflict do nothing;
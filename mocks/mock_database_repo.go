@@ -6,149 +6,827 @@ package mocks
 
 import (
 	models "booking/models"
-	gomock "github.com/golang/mock/gomock"
+	context "context"
 	reflect "reflect"
 	time "time"
+
+	gomock "github.com/golang/mock/gomock"
 )
 
-// MockDatabaseRepo is a mock of DatabaseRepo interface
+// MockDatabaseRepo is a mock of DatabaseRepo interface.
 type MockDatabaseRepo struct {
 	ctrl     *gomock.Controller
 	recorder *MockDatabaseRepoMockRecorder
 }
 
-// MockDatabaseRepoMockRecorder is the mock recorder for MockDatabaseRepo
+// MockDatabaseRepoMockRecorder is the mock recorder for MockDatabaseRepo.
 type MockDatabaseRepoMockRecorder struct {
 	mock *MockDatabaseRepo
 }
 
-// NewMockDatabaseRepo creates a new mock instance
+// NewMockDatabaseRepo creates a new mock instance.
 func NewMockDatabaseRepo(ctrl *gomock.Controller) *MockDatabaseRepo {
 	mock := &MockDatabaseRepo{ctrl: ctrl}
 	mock.recorder = &MockDatabaseRepoMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockDatabaseRepo) EXPECT() *MockDatabaseRepoMockRecorder {
 	return m.recorder
 }
 
-// AllUsers mocks base method
-func (m *MockDatabaseRepo) AllUsers() bool {
+// AddReservationNote mocks base method.
+func (m *MockDatabaseRepo) AddReservationNote(ctx context.Context, reservationID int, body string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AllUsers")
+	ret := m.ctrl.Call(m, "AddReservationNote", ctx, reservationID, body)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReservationNote indicates an expected call of AddReservationNote.
+func (mr *MockDatabaseRepoMockRecorder) AddReservationNote(ctx, reservationID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReservationNote", reflect.TypeOf((*MockDatabaseRepo)(nil).AddReservationNote), ctx, reservationID, body)
+}
+
+// AllRooms mocks base method.
+func (m *MockDatabaseRepo) AllRooms(ctx context.Context) ([]models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllRooms", ctx)
+	ret0, _ := ret[0].([]models.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllRooms indicates an expected call of AllRooms.
+func (mr *MockDatabaseRepoMockRecorder) AllRooms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).AllRooms), ctx)
+}
+
+// AllUsers mocks base method.
+func (m *MockDatabaseRepo) AllUsers(ctx context.Context) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllUsers", ctx)
 	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-// AllUsers indicates an expected call of AllUsers
-func (mr *MockDatabaseRepoMockRecorder) AllUsers() *gomock.Call {
+// AllUsers indicates an expected call of AllUsers.
+func (mr *MockDatabaseRepoMockRecorder) AllUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllUsers", reflect.TypeOf((*MockDatabaseRepo)(nil).AllUsers), ctx)
+}
+
+// AuditLog mocks base method.
+func (m *MockDatabaseRepo) AuditLog(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, f)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockDatabaseRepoMockRecorder) AuditLog(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockDatabaseRepo)(nil).AuditLog), ctx, f)
+}
+
+// Authenticate mocks base method.
+func (m *MockDatabaseRepo) Authenticate(ctx context.Context, email, testPassword string) (int, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, email, testPassword)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockDatabaseRepoMockRecorder) Authenticate(ctx, email, testPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockDatabaseRepo)(nil).Authenticate), ctx, email, testPassword)
+}
+
+// CancelReservation mocks base method.
+func (m *MockDatabaseRepo) CancelReservation(ctx context.Context, id int, reason string, on time.Time) (models.Cancellation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReservation", ctx, id, reason, on)
+	ret0, _ := ret[0].(models.Cancellation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelReservation indicates an expected call of CancelReservation.
+func (mr *MockDatabaseRepoMockRecorder) CancelReservation(ctx, id, reason, on interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).CancelReservation), ctx, id, reason, on)
+}
+
+// CancellationPolicies mocks base method.
+func (m *MockDatabaseRepo) CancellationPolicies(ctx context.Context) ([]models.CancellationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancellationPolicies", ctx)
+	ret0, _ := ret[0].([]models.CancellationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancellationPolicies indicates an expected call of CancellationPolicies.
+func (mr *MockDatabaseRepoMockRecorder) CancellationPolicies(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllUsers", reflect.TypeOf((*MockDatabaseRepo)(nil).AllUsers))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancellationPolicies", reflect.TypeOf((*MockDatabaseRepo)(nil).CancellationPolicies), ctx)
 }
 
-// InsertReservation mocks base method
-func (m *MockDatabaseRepo) InsertReservation(res models.Reservation) (int, error) {
+// CancelledReservations mocks base method.
+func (m *MockDatabaseRepo) CancelledReservations(ctx context.Context) ([]models.Reservation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertReservation", res)
+	ret := m.ctrl.Call(m, "CancelledReservations", ctx)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelledReservations indicates an expected call of CancelledReservations.
+func (mr *MockDatabaseRepoMockRecorder) CancelledReservations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelledReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).CancelledReservations), ctx)
+}
+
+// CreateBlock mocks base method.
+func (m *MockDatabaseRepo) CreateBlock(ctx context.Context, roomID int, start, end time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlock", ctx, roomID, start, end)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertReservation indicates an expected call of InsertReservation
-func (mr *MockDatabaseRepoMockRecorder) InsertReservation(res interface{}) *gomock.Call {
+// CreateBlock indicates an expected call of CreateBlock.
+func (mr *MockDatabaseRepoMockRecorder) CreateBlock(ctx, roomID, start, end interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservation), res)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlock", reflect.TypeOf((*MockDatabaseRepo)(nil).CreateBlock), ctx, roomID, start, end)
 }
 
-// InsertRoomRestriction mocks base method
-func (m *MockDatabaseRepo) InsertRoomRestriction(r models.RoomRestriction) (int, error) {
+// CreateCancellationPolicy mocks base method.
+func (m *MockDatabaseRepo) CreateCancellationPolicy(ctx context.Context, c models.CancellationPolicy) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertRoomRestriction", r)
+	ret := m.ctrl.Call(m, "CreateCancellationPolicy", ctx, c)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertRoomRestriction indicates an expected call of InsertRoomRestriction
-func (mr *MockDatabaseRepoMockRecorder) InsertRoomRestriction(r interface{}) *gomock.Call {
+// CreateCancellationPolicy indicates an expected call of CreateCancellationPolicy.
+func (mr *MockDatabaseRepoMockRecorder) CreateCancellationPolicy(ctx, c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoomRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRoomRestriction), r)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCancellationPolicy", reflect.TypeOf((*MockDatabaseRepo)(nil).CreateCancellationPolicy), ctx, c)
 }
 
-// SearchAvailabilityByDatesByRoomID mocks base method
-func (m *MockDatabaseRepo) SearchAvailabilityByDatesByRoomID(roomID int, start, end time.Time) (bool, error) {
+// CreatePromoCode mocks base method.
+func (m *MockDatabaseRepo) CreatePromoCode(ctx context.Context, c models.PromoCode) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchAvailabilityByDatesByRoomID", roomID, start, end)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "CreatePromoCode", ctx, c)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchAvailabilityByDatesByRoomID indicates an expected call of SearchAvailabilityByDatesByRoomID
-func (mr *MockDatabaseRepoMockRecorder) SearchAvailabilityByDatesByRoomID(roomID, start, end interface{}) *gomock.Call {
+// CreatePromoCode indicates an expected call of CreatePromoCode.
+func (mr *MockDatabaseRepoMockRecorder) CreatePromoCode(ctx, c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailabilityByDatesByRoomID", reflect.TypeOf((*MockDatabaseRepo)(nil).SearchAvailabilityByDatesByRoomID), roomID, start, end)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromoCode", reflect.TypeOf((*MockDatabaseRepo)(nil).CreatePromoCode), ctx, c)
 }
 
-// SearchAvailabilityForAllRooms mocks base method
-func (m *MockDatabaseRepo) SearchAvailabilityForAllRooms(start, end time.Time) ([]models.Room, error) {
+// CreateReservation mocks base method.
+func (m *MockDatabaseRepo) CreateReservation(ctx context.Context, res models.Reservation) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchAvailabilityForAllRooms", start, end)
-	ret0, _ := ret[0].([]models.Room)
+	ret := m.ctrl.Call(m, "CreateReservation", ctx, res)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchAvailabilityForAllRooms indicates an expected call of SearchAvailabilityForAllRooms
-func (mr *MockDatabaseRepoMockRecorder) SearchAvailabilityForAllRooms(start, end interface{}) *gomock.Call {
+// CreateReservation indicates an expected call of CreateReservation.
+func (mr *MockDatabaseRepoMockRecorder) CreateReservation(ctx, res interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailabilityForAllRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).SearchAvailabilityForAllRooms), start, end)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).CreateReservation), ctx, res)
 }
 
-// GetRoomByID mocks base method
-func (m *MockDatabaseRepo) GetRoomByID(id int) (models.Room, error) {
+// CreateStayRule mocks base method.
+func (m *MockDatabaseRepo) CreateStayRule(ctx context.Context, r models.StayRule) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRoomByID", id)
+	ret := m.ctrl.Call(m, "CreateStayRule", ctx, r)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStayRule indicates an expected call of CreateStayRule.
+func (mr *MockDatabaseRepoMockRecorder) CreateStayRule(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStayRule", reflect.TypeOf((*MockDatabaseRepo)(nil).CreateStayRule), ctx, r)
+}
+
+// DeleteBlockByID mocks base method.
+func (m *MockDatabaseRepo) DeleteBlockByID(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlockByID", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlockByID indicates an expected call of DeleteBlockByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteBlockByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlockByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteBlockByID), ctx, id)
+}
+
+// DeleteCancellationPolicy mocks base method.
+func (m *MockDatabaseRepo) DeleteCancellationPolicy(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCancellationPolicy", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCancellationPolicy indicates an expected call of DeleteCancellationPolicy.
+func (mr *MockDatabaseRepoMockRecorder) DeleteCancellationPolicy(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCancellationPolicy", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteCancellationPolicy), ctx, id)
+}
+
+// DeleteStayRule mocks base method.
+func (m *MockDatabaseRepo) DeleteStayRule(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStayRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStayRule indicates an expected call of DeleteStayRule.
+func (mr *MockDatabaseRepoMockRecorder) DeleteStayRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStayRule", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteStayRule), ctx, id)
+}
+
+// EachReservation mocks base method.
+func (m *MockDatabaseRepo) EachReservation(ctx context.Context, filter models.ReservationFilter, fn func(models.Reservation) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EachReservation", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachReservation indicates an expected call of EachReservation.
+func (mr *MockDatabaseRepoMockRecorder) EachReservation(ctx, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).EachReservation), ctx, filter, fn)
+}
+
+// FrontDesk mocks base method.
+func (m *MockDatabaseRepo) FrontDesk(ctx context.Context, day time.Time) (models.FrontDesk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FrontDesk", ctx, day)
+	ret0, _ := ret[0].(models.FrontDesk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FrontDesk indicates an expected call of FrontDesk.
+func (mr *MockDatabaseRepoMockRecorder) FrontDesk(ctx, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FrontDesk", reflect.TypeOf((*MockDatabaseRepo)(nil).FrontDesk), ctx, day)
+}
+
+// GetCancellationPolicy mocks base method.
+func (m *MockDatabaseRepo) GetCancellationPolicy(ctx context.Context, id int) (models.CancellationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCancellationPolicy", ctx, id)
+	ret0, _ := ret[0].(models.CancellationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCancellationPolicy indicates an expected call of GetCancellationPolicy.
+func (mr *MockDatabaseRepoMockRecorder) GetCancellationPolicy(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCancellationPolicy", reflect.TypeOf((*MockDatabaseRepo)(nil).GetCancellationPolicy), ctx, id)
+}
+
+// GetOutboxMail mocks base method.
+func (m *MockDatabaseRepo) GetOutboxMail(ctx context.Context, id int) (models.OutboxMail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxMail", ctx, id)
+	ret0, _ := ret[0].(models.OutboxMail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxMail indicates an expected call of GetOutboxMail.
+func (mr *MockDatabaseRepoMockRecorder) GetOutboxMail(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxMail", reflect.TypeOf((*MockDatabaseRepo)(nil).GetOutboxMail), ctx, id)
+}
+
+// GetPromoCode mocks base method.
+func (m *MockDatabaseRepo) GetPromoCode(ctx context.Context, code string) (models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoCode", ctx, code)
+	ret0, _ := ret[0].(models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromoCode indicates an expected call of GetPromoCode.
+func (mr *MockDatabaseRepoMockRecorder) GetPromoCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoCode", reflect.TypeOf((*MockDatabaseRepo)(nil).GetPromoCode), ctx, code)
+}
+
+// GetPropertyByID mocks base method.
+func (m *MockDatabaseRepo) GetPropertyByID(ctx context.Context, id int) (models.Property, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPropertyByID", ctx, id)
+	ret0, _ := ret[0].(models.Property)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPropertyByID indicates an expected call of GetPropertyByID.
+func (mr *MockDatabaseRepoMockRecorder) GetPropertyByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPropertyByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetPropertyByID), ctx, id)
+}
+
+// GetReservationByID mocks base method.
+func (m *MockDatabaseRepo) GetReservationByID(ctx context.Context, id int) (models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservationByID", ctx, id)
+	ret0, _ := ret[0].(models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservationByID indicates an expected call of GetReservationByID.
+func (mr *MockDatabaseRepoMockRecorder) GetReservationByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservationByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReservationByID), ctx, id)
+}
+
+// GetRoomByID mocks base method.
+func (m *MockDatabaseRepo) GetRoomByID(ctx context.Context, id int) (models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomByID", ctx, id)
 	ret0, _ := ret[0].(models.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRoomByID indicates an expected call of GetRoomByID
-func (mr *MockDatabaseRepoMockRecorder) GetRoomByID(id interface{}) *gomock.Call {
+// GetRoomByID indicates an expected call of GetRoomByID.
+func (mr *MockDatabaseRepoMockRecorder) GetRoomByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomByID), ctx, id)
+}
+
+// GetRoomTypeByID mocks base method.
+func (m *MockDatabaseRepo) GetRoomTypeByID(ctx context.Context, id int) (models.RoomType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomTypeByID", ctx, id)
+	ret0, _ := ret[0].(models.RoomType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomTypeByID indicates an expected call of GetRoomTypeByID.
+func (mr *MockDatabaseRepoMockRecorder) GetRoomTypeByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomTypeByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomTypeByID), ctx, id)
+}
+
+// ImportReservations mocks base method.
+func (m *MockDatabaseRepo) ImportReservations(ctx context.Context, rows []models.ImportRow, commit bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportReservations", ctx, rows, commit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportReservations indicates an expected call of ImportReservations.
+func (mr *MockDatabaseRepoMockRecorder) ImportReservations(ctx, rows, commit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).ImportReservations), ctx, rows, commit)
 }
 
-// UpdateUser mocks base method
-func (m *MockDatabaseRepo) UpdateUser(u models.User) error {
+// InsertBlockForRoom mocks base method.
+func (m *MockDatabaseRepo) InsertBlockForRoom(ctx context.Context, id int, startDate time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", u)
+	ret := m.ctrl.Call(m, "InsertBlockForRoom", ctx, id, startDate)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateUser indicates an expected call of UpdateUser
-func (mr *MockDatabaseRepoMockRecorder) UpdateUser(u interface{}) *gomock.Call {
+// InsertBlockForRoom indicates an expected call of InsertBlockForRoom.
+func (mr *MockDatabaseRepoMockRecorder) InsertBlockForRoom(ctx, id, startDate interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateUser), u)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlockForRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertBlockForRoom), ctx, id, startDate)
 }
 
-// Authenticate mocks base method
-func (m *MockDatabaseRepo) Authenticate(email, testPassword string) (int, string, error) {
+// InsertReservation mocks base method.
+func (m *MockDatabaseRepo) InsertReservation(ctx context.Context, res models.Reservation) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authenticate", email, testPassword)
+	ret := m.ctrl.Call(m, "InsertReservation", ctx, res)
 	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReservation indicates an expected call of InsertReservation.
+func (mr *MockDatabaseRepoMockRecorder) InsertReservation(ctx, res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservation), ctx, res)
+}
+
+// InsertRoomRestriction mocks base method.
+func (m *MockDatabaseRepo) InsertRoomRestriction(ctx context.Context, r models.RoomRestriction) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRoomRestriction", ctx, r)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRoomRestriction indicates an expected call of InsertRoomRestriction.
+func (mr *MockDatabaseRepoMockRecorder) InsertRoomRestriction(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoomRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRoomRestriction), ctx, r)
+}
+
+// MarkMailSent mocks base method.
+func (m *MockDatabaseRepo) MarkMailSent(ctx context.Context, id int, sendErr error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMailSent", ctx, id, sendErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMailSent indicates an expected call of MarkMailSent.
+func (mr *MockDatabaseRepoMockRecorder) MarkMailSent(ctx, id, sendErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMailSent", reflect.TypeOf((*MockDatabaseRepo)(nil).MarkMailSent), ctx, id, sendErr)
+}
+
+// MoveReservation mocks base method.
+func (m *MockDatabaseRepo) MoveReservation(ctx context.Context, id, roomID int, start, end time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveReservation", ctx, id, roomID, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveReservation indicates an expected call of MoveReservation.
+func (mr *MockDatabaseRepoMockRecorder) MoveReservation(ctx, id, roomID, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).MoveReservation), ctx, id, roomID, start, end)
+}
+
+// Movements mocks base method.
+func (m *MockDatabaseRepo) Movements(ctx context.Context, period models.ReportPeriod) (models.Movements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Movements", ctx, period)
+	ret0, _ := ret[0].(models.Movements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Movements indicates an expected call of Movements.
+func (mr *MockDatabaseRepoMockRecorder) Movements(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Movements", reflect.TypeOf((*MockDatabaseRepo)(nil).Movements), ctx, period)
+}
+
+// Occupancy mocks base method.
+func (m *MockDatabaseRepo) Occupancy(ctx context.Context, period models.ReportPeriod) (models.OccupancyReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Occupancy", ctx, period)
+	ret0, _ := ret[0].(models.OccupancyReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Occupancy indicates an expected call of Occupancy.
+func (mr *MockDatabaseRepoMockRecorder) Occupancy(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Occupancy", reflect.TypeOf((*MockDatabaseRepo)(nil).Occupancy), ctx, period)
+}
+
+// PromoCodes mocks base method.
+func (m *MockDatabaseRepo) PromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoCodes", ctx)
+	ret0, _ := ret[0].([]models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoCodes indicates an expected call of PromoCodes.
+func (mr *MockDatabaseRepoMockRecorder) PromoCodes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoCodes", reflect.TypeOf((*MockDatabaseRepo)(nil).PromoCodes), ctx)
+}
+
+// PurgeCancelledReservations mocks base method.
+func (m *MockDatabaseRepo) PurgeCancelledReservations(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeCancelledReservations", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeCancelledReservations indicates an expected call of PurgeCancelledReservations.
+func (mr *MockDatabaseRepoMockRecorder) PurgeCancelledReservations(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeCancelledReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).PurgeCancelledReservations), ctx, before)
+}
+
+// QueueMail mocks base method.
+func (m *MockDatabaseRepo) QueueMail(ctx context.Context, msg models.MailData) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueMail", ctx, msg)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueueMail indicates an expected call of QueueMail.
+func (mr *MockDatabaseRepoMockRecorder) QueueMail(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueMail", reflect.TypeOf((*MockDatabaseRepo)(nil).QueueMail), ctx, msg)
+}
+
+// ReservationTimeline mocks base method.
+func (m *MockDatabaseRepo) ReservationTimeline(ctx context.Context, id int) ([]models.TimelineEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReservationTimeline", ctx, id)
+	ret0, _ := ret[0].([]models.TimelineEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReservationTimeline indicates an expected call of ReservationTimeline.
+func (mr *MockDatabaseRepoMockRecorder) ReservationTimeline(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReservationTimeline", reflect.TypeOf((*MockDatabaseRepo)(nil).ReservationTimeline), ctx, id)
+}
+
+// Reservations mocks base method.
+func (m *MockDatabaseRepo) Reservations(ctx context.Context, filter models.ReservationFilter) (models.ReservationPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reservations", ctx, filter)
+	ret0, _ := ret[0].(models.ReservationPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reservations indicates an expected call of Reservations.
+func (mr *MockDatabaseRepoMockRecorder) Reservations(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reservations", reflect.TypeOf((*MockDatabaseRepo)(nil).Reservations), ctx, filter)
+}
+
+// ReserveRoomType mocks base method.
+func (m *MockDatabaseRepo) ReserveRoomType(ctx context.Context, typeID int, res models.Reservation) (models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveRoomType", ctx, typeID, res)
+	ret0, _ := ret[0].(models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveRoomType indicates an expected call of ReserveRoomType.
+func (mr *MockDatabaseRepoMockRecorder) ReserveRoomType(ctx, typeID, res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveRoomType", reflect.TypeOf((*MockDatabaseRepo)(nil).ReserveRoomType), ctx, typeID, res)
+}
+
+// RestoreReservation mocks base method.
+func (m *MockDatabaseRepo) RestoreReservation(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreReservation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreReservation indicates an expected call of RestoreReservation.
+func (mr *MockDatabaseRepoMockRecorder) RestoreReservation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).RestoreReservation), ctx, id)
+}
+
+// RestrictionsInRange mocks base method.
+func (m *MockDatabaseRepo) RestrictionsInRange(ctx context.Context, from, to time.Time) ([]models.RoomRestriction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestrictionsInRange", ctx, from, to)
+	ret0, _ := ret[0].([]models.RoomRestriction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestrictionsInRange indicates an expected call of RestrictionsInRange.
+func (mr *MockDatabaseRepoMockRecorder) RestrictionsInRange(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestrictionsInRange", reflect.TypeOf((*MockDatabaseRepo)(nil).RestrictionsInRange), ctx, from, to)
+}
+
+// RoomTypes mocks base method.
+func (m *MockDatabaseRepo) RoomTypes(ctx context.Context) ([]models.RoomType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoomTypes", ctx)
+	ret0, _ := ret[0].([]models.RoomType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoomTypes indicates an expected call of RoomTypes.
+func (mr *MockDatabaseRepoMockRecorder) RoomTypes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomTypes", reflect.TypeOf((*MockDatabaseRepo)(nil).RoomTypes), ctx)
+}
+
+// SearchAvailabilityByDatesByRoomID mocks base method.
+func (m *MockDatabaseRepo) SearchAvailabilityByDatesByRoomID(ctx context.Context, roomID int, start, end time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAvailabilityByDatesByRoomID", ctx, roomID, start, end)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAvailabilityByDatesByRoomID indicates an expected call of SearchAvailabilityByDatesByRoomID.
+func (mr *MockDatabaseRepoMockRecorder) SearchAvailabilityByDatesByRoomID(ctx, roomID, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailabilityByDatesByRoomID", reflect.TypeOf((*MockDatabaseRepo)(nil).SearchAvailabilityByDatesByRoomID), ctx, roomID, start, end)
+}
+
+// SearchAvailabilityByType mocks base method.
+func (m *MockDatabaseRepo) SearchAvailabilityByType(ctx context.Context, start, end time.Time, guests int) ([]models.TypeAvailability, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAvailabilityByType", ctx, start, end, guests)
+	ret0, _ := ret[0].([]models.TypeAvailability)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAvailabilityByType indicates an expected call of SearchAvailabilityByType.
+func (mr *MockDatabaseRepoMockRecorder) SearchAvailabilityByType(ctx, start, end, guests interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailabilityByType", reflect.TypeOf((*MockDatabaseRepo)(nil).SearchAvailabilityByType), ctx, start, end, guests)
+}
+
+// SearchAvailabilityForAllRooms mocks base method.
+func (m *MockDatabaseRepo) SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time, guests int) ([]models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAvailabilityForAllRooms", ctx, start, end, guests)
+	ret0, _ := ret[0].([]models.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAvailabilityForAllRooms indicates an expected call of SearchAvailabilityForAllRooms.
+func (mr *MockDatabaseRepoMockRecorder) SearchAvailabilityForAllRooms(ctx, start, end, guests interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailabilityForAllRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).SearchAvailabilityForAllRooms), ctx, start, end, guests)
+}
+
+// SetPromoCodeActive mocks base method.
+func (m *MockDatabaseRepo) SetPromoCodeActive(ctx context.Context, id int, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPromoCodeActive", ctx, id, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPromoCodeActive indicates an expected call of SetPromoCodeActive.
+func (mr *MockDatabaseRepoMockRecorder) SetPromoCodeActive(ctx, id, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPromoCodeActive", reflect.TypeOf((*MockDatabaseRepo)(nil).SetPromoCodeActive), ctx, id, active)
+}
+
+// SetRate mocks base method.
+func (m *MockDatabaseRepo) SetRate(ctx context.Context, typeID int, rate models.Money, policyID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRate", ctx, typeID, rate, policyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRate indicates an expected call of SetRate.
+func (mr *MockDatabaseRepoMockRecorder) SetRate(ctx, typeID, rate, policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRate", reflect.TypeOf((*MockDatabaseRepo)(nil).SetRate), ctx, typeID, rate, policyID)
+}
+
+// StayRules mocks base method.
+func (m *MockDatabaseRepo) StayRules(ctx context.Context) (models.StayRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StayRules", ctx)
+	ret0, _ := ret[0].(models.StayRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StayRules indicates an expected call of StayRules.
+func (mr *MockDatabaseRepoMockRecorder) StayRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StayRules", reflect.TypeOf((*MockDatabaseRepo)(nil).StayRules), ctx)
+}
+
+// StayStats mocks base method.
+func (m *MockDatabaseRepo) StayStats(ctx context.Context, period models.ReportPeriod) (models.StayStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StayStats", ctx, period)
+	ret0, _ := ret[0].(models.StayStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StayStats indicates an expected call of StayStats.
+func (mr *MockDatabaseRepoMockRecorder) StayStats(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StayStats", reflect.TypeOf((*MockDatabaseRepo)(nil).StayStats), ctx, period)
+}
+
+// TransitionReservation mocks base method.
+func (m *MockDatabaseRepo) TransitionReservation(ctx context.Context, id int, to models.ReservationStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionReservation", ctx, id, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransitionReservation indicates an expected call of TransitionReservation.
+func (mr *MockDatabaseRepoMockRecorder) TransitionReservation(ctx, id, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).TransitionReservation), ctx, id, to)
+}
+
+// UpdateProperty mocks base method.
+func (m *MockDatabaseRepo) UpdateProperty(ctx context.Context, p models.Property) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProperty", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProperty indicates an expected call of UpdateProperty.
+func (mr *MockDatabaseRepoMockRecorder) UpdateProperty(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProperty", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateProperty), ctx, p)
+}
+
+// UpdateReservation mocks base method.
+func (m *MockDatabaseRepo) UpdateReservation(ctx context.Context, r models.Reservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReservation", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReservation indicates an expected call of UpdateReservation.
+func (mr *MockDatabaseRepoMockRecorder) UpdateReservation(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservation), ctx, r)
+}
+
+// UpdateUser mocks base method.
+func (m *MockDatabaseRepo) UpdateUser(ctx context.Context, u models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockDatabaseRepoMockRecorder) UpdateUser(ctx, u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateUser), ctx, u)
+}
+
+// UserProperties mocks base method.
+func (m *MockDatabaseRepo) UserProperties(ctx context.Context, userID int) ([]models.Property, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserProperties", ctx, userID)
+	ret0, _ := ret[0].([]models.Property)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Authenticate indicates an expected call of Authenticate
-func (mr *MockDatabaseRepoMockRecorder) Authenticate(email, testPassword interface{}) *gomock.Call {
+// UserProperties indicates an expected call of UserProperties.
+func (mr *MockDatabaseRepoMockRecorder) UserProperties(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockDatabaseRepo)(nil).Authenticate), email, testPassword)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserProperties", reflect.TypeOf((*MockDatabaseRepo)(nil).UserProperties), ctx, userID)
 }
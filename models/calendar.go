@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// CellState tells what occupies a room on a night of the calendar
+type CellState string
+
+// States of a calendar night
+const (
+	CellFree     CellState = "free"
+	CellReserved CellState = "reserved"
+	CellBlocked  CellState = "blocked"
+)
+
+// CalendarCell is one night of a room, the ids are set when the night is reserved or blocked
+type CalendarCell struct {
+	Date          time.Time
+	State         CellState
+	ReservationID int
+	// RestrictionID is the room restriction occupying the night, for blocked nights the block to remove
+	RestrictionID int
+	// First is set on the first night of the restriction shown on the calendar
+	First bool
+}
+
+// CalendarRoom is the row of a room on the calendar
+type CalendarRoom struct {
+	Room  Room
+	Cells []CalendarCell
+}
+
+// Calendar lays out every room over the nights from From up to but not including To
+type Calendar struct {
+	From  time.Time
+	To    time.Time
+	Days  []time.Time
+	Rooms []CalendarRoom
+}
+
+// NewCalendar places restrictions on the nights of rooms. A restriction occupies the nights from its start
+// date up to its end date, the day of departure is free for the next guest.
+func NewCalendar(from, to time.Time, rooms []Room, restrictions []RoomRestriction) Calendar {
+	c := Calendar{From: from, To: to}
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		c.Days = append(c.Days, d)
+	}
+
+	index := make(map[int]int, len(rooms))
+	for i, room := range rooms {
+		index[room.ID] = i
+		row := CalendarRoom{Room: room, Cells: make([]CalendarCell, len(c.Days))}
+		for j, d := range c.Days {
+			row.Cells[j] = CalendarCell{Date: d, State: CellFree}
+		}
+		c.Rooms = append(c.Rooms, row)
+	}
+
+	for _, rs := range restrictions {
+		i, ok := index[rs.RoomID]
+		if !ok {
+			continue
+		}
+
+		state := CellBlocked
+		if rs.ReservationID > 0 {
+			state = CellReserved
+		}
+
+		first := true
+		for j, d := range c.Days {
+			if d.Before(rs.StartDate) || !d.Before(rs.EndDate) {
+				continue
+			}
+			c.Rooms[i].Cells[j] = CalendarCell{
+				Date:          d,
+				State:         state,
+				ReservationID: rs.ReservationID,
+				RestrictionID: rs.ID,
+				First:         first,
+			}
+			first = false
+		}
+	}
+
+	return c
+}
+
+// Blocks returns the ids of the blocks shown in the row, once each
+func (r CalendarRoom) Blocks() []int {
+	var ids []int
+	for _, cell := range r.Cells {
+		if cell.State == CellBlocked && cell.First {
+			ids = append(ids, cell.RestrictionID)
+		}
+	}
+	return ids
+}
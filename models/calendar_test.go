@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCalendar(t *testing.T) {
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return from.AddDate(0, 0, n-1) }
+
+	rooms := []Room{{ID: 1, RoomName: "General's Quarters"}, {ID: 2, RoomName: "Major's Suite"}}
+	c := NewCalendar(from, from.AddDate(0, 1, 0), rooms, []RoomRestriction{
+		// started last month, leaves on the 3rd
+		{ID: 7, RoomID: 1, ReservationID: 3, StartDate: day(-1), EndDate: day(3)},
+		// arrives the day the previous guest leaves
+		{ID: 8, RoomID: 1, ReservationID: 4, StartDate: day(3), EndDate: day(5)},
+		{ID: 9, RoomID: 2, StartDate: day(10), EndDate: day(12)},
+		// rooms that are not shown are left out
+		{ID: 10, RoomID: 5, StartDate: day(1), EndDate: day(2)},
+	})
+
+	assert.Len(t, c.Days, 30)
+	assert.Len(t, c.Rooms, 2)
+
+	cells := c.Rooms[0].Cells
+	assert.Equal(t, CalendarCell{Date: day(1), State: CellReserved, ReservationID: 3, RestrictionID: 7, First: true}, cells[0])
+	assert.Equal(t, CellReserved, cells[1].State)
+	assert.False(t, cells[1].First)
+	assert.Equal(t, CalendarCell{Date: day(3), State: CellReserved, ReservationID: 4, RestrictionID: 8, First: true}, cells[2])
+	assert.Equal(t, CellFree, cells[4].State)
+
+	cells = c.Rooms[1].Cells
+	assert.Equal(t, CellFree, cells[8].State)
+	assert.Equal(t, CalendarCell{Date: day(10), State: CellBlocked, RestrictionID: 9, First: true}, cells[9])
+	assert.Equal(t, CellBlocked, cells[10].State)
+	assert.Equal(t, CellFree, cells[11].State)
+	assert.Equal(t, []int{9}, c.Rooms[1].Blocks())
+	assert.Empty(t, c.Rooms[0].Blocks())
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"booking/dates"
+	"fmt"
+	"time"
+)
+
+// CancellationPolicy is the terms guests cancel a stay on. Cancelling is free until FreeDays days before
+// arrival, PenaltyPercent of the price is charged from then on. A NonRefundable stay is charged in full
+// whenever it is cancelled. The zero CancellationPolicy lets guests cancel for free until they arrive.
+type CancellationPolicy struct {
+	ID             int
+	PropertyID     int
+	Name           string
+	FreeDays       int
+	PenaltyPercent int
+	NonRefundable  bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Cancellation is what cancelling a reservation costs: the Penalty kept of its total and the Refund
+type Cancellation struct {
+	Penalty Money
+	Refund  Money
+}
+
+// FreeUntil is the last day the stay arriving on arrival can be cancelled for free
+func (p CancellationPolicy) FreeUntil(arrival time.Time) dates.Date {
+	return dates.Of(arrival).AddDays(-p.FreeDays)
+}
+
+// Penalty returns how much of total is charged when the stay arriving on arrival is cancelled on day on
+func (p CancellationPolicy) Penalty(total Money, arrival time.Time, on dates.Date) Money {
+	switch {
+	case p.NonRefundable:
+		return total
+	case on.After(p.FreeUntil(arrival)):
+		return total * Money(p.PenaltyPercent) / 100
+	}
+	return 0
+}
+
+// charge tells what is charged once cancelling is no longer free, e.g. "50% of the price"
+func (p CancellationPolicy) charge() string {
+	if p.PenaltyPercent == 100 {
+		return "the full price"
+	}
+	return fmt.Sprintf("%d%% of the price", p.PenaltyPercent)
+}
+
+// Describe sums the policy up for staff, e.g. "Free until 7 days before arrival, then 50% of the price"
+func (p CancellationPolicy) Describe() string {
+	switch {
+	case p.NonRefundable:
+		return "Non-refundable"
+	case p.PenaltyPercent == 0:
+		return "Free cancellation"
+	case p.FreeDays == 0:
+		return fmt.Sprintf("Free until the day of arrival, then %s", p.charge())
+	}
+	return fmt.Sprintf("Free until %s before arrival, then %s", plural(p.FreeDays, "day", "days"), p.charge())
+}
+
+// Terms tells guests arriving on arrival what cancelling costs them, e.g. "Free cancellation until
+// Sun 20 Dec 2026, then 50% of the price is charged"
+func (p CancellationPolicy) Terms(arrival time.Time) string {
+	switch {
+	case p.NonRefundable:
+		return "Non-refundable, the full price is charged when cancelling"
+	case p.PenaltyPercent == 0:
+		return "Free cancellation"
+	}
+	return fmt.Sprintf("Free cancellation until %s, then %s is charged", humanDay(p.FreeUntil(arrival)), p.charge())
+}
+
+// Cancellation returns the penalty and refund of cancelling the reservation on day on under its policy.
+// The refund is what is left of the total, staff settle it with the guest.
+func (r Reservation) Cancellation(on dates.Date) Cancellation {
+	penalty := r.CancellationPolicy.Penalty(r.Total, r.StartDate, on)
+	return Cancellation{Penalty: penalty, Refund: r.Total - penalty}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"booking/dates"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationPolicy_Penalty(t *testing.T) {
+	arrival := time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)
+	week := CancellationPolicy{FreeDays: 7, PenaltyPercent: 50}
+
+	// the seventh day before arrival is the last free one
+	assert.Equal(t, Money(0), week.Penalty(20000, arrival, dates.Of(arrival).AddDays(-7)))
+	assert.Equal(t, Money(10000), week.Penalty(20000, arrival, dates.Of(arrival).AddDays(-6)))
+
+	assert.Equal(t, Money(20000), CancellationPolicy{NonRefundable: true}.Penalty(20000, arrival, dates.Of(arrival).AddDays(-90)))
+	assert.Equal(t, Money(0), CancellationPolicy{}.Penalty(20000, arrival, dates.Of(arrival)))
+
+	res := Reservation{StartDate: arrival, Total: 20000, CancellationPolicy: week}
+	assert.Equal(t, Cancellation{Penalty: 10000, Refund: 10000}, res.Cancellation(dates.Of(arrival)))
+}
+
+func TestCancellationPolicy_Terms(t *testing.T) {
+	arrival := time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)
+
+	week := CancellationPolicy{FreeDays: 7, PenaltyPercent: 50}
+	assert.Equal(t, "Free until 7 days before arrival, then 50% of the price", week.Describe())
+	assert.Equal(t, "Free cancellation until Sun 20 Dec 2026, then 50% of the price is charged", week.Terms(arrival))
+
+	sameDay := CancellationPolicy{PenaltyPercent: 100}
+	assert.Equal(t, "Free until the day of arrival, then the full price", sameDay.Describe())
+
+	assert.Equal(t, "Non-refundable", CancellationPolicy{NonRefundable: true}.Describe())
+	assert.Equal(t, "Free cancellation", CancellationPolicy{}.Terms(arrival))
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain errors returned by the repository and handlers. Wrap them with fmt.Errorf("...: %w", err)
+// to add details, callers should compare with errors.Is
+var (
+	// ErrNotFound means the requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the change clashes with existing data, e.g. the room is already booked
+	ErrConflict = errors.New("conflict")
+	// ErrValidation means the input is malformed or breaks a business rule
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized means the user is not logged in or gave wrong credentials
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// ValidationError describes which input was rejected and why; it matches ErrValidation
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// NewValidationError returns a validation error for field
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseGuests reads the number of adults and children of a stay coming from user input. An empty number of
+// adults is one and an empty number of children is none, every stay has at least one adult.
+func ParseGuests(adults, children string) (int, int, error) {
+	a, c := 1, 0
+	var err error
+	if s := strings.TrimSpace(adults); s != "" {
+		if a, err = strconv.Atoi(s); err != nil {
+			return 0, 0, NewValidationError("adults", "invalid number of adults")
+		}
+	}
+	if a < 1 {
+		return 0, 0, NewValidationError("adults", "at least one adult must stay")
+	}
+	if s := strings.TrimSpace(children); s != "" {
+		if c, err = strconv.Atoi(s); err != nil || c < 0 {
+			return 0, 0, NewValidationError("children", "invalid number of children")
+		}
+	}
+	return a, c, nil
+}
+
+// Guests is the number of people staying, adults and children alike
+func (r Reservation) Guests() int {
+	return r.Adults + r.Children
+}
+
+// GuestSummary describes who stays, e.g. "2 adults, 1 child"
+func (r Reservation) GuestSummary() string {
+	s := plural(r.Adults, "adult", "adults")
+	if r.Children > 0 {
+		s += ", " + plural(r.Children, "child", "children")
+	}
+	return s
+}
+
+// Fits tells whether a room of type t sleeps the guests of r
+func (t RoomType) Fits(r Reservation) bool {
+	return r.Guests() <= t.MaxOccupancy
+}
+
+// plural formats n with the singular or plural form of a noun
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGuests(t *testing.T) {
+	tests := []struct {
+		adults, children string
+		a, c             int
+		field            string
+	}{
+		{"", "", 1, 0, ""},
+		{"2", "1", 2, 1, ""},
+		{" 3 ", "0", 3, 0, ""},
+		{"0", "", 0, 0, "adults"},
+		{"two", "", 0, 0, "adults"},
+		{"2", "-1", 0, 0, "children"},
+		{"2", "x", 0, 0, "children"},
+	}
+
+	for _, test := range tests {
+		a, c, err := ParseGuests(test.adults, test.children)
+		if test.field == "" {
+			assert.NoError(t, err, "%q %q", test.adults, test.children)
+			assert.Equal(t, test.a, a)
+			assert.Equal(t, test.c, c)
+			continue
+		}
+		var validationErr *ValidationError
+		if assert.True(t, errors.As(err, &validationErr), "%q %q", test.adults, test.children) {
+			assert.Equal(t, test.field, validationErr.Field)
+		}
+	}
+}
+
+func TestReservation_GuestSummary(t *testing.T) {
+	assert.Equal(t, "1 adult", Reservation{Adults: 1}.GuestSummary())
+	assert.Equal(t, "2 adults, 1 child", Reservation{Adults: 2, Children: 1}.GuestSummary())
+	assert.Equal(t, "1 adult, 3 children", Reservation{Adults: 1, Children: 3}.GuestSummary())
+}
+
+func TestRoomType_Fits(t *testing.T) {
+	double := RoomType{MaxOccupancy: 2}
+	assert.True(t, double.Fits(Reservation{Adults: 2}))
+	assert.True(t, double.Fits(Reservation{Adults: 1, Children: 1}))
+	assert.False(t, double.Fits(Reservation{Adults: 2, Children: 1}))
+}
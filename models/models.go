@@ -2,6 +2,7 @@
 package models
 
 import (
+	"booking/dates"
 	"time"
 )
 
@@ -19,10 +20,37 @@ type User struct {
 
 // Rooms is the room model
 type Room struct {
-	ID        int
-	RoomName  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         int
+	PropertyID int
+	RoomTypeID int
+	RoomName   string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// RoomType is what guests book, e.g. a standard double, the property has one or more rooms of each type
+// and one of them is assigned to the reservation
+type RoomType struct {
+	ID          int
+	PropertyID  int
+	Name        string
+	Description string
+	// MaxOccupancy is the most guests a room of the type sleeps, adults and children alike
+	MaxOccupancy int
+	NightlyRate  Money
+	// CancellationPolicyID is the policy stays in the type are booked on, 0 for free cancellation
+	CancellationPolicyID int
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	// Rooms are the units of the type, only loaded by RoomTypes
+	Rooms []Room
+}
+
+// TypeAvailability is how many rooms of a type are free for a stay
+type TypeAvailability struct {
+	RoomType  RoomType
+	Units     int
+	Available int
 }
 
 // Restrictions is the restriction model
@@ -42,10 +70,107 @@ type Reservation struct {
 	StartDate time.Time
 	EndDate   time.Time
 	RoomID    int
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Processed int
-	Room      Room
+	Adults    int
+	Children  int
+	// Subtotal is the price of the nights, Total what the guest pays once Discount is taken off
+	Subtotal Money
+	Discount Money
+	Total    Money
+	// PromoCode is the code the discount comes from, only its ID and Code are loaded
+	PromoCode PromoCode
+	// CancellationPolicy is a copy of the terms the stay was booked on, without ID or dates
+	CancellationPolicy CancellationPolicy
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Status             ReservationStatus
+	Room               Room
+	// RoomType is the type of Room, the one the guest booked
+	RoomType RoomType
+	// the time of each status transition, zero until it happens
+	ConfirmedAt  time.Time
+	CheckedInAt  time.Time
+	CheckedOutAt time.Time
+	CancelledAt  time.Time
+	NoShowAt     time.Time
+	CancelReason string
+	// Penalty and Refund are recorded when the reservation is cancelled
+	Penalty Money
+	Refund  Money
+	Notes   []ReservationNote
+}
+
+// Nights is the length of the stay
+func (r Reservation) Nights() int {
+	return dates.Nights(r.StartDate, r.EndDate)
+}
+
+// Cancelled tells whether the reservation is in the trash
+func (r Reservation) Cancelled() bool {
+	return r.Status == StatusCancelled
+}
+
+// Sort keys accepted by ReservationFilter.Sort
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByRoom      = "room"
+	SortByArrival   = "arrival"
+	SortByDeparture = "departure"
+	SortByStatus    = "status"
+	SortByCreated   = "created"
+)
+
+// ReservationSortKeys lists the valid sort keys
+var ReservationSortKeys = []string{SortByID, SortByName, SortByRoom, SortByArrival, SortByDeparture, SortByStatus, SortByCreated}
+
+// ReservationFilter narrows down, orders and pages the admin reservation lists
+type ReservationFilter struct {
+	// Status selects a single status, empty means every reservation that is not cancelled
+	Status ReservationStatus
+	// Search matches part of the guest's first name, last name or email
+	Search string
+	RoomID int
+	// From and To keep the stays that overlap these days, both inclusive
+	From time.Time
+	To   time.Time
+	// Sort is one of ReservationSortKeys, arrival by default
+	Sort string
+	Desc bool
+	// Page starts at 1, PerPage defaults to DefaultPerPage
+	Page    int
+	PerPage int
+}
+
+const (
+	DefaultPerPage = 25
+	MaxPerPage     = 100
+)
+
+// ReservationPage is one page of a filtered reservation list
+type ReservationPage struct {
+	Reservations []Reservation
+	// Total counts every reservation matching the filter, on all pages
+	Total   int
+	Page    int
+	PerPage int
+}
+
+// Pages is the number of pages needed to show every matching reservation
+func (p ReservationPage) Pages() int {
+	if p.PerPage <= 0 || p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+// HasPrev tells whether there is a page before this one
+func (p ReservationPage) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext tells whether there is a page after this one
+func (p ReservationPage) HasNext() bool {
+	return p.Page < p.Pages()
 }
 
 type RoomRestriction struct {
@@ -63,9 +188,121 @@ type RoomRestriction struct {
 }
 
 type MailData struct {
-	To       string
-	From     string
-	Subject  string
-	Content  string
-	Template string
+	// ID is the outbox entry of the email, 0 when it is not recorded
+	ID int
+	// ReservationID links the email to the timeline of a reservation
+	ReservationID int
+	// RequestID is the request the email was sent from, empty for background jobs
+	RequestID   string
+	To          string
+	From        string
+	Subject     string
+	Content     string
+	Template    string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Outbox statuses of an email
+const (
+	MailQueued = "queued"
+	MailSent   = "sent"
+	MailFailed = "failed"
+)
+
+// OutboxMail is an email recorded in the outbox, with whether it could be delivered
+type OutboxMail struct {
+	ID            int
+	ReservationID int
+	To            string
+	From          string
+	Subject       string
+	Content       string
+	Template      string
+	Status        string
+	Error         string
+	CreatedAt     time.Time
+	SentAt        time.Time
+}
+
+// ReservationNote is a note left by staff on a reservation
+type ReservationNote struct {
+	ID            int
+	ReservationID int
+	UserID        int
+	UserName      string
+	Body          string
+	CreatedAt     time.Time
+}
+
+// FrontDesk lists the guests front-desk staff deal with on a day, sorted by room
+type FrontDesk struct {
+	Date       time.Time
+	Arrivals   []Reservation
+	Departures []Reservation
+	InHouse    []Reservation
+}
+
+// AuditEntry is one row of the append-only audit trail
+type AuditEntry struct {
+	ID         int
+	UserID     int
+	UserName   string
+	Action     string
+	EntityType string
+	EntityID   int
+	Before     string
+	After      string
+	CreatedAt  time.Time
+}
+
+// AuditFilter narrows down the audit trail, zero values match everything
+type AuditFilter struct {
+	UserID     int
+	Action     string
+	EntityType string
+	EntityID   int
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+// ImportRow is one line of a reservation import and the problems found in it
+type ImportRow struct {
+	Line        int
+	Reservation Reservation
+	Errors      []string
+}
+
+// Accepted tells whether the row can be imported
+func (r ImportRow) Accepted() bool {
+	return len(r.Errors) == 0
+}
+
+// ImportReport is the outcome of an import, a dry run unless Committed
+type ImportReport struct {
+	Rows      []ImportRow
+	Committed bool
+}
+
+// Accepted counts the rows that are, or would be, imported
+func (r ImportReport) Accepted() int {
+	n := 0
+	for _, row := range r.Rows {
+		if row.Accepted() {
+			n++
+		}
+	}
+	return n
+}
+
+// Rejected counts the rows left out of the import
+func (r ImportReport) Rejected() int {
+	return len(r.Rows) - r.Accepted()
 }
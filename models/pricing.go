@@ -0,0 +1,93 @@
+package models
+
+import (
+	"booking/dates"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in the minor unit of the currency of the property, e.g. cents
+type Money int
+
+// String formats the amount with two decimals, e.g. 120.50
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+var moneyPattern = regexp.MustCompile(`^(\d+)(?:[.,](\d{1,2}))?$`)
+
+// ParseMoney reads an amount typed with at most two decimals, such as 120 or 120.5
+func ParseMoney(s string) (Money, error) {
+	m := moneyPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, NewValidationError("", "invalid amount, use a number such as 120.50")
+	}
+	units, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, NewValidationError("", "invalid amount, use a number such as 120.50")
+	}
+	cents, _ := strconv.Atoi((m[2] + "00")[:2])
+	return Money(units*100 + cents), nil
+}
+
+// Quote is the price of a stay in a room type, with the discount of a promo code
+type Quote struct {
+	Nights      int
+	NightlyRate Money
+	Subtotal    Money
+	Discount    Money
+	Total       Money
+	// PromoCode is the code the discount comes from, the zero PromoCode without one
+	PromoCode PromoCode
+	// CancellationPolicy is the policy of the room type, the stay is booked on it
+	CancellationPolicy CancellationPolicy
+}
+
+// NewQuote prices a stay in room type t at its nightly rate. A stay that does not last a night has no
+// price, the error is a ValidationError on end_date.
+func NewQuote(t RoomType, stay dates.Range) (Quote, error) {
+	if stay.Nights() < 1 {
+		return Quote{}, NewValidationError("end_date", "Departure must be after arrival")
+	}
+	subtotal := t.NightlyRate * Money(stay.Nights())
+	return Quote{
+		Nights:      stay.Nights(),
+		NightlyRate: t.NightlyRate,
+		Subtotal:    subtotal,
+		Total:       subtotal,
+	}, nil
+}
+
+// WithPromo returns the quote discounted by promo code p, the discount never exceeds the subtotal
+func (q Quote) WithPromo(p PromoCode) Quote {
+	q.PromoCode = p
+	q.Discount = p.DiscountOn(q.Subtotal)
+	q.Total = q.Subtotal - q.Discount
+	return q
+}
+
+// Price records the amounts of quote q on the reservation
+func (r *Reservation) Price(q Quote) {
+	r.Subtotal, r.Discount, r.Total = q.Subtotal, q.Discount, q.Total
+	r.PromoCode = q.PromoCode
+	r.CancellationPolicy = q.CancellationPolicy
+}
+
+// PriceSummary describes the price of the reservation in currency, e.g. "EUR 171.00 after 19.00 off with
+// WINTER10". It is empty for reservations booked before stays had a price.
+func (r Reservation) PriceSummary(currency string) string {
+	if r.Subtotal == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("%s %s", currency, r.Total)
+	if r.Discount > 0 {
+		s += fmt.Sprintf(" after %s off with %s", r.Discount, r.PromoCode.Code)
+	}
+	return s
+}
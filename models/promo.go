@@ -0,0 +1,96 @@
+package models
+
+import (
+	"booking/dates"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DiscountKind is how a promo code takes money off a stay
+type DiscountKind string
+
+const (
+	// DiscountPercent takes Amount percent off the price of the stay
+	DiscountPercent DiscountKind = "percent"
+	// DiscountFixed takes Amount, in minor units, off the price of the stay
+	DiscountFixed DiscountKind = "fixed"
+)
+
+// PromoCode is a code guests type on the booking form for a discount. It can be redeemed on the days from
+// ValidFrom up to but not including ValidUntil, zero dates leaving the window open, for stays of RoomType,
+// every type when its ID is 0, lasting at least MinNights. Zero limits do not apply.
+type PromoCode struct {
+	ID         int
+	PropertyID int
+	Code       string
+	Kind       DiscountKind
+	Amount     int
+	ValidFrom  time.Time
+	ValidUntil time.Time
+	RoomType   RoomType
+	MinNights  int
+	// MaxUses caps the reservations booked with the code, MaxUsesPerGuest those of one email address
+	MaxUses         int
+	MaxUsesPerGuest int
+	Active          bool
+	// Uses is the number of reservations booked with the code and not cancelled
+	Uses      int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizePromoCode returns the code the way it is stored, codes are not case sensitive
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// DiscountOn returns how much the code takes off subtotal, never more than subtotal
+func (p PromoCode) DiscountOn(subtotal Money) Money {
+	var discount Money
+	switch p.Kind {
+	case DiscountPercent:
+		discount = subtotal * Money(p.Amount) / 100
+	case DiscountFixed:
+		discount = Money(p.Amount)
+	}
+	if discount > subtotal {
+		return subtotal
+	}
+	return discount
+}
+
+// Describe tells what the code takes off, e.g. "10% off" or "20.00 off"
+func (p PromoCode) Describe() string {
+	if p.Kind == DiscountPercent {
+		return fmt.Sprintf("%d%% off", p.Amount)
+	}
+	return fmt.Sprintf("%s off", Money(p.Amount))
+}
+
+// Check tells whether the code can be redeemed today for a stay in room type typeID. The error is a
+// ValidationError on promo_code. The limit per guest is only known when booking, see ErrPromoUsedUp.
+func (p PromoCode) Check(typeID int, stay dates.Range, today dates.Date) error {
+	switch {
+	case !p.Active:
+		return NewValidationError("promo_code", "This promo code is no longer available")
+	case !p.ValidFrom.IsZero() && today.Before(dates.Of(p.ValidFrom)):
+		return NewValidationError("promo_code", fmt.Sprintf("This promo code can be used from %s", dates.Of(p.ValidFrom)))
+	case !p.ValidUntil.IsZero() && !today.Before(dates.Of(p.ValidUntil)):
+		return NewValidationError("promo_code", "This promo code has expired")
+	case p.RoomType.ID != 0 && p.RoomType.ID != typeID:
+		return NewValidationError("promo_code", fmt.Sprintf("This promo code is only for the %s", p.RoomType.Name))
+	case p.MinNights > 0 && stay.Nights() < p.MinNights:
+		return NewValidationError("promo_code", fmt.Sprintf("This promo code needs a stay of at least %s",
+			plural(p.MinNights, "night", "nights")))
+	case p.MaxUses > 0 && p.Uses >= p.MaxUses:
+		return ErrPromoUsedUp
+	}
+	return nil
+}
+
+// ErrPromoUsedUp is returned when a promo code reached its limit of uses
+var ErrPromoUsedUp = NewValidationError("promo_code", "This promo code has been used up")
+
+// ErrPromoUsedByGuest is returned when a guest reached the limit of uses of a promo code per guest
+var ErrPromoUsedByGuest = NewValidationError("promo_code", "You have already used this promo code")
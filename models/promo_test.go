@@ -0,0 +1,125 @@
+package models
+
+import (
+	"booking/dates"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	for in, expected := range map[string]Money{"120": 12000, "120.5": 12050, "0,99": 99, " 7.05 ": 705} {
+		m, err := ParseMoney(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, m, in)
+	}
+	for _, in := range []string{"", "-5", "1.999", "ten"} {
+		_, err := ParseMoney(in)
+		assert.Error(t, err, in)
+	}
+	assert.Equal(t, "120.50", Money(12050).String())
+	assert.Equal(t, "-0.05", Money(-5).String())
+}
+
+func TestQuote_WithPromo(t *testing.T) {
+	start, _ := dates.Parse("2026-11-10")
+	q, err := NewQuote(RoomType{NightlyRate: 9550}, dates.Range{Start: start, End: start.AddDays(3)})
+	assert.NoError(t, err)
+	assert.Equal(t, Money(28650), q.Subtotal)
+	assert.Equal(t, q.Subtotal, q.Total)
+
+	tenPercent := q.WithPromo(PromoCode{Code: "WINTER10", Kind: DiscountPercent, Amount: 10})
+	assert.Equal(t, Money(2865), tenPercent.Discount)
+	assert.Equal(t, Money(25785), tenPercent.Total)
+	assert.Equal(t, "WINTER10", tenPercent.PromoCode.Code)
+
+	// a fixed discount never makes the stay cost less than nothing
+	free := q.WithPromo(PromoCode{Kind: DiscountFixed, Amount: 50000})
+	assert.Equal(t, q.Subtotal, free.Discount)
+	assert.Equal(t, Money(0), free.Total)
+
+	var res Reservation
+	res.Price(tenPercent)
+	assert.Equal(t, Money(25785), res.Total)
+	assert.Equal(t, "WINTER10", res.PromoCode.Code)
+
+	// a stay that does not last a night would be priced at nothing or less
+	for _, nights := range []int{0, -2} {
+		_, err := NewQuote(RoomType{NightlyRate: 9550}, dates.Range{Start: start, End: start.AddDays(nights)})
+		var validationErr *ValidationError
+		if assert.True(t, errors.As(err, &validationErr), nights) {
+			assert.Equal(t, "end_date", validationErr.Field)
+		}
+	}
+}
+
+func TestPromoCode_Check(t *testing.T) {
+	today, _ := dates.Parse("2026-10-22")
+	code := PromoCode{
+		Kind:       DiscountPercent,
+		Amount:     10,
+		ValidFrom:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		ValidUntil: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		RoomType:   RoomType{ID: 3, Name: "Standard Double"},
+		MinNights:  2,
+		MaxUses:    10,
+		Active:     true,
+	}
+	stay := func(nights int) dates.Range {
+		start, _ := dates.Parse("2026-12-01")
+		return dates.Range{Start: start, End: start.AddDays(nights)}
+	}
+	with := func(change func(*PromoCode)) PromoCode {
+		c := code
+		change(&c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		code    PromoCode
+		typeID  int
+		nights  int
+		today   dates.Date
+		message string
+	}{
+		{"allowed", code, 3, 2, today, ""},
+		{"inactive", with(func(c *PromoCode) { c.Active = false }), 3, 2, today, "This promo code is no longer available"},
+		{"not yet valid", code, 3, 2, today.AddDays(-30), "This promo code can be used from 2026-10-01"},
+		{"last day", code, 3, 2, today.AddDays(9), ""},
+		{"expired", code, 3, 2, today.AddDays(10), "This promo code has expired"},
+		{"other room type", code, 4, 2, today, "This promo code is only for the Standard Double"},
+		{"too short", code, 3, 1, today, "This promo code needs a stay of at least 2 nights"},
+		{"used up", with(func(c *PromoCode) { c.Uses = 10 }), 3, 2, today, "This promo code has been used up"},
+		{"open", PromoCode{Kind: DiscountFixed, Amount: 500, Active: true}, 4, 1, today, ""},
+	}
+
+	for _, test := range tests {
+		err := test.code.Check(test.typeID, stay(test.nights), test.today)
+		if test.message == "" {
+			assert.NoError(t, err, test.name)
+			continue
+		}
+		var validationErr *ValidationError
+		if assert.True(t, errors.As(err, &validationErr), test.name) {
+			assert.Equal(t, "promo_code", validationErr.Field, test.name)
+			assert.Equal(t, test.message, validationErr.Message, test.name)
+		}
+	}
+
+	assert.Equal(t, "10% off", code.Describe())
+	assert.Equal(t, "5.00 off", PromoCode{Kind: DiscountFixed, Amount: 500}.Describe())
+	assert.Equal(t, "WINTER10", NormalizePromoCode(" winter10 "))
+}
+
+func TestReservation_PriceSummary(t *testing.T) {
+	assert.Equal(t, "", Reservation{}.PriceSummary("EUR"))
+
+	res := Reservation{Subtotal: 19000, Total: 19000}
+	assert.Equal(t, "EUR 190.00", res.PriceSummary("EUR"))
+
+	res.Price(Quote{Subtotal: 19000, Discount: 1900, Total: 17100, PromoCode: PromoCode{Code: "WINTER10"}})
+	assert.Equal(t, "EUR 171.00 after 19.00 off with WINTER10", res.PriceSummary("EUR"))
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Property is a guesthouse owning rooms, with the settings used on its pages and emails
+type Property struct {
+	ID   int
+	Name string
+	// ContactEmail sends the emails of the property and receives its booking notifications
+	ContactEmail string
+	// TimeZone is an IANA name such as Europe/Paris, it decides which day it is at the property
+	TimeZone string
+	// Currency is an ISO 4217 code such as EUR
+	Currency string
+	// BrandColor is the color of the navigation bar as #rrggbb, empty for the default one
+	BrandColor string
+	LogoURL    string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Location returns the time zone of the property, nil when it has none or an unknown one
+func (p Property) Location() *time.Location {
+	if p.TimeZone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+type propertyKey struct{}
+
+type userPropertiesKey struct{}
+
+type roomTypesKey struct{}
+
+// WithProperty returns a copy of ctx working on property p, repository queries only see its rooms
+// and their reservations
+func WithProperty(ctx context.Context, p Property) context.Context {
+	return context.WithValue(ctx, propertyKey{}, p)
+}
+
+// PropertyFromContext returns the property of ctx, the zero Property when there is none, e.g. in
+// background jobs working on every property
+func PropertyFromContext(ctx context.Context) Property {
+	p, _ := ctx.Value(propertyKey{}).(Property)
+	return p
+}
+
+// WithUserProperties returns a copy of ctx recording the properties the logged in user may switch to
+func WithUserProperties(ctx context.Context, properties []Property) context.Context {
+	return context.WithValue(ctx, userPropertiesKey{}, properties)
+}
+
+// UserPropertiesFromContext returns the properties the logged in user may switch to
+func UserPropertiesFromContext(ctx context.Context) []Property {
+	properties, _ := ctx.Value(userPropertiesKey{}).([]Property)
+	return properties
+}
+
+// WithRoomTypes returns a copy of ctx recording the room types of the property shown in the Rooms menu
+func WithRoomTypes(ctx context.Context, types []RoomType) context.Context {
+	return context.WithValue(ctx, roomTypesKey{}, types)
+}
+
+// RoomTypesFromContext returns the room types of the property shown in the Rooms menu
+func RoomTypesFromContext(ctx context.Context) []RoomType {
+	types, _ := ctx.Value(roomTypesKey{}).([]RoomType)
+	return types
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"booking/dates"
+	"net/url"
+	"time"
+)
+
+// ReportPeriod is the range of nights a report covers, from From up to but excluding To
+type ReportPeriod struct {
+	Key   string    `json:"key"`
+	Label string    `json:"label"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+}
+
+// Nights is the number of nights in the period
+func (p ReportPeriod) Nights() int {
+	return dates.Nights(p.From, p.To)
+}
+
+// Query returns the query string parameters selecting the period
+func (p ReportPeriod) Query() url.Values {
+	q := url.Values{}
+	if p.Key != "custom" {
+		q.Set("period", p.Key)
+		return q
+	}
+	q.Set("from", p.From.Format("2006-01-02"))
+	q.Set("to", p.To.AddDate(0, 0, -1).Format("2006-01-02"))
+	return q
+}
+
+// RoomOccupancy is how many nights of a period a room was booked
+type RoomOccupancy struct {
+	RoomID   int    `json:"room_id"`
+	RoomName string `json:"room_name"`
+	// Nights booked by reservations
+	Nights int `json:"nights"`
+	// Blocked nights are taken off the nights the room could be sold
+	Blocked   int     `json:"blocked"`
+	Available int     `json:"available"`
+	Rate      float64 `json:"rate"`
+}
+
+func (o *RoomOccupancy) computeRate() {
+	o.Rate = 0
+	if o.Available > 0 {
+		o.Rate = float64(o.Nights) / float64(o.Available)
+	}
+}
+
+// OccupancyReport is the occupancy of every room and of the whole house over a period
+type OccupancyReport struct {
+	Period  ReportPeriod    `json:"period"`
+	Rooms   []RoomOccupancy `json:"rooms"`
+	Overall RoomOccupancy   `json:"overall"`
+}
+
+// NewOccupancyReport works out the available nights and rates from the booked and blocked nights of each room
+func NewOccupancyReport(period ReportPeriod, rooms []RoomOccupancy) OccupancyReport {
+	report := OccupancyReport{Period: period, Rooms: rooms}
+	report.Overall.RoomName = "All rooms"
+
+	for i := range report.Rooms {
+		room := &report.Rooms[i]
+		room.Available = period.Nights() - room.Blocked
+		if room.Available < 0 {
+			room.Available = 0
+		}
+		room.computeRate()
+
+		report.Overall.Nights += room.Nights
+		report.Overall.Blocked += room.Blocked
+		report.Overall.Available += room.Available
+	}
+	report.Overall.computeRate()
+
+	return report
+}
+
+// Movements counts the guests arriving and leaving over a range of days
+type Movements struct {
+	Arrivals   int `json:"arrivals"`
+	Departures int `json:"departures"`
+}
+
+// LeadTime counts the reservations made a range of days before arrival
+type LeadTime struct {
+	Label   string `json:"label"`
+	MinDays int    `json:"min_days"`
+	// MaxDays is -1 for the last, open ended range
+	MaxDays int `json:"max_days"`
+	Count   int `json:"count"`
+}
+
+// LeadTimes is a histogram of booking lead times
+type LeadTimes []LeadTime
+
+// NewLeadTimes returns the empty histogram shown on the dashboard
+func NewLeadTimes() LeadTimes {
+	return LeadTimes{
+		{Label: "Same day", MinDays: 0, MaxDays: 0},
+		{Label: "1-7 days", MinDays: 1, MaxDays: 7},
+		{Label: "8-30 days", MinDays: 8, MaxDays: 30},
+		{Label: "31-90 days", MinDays: 31, MaxDays: 90},
+		{Label: "Over 90 days", MinDays: 91, MaxDays: -1},
+	}
+}
+
+// Add counts n reservations booked days before arrival
+func (l LeadTimes) Add(days, n int) {
+	for i := range l {
+		if days >= l[i].MinDays && (l[i].MaxDays < 0 || days <= l[i].MaxDays) {
+			l[i].Count += n
+			return
+		}
+	}
+}
+
+// StayStats describes the reservations arriving in a period
+type StayStats struct {
+	Period ReportPeriod `json:"period"`
+	// Reservations and Nights leave out cancelled reservations
+	Reservations  int `json:"reservations"`
+	Nights        int `json:"nights"`
+	Cancellations int `json:"cancellations"`
+	NoShows       int `json:"no_shows"`
+	// Revenue is what the reservations are booked for, after the Discounts of their promo codes
+	Revenue   Money     `json:"revenue"`
+	Discounts Money     `json:"discounts"`
+	LeadTimes LeadTimes `json:"lead_times"`
+}
+
+// AverageStay is the mean number of nights per reservation
+func (s StayStats) AverageStay() float64 {
+	if s.Reservations == 0 {
+		return 0
+	}
+	return float64(s.Nights) / float64(s.Reservations)
+}
+
+// AverageDailyRate is the mean price paid for a night, after discounts
+func (s StayStats) AverageDailyRate() Money {
+	if s.Nights == 0 {
+		return 0
+	}
+	return s.Revenue / Money(s.Nights)
+}
+
+// CancellationRate is the share of the reservations for the period that were cancelled
+func (s StayStats) CancellationRate() float64 {
+	total := s.Reservations + s.Cancellations
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Cancellations) / float64(total)
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOccupancyReport(t *testing.T) {
+	period := ReportPeriod{
+		From: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, 30, period.Nights())
+
+	report := NewOccupancyReport(period, []RoomOccupancy{
+		{RoomID: 1, Nights: 15},
+		{RoomID: 2, Nights: 10, Blocked: 10},
+		{RoomID: 3, Blocked: 30},
+	})
+
+	assert.Equal(t, 30, report.Rooms[0].Available)
+	assert.Equal(t, 0.5, report.Rooms[0].Rate)
+	assert.Equal(t, 0.5, report.Rooms[1].Rate)
+	// a room blocked all period is not sellable and has no rate
+	assert.Equal(t, 0, report.Rooms[2].Available)
+	assert.Equal(t, 0.0, report.Rooms[2].Rate)
+
+	assert.Equal(t, 25, report.Overall.Nights)
+	assert.Equal(t, 50, report.Overall.Available)
+	assert.Equal(t, 0.5, report.Overall.Rate)
+}
+
+func TestLeadTimes_Add(t *testing.T) {
+	l := NewLeadTimes()
+	l.Add(0, 2)
+	l.Add(7, 1)
+	l.Add(8, 1)
+	l.Add(400, 3)
+
+	var counts []int
+	for _, b := range l {
+		counts = append(counts, b.Count)
+	}
+	assert.Equal(t, []int{2, 1, 1, 0, 3}, counts)
+}
+
+func TestStayStats(t *testing.T) {
+	assert.Equal(t, 0.0, StayStats{}.AverageStay())
+	assert.Equal(t, 0.0, StayStats{}.CancellationRate())
+
+	s := StayStats{Reservations: 4, Nights: 10, Cancellations: 1}
+	assert.Equal(t, 2.5, s.AverageStay())
+	assert.Equal(t, 0.2, s.CancellationRate())
+}
+
+func TestReportPeriod_Query(t *testing.T) {
+	assert.Equal(t, "period=week", ReportPeriod{Key: "week"}.Query().Encode())
+
+	custom := ReportPeriod{
+		Key:  "custom",
+		From: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 11, 8, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "from=2026-11-01&to=2026-11-07", custom.Query().Encode())
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReservationStatus is a step in the life of a reservation
+type ReservationStatus string
+
+const (
+	StatusPending    ReservationStatus = "pending"
+	StatusConfirmed  ReservationStatus = "confirmed"
+	StatusCheckedIn  ReservationStatus = "checked_in"
+	StatusCheckedOut ReservationStatus = "checked_out"
+	StatusCancelled  ReservationStatus = "cancelled"
+	StatusNoShow     ReservationStatus = "no_show"
+)
+
+// ReservationStatuses lists every status in lifecycle order
+var ReservationStatuses = []ReservationStatus{
+	StatusPending,
+	StatusConfirmed,
+	StatusCheckedIn,
+	StatusCheckedOut,
+	StatusCancelled,
+	StatusNoShow,
+}
+
+// reservationTransitions is the only place that decides which status may follow which
+var reservationTransitions = map[ReservationStatus][]ReservationStatus{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCheckedIn, StatusNoShow, StatusCancelled},
+	StatusCheckedIn: {StatusCheckedOut},
+}
+
+var statusLabels = map[ReservationStatus]string{
+	StatusPending:    "Pending",
+	StatusConfirmed:  "Confirmed",
+	StatusCheckedIn:  "Checked in",
+	StatusCheckedOut: "Checked out",
+	StatusCancelled:  "Cancelled",
+	StatusNoShow:     "No-show",
+}
+
+var statusActions = map[ReservationStatus]string{
+	StatusConfirmed:  "Confirm",
+	StatusCheckedIn:  "Check in",
+	StatusCheckedOut: "Check out",
+	StatusCancelled:  "Cancel",
+	StatusNoShow:     "Mark as no-show",
+}
+
+// ParseReservationStatus validates a status coming from user input
+func ParseReservationStatus(s string) (ReservationStatus, error) {
+	status := ReservationStatus(s)
+	if _, ok := statusLabels[status]; !ok {
+		return "", NewValidationError("status", fmt.Sprintf("unknown reservation status %q", s))
+	}
+	return status, nil
+}
+
+// Label is the name of the status shown to admins
+func (s ReservationStatus) Label() string {
+	return statusLabels[s]
+}
+
+// Action is the label of the button that moves a reservation into this status
+func (s ReservationStatus) Action() string {
+	return statusActions[s]
+}
+
+// Transitions lists the statuses a reservation may move to from s
+func (s ReservationStatus) Transitions() []ReservationStatus {
+	return reservationTransitions[s]
+}
+
+// CanTransitionTo tells whether a reservation in status s may move to status to
+func (s ReservationStatus) CanTransitionTo(to ReservationStatus) bool {
+	for _, next := range reservationTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns ErrConflict if a reservation in status s cannot move to status to
+func (s ReservationStatus) ValidateTransition(to ReservationStatus) error {
+	if !s.CanTransitionTo(to) {
+		return fmt.Errorf("%w: a %s reservation cannot become %s", ErrConflict, s.Label(), to.Label())
+	}
+	return nil
+}
+
+// Movable tells whether the stay of a reservation in status s can still change dates or room,
+// finished and cancelled stays are history
+func (s ReservationStatus) Movable() bool {
+	return s == StatusPending || s == StatusConfirmed || s == StatusCheckedIn
+}
+
+// StatusChangedAt returns when the reservation entered status, zero if it never did
+func (r Reservation) StatusChangedAt(status ReservationStatus) time.Time {
+	switch status {
+	case StatusPending:
+		return r.CreatedAt
+	case StatusConfirmed:
+		return r.ConfirmedAt
+	case StatusCheckedIn:
+		return r.CheckedInAt
+	case StatusCheckedOut:
+		return r.CheckedOutAt
+	case StatusCancelled:
+		return r.CancelledAt
+	case StatusNoShow:
+		return r.NoShowAt
+	}
+	return time.Time{}
+}
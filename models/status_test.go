@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ReservationStatus
+		allowed  bool
+	}{
+		{StatusPending, StatusConfirmed, true},
+		{StatusPending, StatusCancelled, true},
+		{StatusPending, StatusCheckedIn, false},
+		{StatusConfirmed, StatusCheckedIn, true},
+		{StatusConfirmed, StatusNoShow, true},
+		{StatusConfirmed, StatusCancelled, true},
+		{StatusConfirmed, StatusPending, false},
+		{StatusCheckedIn, StatusCheckedOut, true},
+		{StatusCheckedIn, StatusCancelled, false},
+		{StatusCheckedOut, StatusCheckedIn, false},
+		{StatusCancelled, StatusConfirmed, false},
+		{StatusNoShow, StatusCheckedIn, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.allowed, test.from.CanTransitionTo(test.to), "%s -> %s", test.from, test.to)
+
+		err := test.from.ValidateTransition(test.to)
+		if test.allowed {
+			assert.NoError(t, err, "%s -> %s", test.from, test.to)
+		} else {
+			assert.True(t, errors.Is(err, ErrConflict), "%s -> %s", test.from, test.to)
+		}
+	}
+}
+
+func TestParseReservationStatus(t *testing.T) {
+	for _, s := range ReservationStatuses {
+		got, err := ParseReservationStatus(string(s))
+		assert.NoError(t, err)
+		assert.Equal(t, s, got)
+		assert.NotEmpty(t, s.Label())
+	}
+
+	_, err := ParseReservationStatus("processed")
+	assert.True(t, errors.Is(err, ErrValidation))
+}
+
+func TestReservationStatus_Movable(t *testing.T) {
+	movable := map[ReservationStatus]bool{
+		StatusPending:   true,
+		StatusConfirmed: true,
+		StatusCheckedIn: true,
+	}
+	for _, s := range ReservationStatuses {
+		assert.Equal(t, movable[s], s.Movable(), "%s", s)
+	}
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"booking/dates"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays is a set of days of the week, a bit per day from sunday, the empty set means every day
+type Weekdays uint8
+
+// Has tells whether the set includes day, every set includes it when empty
+func (w Weekdays) Has(day time.Weekday) bool {
+	return w == 0 || w&(1<<uint(day)) != 0
+}
+
+// With returns the set including day as well
+func (w Weekdays) With(day time.Weekday) Weekdays {
+	return w | 1<<uint(day)
+}
+
+// String lists the days of the set, e.g. "Sat, Sun", or "every day"
+func (w Weekdays) String() string {
+	if w == 0 {
+		return "every day"
+	}
+	var days []string
+	// the week of the property starts on monday
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if w&(1<<uint(day)) != 0 {
+			days = append(days, day.String()[:3])
+		}
+	}
+	return strings.Join(days, ", ")
+}
+
+// StayRule restricts the stays guests may book on the public site. It applies to RoomType, every type when
+// its ID is 0, and to stays arriving (or leaving, for ClosedToDeparture) on the dates from StartDate up to
+// but not including EndDate that fall on Weekdays. Zero dates leave the period open and zero limits do not
+// apply. Staff booking from the admin pages are not held to the rules.
+type StayRule struct {
+	ID         int
+	PropertyID int
+	RoomType   RoomType
+	StartDate  time.Time
+	EndDate    time.Time
+	Weekdays   Weekdays
+	MinNights  int
+	MaxNights  int
+	// ClosedToArrival and ClosedToDeparture refuse stays arriving or leaving on the dates of the rule
+	ClosedToArrival   bool
+	ClosedToDeparture bool
+	// CutoffHour is the hour of the day at the property when same-day arrivals close, e.g. 18
+	CutoffHour int
+	// MaxAdvanceDays is how many days ahead of arrival stays may be booked at most
+	MaxAdvanceDays int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// appliesOn tells whether the rule covers the stays of room type typeID arriving or leaving on d
+func (r StayRule) appliesOn(typeID int, d dates.Date) bool {
+	if r.RoomType.ID != 0 && r.RoomType.ID != typeID {
+		return false
+	}
+	if !r.StartDate.IsZero() && d.Before(dates.Of(r.StartDate)) {
+		return false
+	}
+	if !r.EndDate.IsZero() && !d.Before(dates.Of(r.EndDate)) {
+		return false
+	}
+	return r.Weekdays.Has(d.Weekday())
+}
+
+// Describe lists what the rule restricts, for the admin pages
+func (r StayRule) Describe() string {
+	var parts []string
+	if r.MinNights > 0 {
+		parts = append(parts, fmt.Sprintf("at least %s", plural(r.MinNights, "night", "nights")))
+	}
+	if r.MaxNights > 0 {
+		parts = append(parts, fmt.Sprintf("at most %s", plural(r.MaxNights, "night", "nights")))
+	}
+	if r.ClosedToArrival {
+		parts = append(parts, "closed to arrival")
+	}
+	if r.ClosedToDeparture {
+		parts = append(parts, "closed to departure")
+	}
+	if r.CutoffHour > 0 {
+		parts = append(parts, fmt.Sprintf("same-day arrivals until %02d:00", r.CutoffHour))
+	}
+	if r.MaxAdvanceDays > 0 {
+		parts = append(parts, fmt.Sprintf("booked at most %s ahead", plural(r.MaxAdvanceDays, "day", "days")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StayRules are the rules of a property, every one that applies to a stay must accept it
+type StayRules []StayRule
+
+// Check tells whether guests may book a stay in room type typeID, now being the current time at the
+// property. The error is a ValidationError on start_date or end_date naming the first rule refusing the stay,
+// stays that do not last a night are always refused.
+func (rules StayRules) Check(typeID int, stay dates.Range, now time.Time) error {
+	today := dates.Of(now)
+	arrival, departure := stay.Start, stay.End
+
+	if stay.Nights() < 1 {
+		return NewValidationError("end_date", "Departure must be after arrival")
+	}
+	if arrival.Before(today) {
+		return NewValidationError("start_date", "Arrival cannot be in the past")
+	}
+
+	for _, r := range rules {
+		if !r.appliesOn(typeID, arrival) {
+			continue
+		}
+		switch {
+		case r.ClosedToArrival:
+			return NewValidationError("start_date", fmt.Sprintf("Arrivals are closed on %s", humanDay(arrival)))
+		case r.CutoffHour > 0 && arrival.Sub(today) == 0 && now.Hour() >= r.CutoffHour:
+			return NewValidationError("start_date", fmt.Sprintf("Arrivals for today can only be booked until %02d:00", r.CutoffHour))
+		case r.MaxAdvanceDays > 0 && arrival.Sub(today) > r.MaxAdvanceDays:
+			return NewValidationError("start_date", fmt.Sprintf("Stays can be booked at most %s ahead, arriving by %s",
+				plural(r.MaxAdvanceDays, "day", "days"), humanDay(today.AddDays(r.MaxAdvanceDays))))
+		case r.MinNights > 0 && stay.Nights() < r.MinNights:
+			return NewValidationError("end_date", fmt.Sprintf("Stays arriving on %s must last at least %s",
+				humanDay(arrival), plural(r.MinNights, "night", "nights")))
+		case r.MaxNights > 0 && stay.Nights() > r.MaxNights:
+			return NewValidationError("end_date", fmt.Sprintf("Stays arriving on %s can last at most %s",
+				humanDay(arrival), plural(r.MaxNights, "night", "nights")))
+		}
+	}
+
+	for _, r := range rules {
+		if r.ClosedToDeparture && r.appliesOn(typeID, departure) {
+			return NewValidationError("end_date", fmt.Sprintf("Departures are closed on %s", humanDay(departure)))
+		}
+	}
+
+	return nil
+}
+
+// humanDay formats a date for the messages of the rules, e.g. "Thu 24 Dec 2026"
+func humanDay(d dates.Date) string {
+	return d.Time().Format("Mon 02 Jan 2006")
+}
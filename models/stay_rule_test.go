@@ -0,0 +1,83 @@
+package models
+
+import (
+	"booking/dates"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStayRules_Check(t *testing.T) {
+	// a thursday afternoon at the property
+	now := time.Date(2026, 10, 22, 15, 30, 0, 0, time.UTC)
+	christmas := StayRule{
+		StartDate: time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2027, 1, 3, 0, 0, 0, 0, time.UTC),
+		MinNights: 3,
+	}
+	rules := StayRules{
+		christmas,
+		{Weekdays: Weekdays(0).With(time.Sunday), ClosedToArrival: true},
+		{Weekdays: Weekdays(0).With(time.Monday), ClosedToDeparture: true},
+		{RoomType: RoomType{ID: 4}, MaxNights: 7},
+		{CutoffHour: 15},
+		{MaxAdvanceDays: 365},
+	}
+	stay := func(start string, nights int) dates.Range {
+		d, _ := dates.Parse(start)
+		return dates.Range{Start: d, End: d.AddDays(nights)}
+	}
+
+	tests := []struct {
+		name    string
+		typeID  int
+		stay    dates.Range
+		field   string
+		message string
+	}{
+		{"allowed", 3, stay("2026-10-23", 2), "", ""},
+		{"past", 3, stay("2026-10-21", 2), "start_date", "Arrival cannot be in the past"},
+		{"empty", 3, stay("2026-10-23", 0), "end_date", "Departure must be after arrival"},
+		{"reversed", 3, stay("2026-10-23", -2), "end_date", "Departure must be after arrival"},
+		{"same day after cutoff", 3, stay("2026-10-22", 2), "start_date", "Arrivals for today can only be booked until 15:00"},
+		{"too far ahead", 3, stay("2027-10-23", 2), "start_date", "Stays can be booked at most 365 days ahead, arriving by Fri 22 Oct 2027"},
+		{"closed to arrival", 3, stay("2026-10-25", 2), "start_date", "Arrivals are closed on Sun 25 Oct 2026"},
+		{"closed to departure", 3, stay("2026-10-24", 2), "end_date", "Departures are closed on Mon 26 Oct 2026"},
+		{"minimum in period", 3, stay("2026-12-22", 2), "end_date", "Stays arriving on Tue 22 Dec 2026 must last at least 3 nights"},
+		{"minimum after period", 3, stay("2027-01-04", 2), "", ""},
+		{"maximum of other type", 3, stay("2026-11-03", 8), "", ""},
+		{"maximum of type", 4, stay("2026-11-03", 8), "end_date", "Stays arriving on Tue 03 Nov 2026 can last at most 7 nights"},
+	}
+
+	for _, test := range tests {
+		err := rules.Check(test.typeID, test.stay, now)
+		if test.field == "" {
+			assert.NoError(t, err, test.name)
+			continue
+		}
+		var validationErr *ValidationError
+		if assert.True(t, errors.As(err, &validationErr), test.name) {
+			assert.Equal(t, test.field, validationErr.Field, test.name)
+			assert.Equal(t, test.message, validationErr.Message, test.name)
+		}
+	}
+
+	// before the cutoff guests may still arrive today
+	assert.NoError(t, rules.Check(3, stay("2026-10-22", 2), now.Add(-time.Hour)))
+}
+
+func TestWeekdays(t *testing.T) {
+	weekend := Weekdays(0).With(time.Saturday).With(time.Sunday)
+	assert.True(t, weekend.Has(time.Sunday))
+	assert.False(t, weekend.Has(time.Monday))
+	assert.True(t, Weekdays(0).Has(time.Monday))
+	assert.Equal(t, "Sat, Sun", weekend.String())
+	assert.Equal(t, "every day", Weekdays(0).String())
+}
+
+func TestStayRule_Describe(t *testing.T) {
+	r := StayRule{MinNights: 2, ClosedToArrival: true, CutoffHour: 18}
+	assert.Equal(t, "at least 2 nights, closed to arrival, same-day arrivals until 18:00", r.Describe())
+}
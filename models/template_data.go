@@ -14,4 +14,10 @@ type TemplateData struct {
 	Error           string
 	Form            *form.Form
 	IsAuthenticated bool
+	// Property is the property the page belongs to
+	Property Property
+	// Properties are the properties the logged in user may switch to
+	Properties []Property
+	// RoomTypes are the room types of the property, listed in the Rooms menu of the public pages
+	RoomTypes []RoomType
 }
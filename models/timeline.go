@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of timeline entries
+const (
+	TimelineNote   = "note"
+	TimelineEmail  = "email"
+	TimelineStatus = "status"
+	TimelineEdit   = "edit"
+)
+
+// TimelineEntry is one event in the history of a reservation
+type TimelineEntry struct {
+	Kind     string
+	Time     time.Time
+	UserName string
+	Title    string
+	Body     string
+	// MailID and MailStatus are set for emails, to link to the outbox
+	MailID     int
+	MailStatus string
+}
+
+// auditTitles names the audited reservation changes that are not status transitions or edits
+var auditTitles = map[string]string{
+	"reservation.cancel":  "Cancelled",
+	"reservation.restore": "Restored",
+	"reservation.import":  "Imported",
+	"reservation.create":  "Booked by staff",
+	"reservation.move":    "Moved",
+}
+
+// ignoredFields change on every edit and are left out of the list of edited fields
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// NewTimeline merges the notes, emails and audited changes of a reservation, newest first
+func NewTimeline(notes []ReservationNote, mails []OutboxMail, changes []AuditEntry) []TimelineEntry {
+	var entries []TimelineEntry
+
+	for _, n := range notes {
+		entries = append(entries, TimelineEntry{
+			Kind:     TimelineNote,
+			Time:     n.CreatedAt,
+			UserName: n.UserName,
+			Title:    "Note",
+			Body:     n.Body,
+		})
+	}
+
+	for _, m := range mails {
+		entries = append(entries, TimelineEntry{
+			Kind:       TimelineEmail,
+			Time:       m.CreatedAt,
+			Title:      "Email to " + m.To,
+			Body:       m.Subject,
+			MailID:     m.ID,
+			MailStatus: m.Status,
+		})
+	}
+
+	for _, c := range changes {
+		entry := TimelineEntry{Time: c.CreatedAt, UserName: c.UserName}
+		switch c.Action {
+		case "reservation.status":
+			entry.Kind = TimelineStatus
+			entry.Title = ReservationStatus(jsonField(c.After, "status")).Label()
+		case "reservation.update":
+			entry.Kind = TimelineEdit
+			entry.Title = "Details edited"
+			entry.Body = "Changed " + strings.Join(ChangedFields(c.Before, c.After), ", ")
+		default:
+			title, ok := auditTitles[c.Action]
+			if !ok {
+				continue
+			}
+			entry.Kind = TimelineStatus
+			entry.Title = title
+			switch c.Action {
+			case "reservation.cancel":
+				entry.Body = jsonField(c.After, "cancel_reason")
+			case "reservation.move":
+				entry.Body = "Changed " + strings.Join(ChangedFields(c.Before, c.After), ", ")
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	return entries
+}
+
+// ChangedFields lists the fields whose value differs between two JSON snapshots, as words
+func ChangedFields(before, after string) []string {
+	var b, a map[string]interface{}
+	json.Unmarshal([]byte(before), &b)
+	json.Unmarshal([]byte(after), &a)
+
+	var fields []string
+	for key, value := range a {
+		if ignoredFields[key] || reflect.DeepEqual(b[key], value) {
+			continue
+		}
+		fields = append(fields, strings.ReplaceAll(key, "_", " "))
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// jsonField returns a string field of a JSON snapshot, empty if it is missing
+func jsonField(data, field string) string {
+	var m map[string]interface{}
+	json.Unmarshal([]byte(data), &m)
+	s, _ := m[field].(string)
+	return s
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTimeline(t *testing.T) {
+	at := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	entries := NewTimeline(
+		[]ReservationNote{{Body: "Late arrival", UserName: "Khanh Nguyen", CreatedAt: at.Add(3 * time.Hour)}},
+		[]OutboxMail{{ID: 5, To: "guest@example.com", Subject: "Reservation Confirmation", Status: MailSent, CreatedAt: at}},
+		[]AuditEntry{
+			{Action: "reservation.status", After: `{"status":"confirmed"}`, CreatedAt: at.Add(time.Hour)},
+			{Action: "reservation.update", Before: `{"email":"a@example.com","phone":"1","updated_at":"x"}`,
+				After: `{"email":"b@example.com","phone":"1","updated_at":"y"}`, CreatedAt: at.Add(2 * time.Hour)},
+			{Action: "reservation.cancel", After: `{"cancel_reason":"guest called"}`, CreatedAt: at.Add(4 * time.Hour)},
+			{Action: "block.create", CreatedAt: at.Add(5 * time.Hour)},
+		},
+	)
+
+	var kinds, titles []string
+	for _, e := range entries {
+		kinds = append(kinds, e.Kind)
+		titles = append(titles, e.Title)
+	}
+	// newest first, unrelated actions are left out
+	assert.Equal(t, []string{TimelineStatus, TimelineNote, TimelineEdit, TimelineStatus, TimelineEmail}, kinds)
+	assert.Equal(t, []string{"Cancelled", "Note", "Details edited", "Confirmed", "Email to guest@example.com"}, titles)
+	assert.Equal(t, "guest called", entries[0].Body)
+	assert.Equal(t, "Changed email", entries[2].Body)
+	assert.Equal(t, 5, entries[4].MailID)
+}
+
+func TestNewTimeline_Move(t *testing.T) {
+	entries := NewTimeline(nil, nil, []AuditEntry{
+		{Action: "reservation.move", Before: `{"room_id":1,"start_date":"2026-11-01","end_date":"2026-11-03"}`,
+			After: `{"room_id":2,"start_date":"2026-11-01","end_date":"2026-11-04"}`},
+	})
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Moved", entries[0].Title)
+	assert.Equal(t, "Changed end date, room id", entries[0].Body)
+}
+
+func TestChangedFields(t *testing.T) {
+	assert.Equal(t, []string{"first name", "phone"},
+		ChangedFields(`{"first_name":"Jo","phone":"1","email":"a"}`, `{"first_name":"John","phone":"2","email":"a"}`))
+	assert.Empty(t, ChangedFields(`{"updated_at":"x"}`, `{"updated_at":"y"}`))
+}
@@ -46,11 +46,17 @@ func Add(a, b int) int {
 	return a + b
 }
 
+// Percent formats a ratio such as an occupancy rate
+func Percent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+}
+
 var functions = template.FuncMap{
 	"humanDate":  HumanDate,
 	"formatDate": FormatDate,
 	"iterate":    Iterate,
 	"add":        Add,
+	"percent":    Percent,
 }
 
 func AddDefaultData(td *models.TemplateData, r *http.Request) *models.TemplateData {
@@ -61,6 +67,9 @@ func AddDefaultData(td *models.TemplateData, r *http.Request) *models.TemplateDa
 	if app.Session.Exists(r.Context(), "user_id") {
 		td.IsAuthenticated = true
 	}
+	td.Property = models.PropertyFromContext(r.Context())
+	td.Properties = models.UserPropertiesFromContext(r.Context())
+	td.RoomTypes = models.RoomTypesFromContext(r.Context())
 	return td
 }
 
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Audited actions
+const (
+	AuditReservationUpdate  = "reservation.update"
+	AuditReservationStatus  = "reservation.status"
+	AuditReservationCancel  = "reservation.cancel"
+	AuditReservationRestore = "reservation.restore"
+	AuditReservationPurge   = "reservation.purge"
+	AuditReservationImport  = "reservation.import"
+	AuditReservationCreate  = "reservation.create"
+	AuditReservationMove    = "reservation.move"
+	AuditBlockCreate        = "block.create"
+	AuditBlockDelete        = "block.delete"
+)
+
+// Audited entities, the names are the tables they are snapshotted from
+const (
+	entityReservation     = "reservations"
+	entityRoomRestriction = "room_restrictions"
+)
+
+// defaultAuditLimit caps the number of audit rows loaded at once
+const defaultAuditLimit = 500
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx recording which user performs the changes
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the acting user id, 0 if the change is not made by a logged in user
+func ActorFromContext(ctx context.Context) int {
+	id, _ := ctx.Value(actorKey{}).(int)
+	return id
+}
+
+// inTx runs fn in a transaction, committing only if fn succeeds
+func (p *postgressDBRepo) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.DB.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// snapshot returns the row as JSON, nil when the row does not exist
+func snapshot(ctx context.Context, tx *sql.Tx, table string, id int) (*string, error) {
+	// table is one of the entity constants above, never user input
+	query := fmt.Sprintf("select row_to_json(t)::text from %s t where t.id = $1", table)
+
+	var data string
+	err := tx.QueryRowContext(ctx, query, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// audit appends an entry for the actor and the property in ctx within tx
+func audit(ctx context.Context, tx *sql.Tx, action, table string, id int, before, after *string) error {
+	var userID sql.NullInt64
+	if actor := ActorFromContext(ctx); actor > 0 {
+		userID = sql.NullInt64{Int64: int64(actor), Valid: true}
+	}
+
+	var propertyID sql.NullInt64
+	if property := models.PropertyFromContext(ctx).ID; property > 0 {
+		propertyID = sql.NullInt64{Int64: int64(property), Valid: true}
+	}
+
+	query := `insert into audit_log (user_id, action, entity_type, entity_id, before_data, after_data, created_at, property_id)
+			  values ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7, $8)`
+
+	_, err := tx.ExecContext(ctx, query, userID, action, table, id, before, after, time.Now(), propertyID)
+	return err
+}
+
+// auditedChange snapshots the row before and after change and records both in the audit trail,
+// all in one transaction. change returns the id of the affected row. A row of another property than
+// the one of ctx is not found.
+func (p *postgressDBRepo) auditedChange(ctx context.Context, action, table string, id int, change func(tx *sql.Tx) (int, error)) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		if id > 0 {
+			if err := checkProperty(ctx, tx, table, id); err != nil {
+				return err
+			}
+		}
+
+		before, err := snapshot(ctx, tx, table, id)
+		if err != nil {
+			return err
+		}
+
+		id, err := change(tx)
+		if err != nil {
+			return err
+		}
+
+		after, err := snapshot(ctx, tx, table, id)
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, tx, action, table, id, before, after)
+	})
+}
+
+func (p *postgressDBRepo) AuditLog(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if property := models.PropertyFromContext(ctx).ID; property > 0 {
+		add("a.property_id = $%d", property)
+	}
+	if f.UserID > 0 {
+		add("a.user_id = $%d", f.UserID)
+	}
+	if f.Action != "" {
+		add("a.action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("a.entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID > 0 {
+		add("a.entity_id = $%d", f.EntityID)
+	}
+	if !f.From.IsZero() {
+		add("a.created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("a.created_at < $%d", f.To)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	args = append(args, limit)
+
+	query := `
+		select a.id, coalesce(a.user_id, 0), coalesce(u.first_name || ' ' || u.last_name, ''),
+			a.action, a.entity_type, a.entity_id,
+			coalesce(a.before_data::text, ''), coalesce(a.after_data::text, ''), a.created_at
+		from audit_log a
+		left join users u on (u.id = a.user_id)
+	`
+	if len(where) > 0 {
+		query += " where " + strings.Join(where, " and ")
+	}
+	query += fmt.Sprintf(" order by a.created_at desc, a.id desc limit $%d", len(args))
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		err = rows.Scan(
+			&e.ID,
+			&e.UserID,
+			&e.UserName,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityID,
+			&e.Before,
+			&e.After,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
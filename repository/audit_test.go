@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"booking/config"
+	"booking/models"
+	sqldriver "booking/sql_driver"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPostgresRepo(t *testing.T) (DatabaseRepo, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewPostgresRepo(&config.AppConfig{}, &sqldriver.DB{SQL: db}), mock
+}
+
+func TestUpdateReservation_Audited(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := WithActor(context.Background(), 7)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"first_name":"Khanh"}`))
+	mock.ExpectExec("update reservations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"first_name":"John"}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditReservationUpdate, "reservations", 3, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.UpdateReservation(ctx, models.Reservation{ID: 3, FirstName: "John"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelReservation_RollsBackWithoutAudit(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}))
+	mock.ExpectQuery("select status from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	mock.ExpectRollback()
+
+	_, err := repo.CancelReservation(context.Background(), 3, "", time.Now())
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreReservation_Conflict(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":3}`))
+	mock.ExpectQuery("select room_id, start_date, end_date, status, confirmed_at from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id", "start_date", "end_date", "status", "confirmed_at"}).
+			AddRow(1, start, start.AddDate(0, 0, 2), "cancelled", nil))
+	mock.ExpectQuery("select id from rooms").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("select count").WithArgs(1, start, start.AddDate(0, 0, 2), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := repo.RestoreReservation(context.Background(), 3)
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestActorFromContext(t *testing.T) {
+	assert.Equal(t, 0, ActorFromContext(context.Background()))
+	assert.Equal(t, 7, ActorFromContext(WithActor(context.Background(), 7)))
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// lockBookableRoom locks the room a stay is booked into, a missing room is a validation error of the form
+func lockBookableRoom(ctx context.Context, tx *sql.Tx, roomID int) error {
+	err := lockRoom(ctx, tx, roomID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.NewValidationError("room_id", fmt.Sprintf("room %d does not exist", roomID))
+	}
+	return err
+}
+
+// CreateReservation books a room on behalf of a guest, in the status of res. Staff are not held to the rules
+// of the booking form, but the room must be free: an overlapping reservation or block is an ErrConflict.
+func (p *postgressDBRepo) CreateReservation(ctx context.Context, res models.Reservation) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockBookableRoom(ctx, tx, res.RoomID); err != nil {
+			return err
+		}
+
+		taken, err := roomTaken(ctx, tx, res.RoomID, res.StartDate, res.EndDate, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: the room is already booked or blocked for these dates", models.ErrConflict)
+		}
+
+		return insertReservation(ctx, tx, &res, AuditReservationCreate)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// MoveReservation changes the room and dates of a reservation together with its room restriction.
+// The new stay may overlap the old one, but not any other reservation or block of the room.
+func (p *postgressDBRepo) MoveReservation(ctx context.Context, id, roomID int, start, end time.Time) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return p.auditedChange(ctx, AuditReservationMove, entityReservation, id, func(tx *sql.Tx) (int, error) {
+		var status models.ReservationStatus
+		err := tx.QueryRowContext(ctx, `select status from reservations where id = $1 for update`, id).Scan(&status)
+		if err != nil {
+			return id, mapError(err)
+		}
+		if !status.Movable() {
+			return id, models.NewValidationError("", fmt.Sprintf("a %s reservation cannot be moved", strings.ToLower(status.Label())))
+		}
+
+		if err := lockBookableRoom(ctx, tx, roomID); err != nil {
+			return id, err
+		}
+
+		taken, err := roomTaken(ctx, tx, roomID, start, end, id)
+		if err != nil {
+			return id, err
+		}
+		if taken {
+			return id, fmt.Errorf("%w: the room is already booked or blocked for these dates", models.ErrConflict)
+		}
+
+		now := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			update reservations set room_id = $1, start_date = $2, end_date = $3, updated_at = $4
+			where id = $5`, roomID, start, end, now, id)
+		if err != nil {
+			return id, mapError(err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			update room_restrictions set room_id = $1, start_date = $2, end_date = $3, updated_at = $4
+			where reservation_id = $5`, roomID, start, end, now, id)
+		return id, mapError(err)
+	})
+}
+
+// CreateBlock keeps a room from being booked from start to end, e.g. for maintenance.
+// Like reservations, blocks cannot overlap anything else booked or blocked in the room.
+func (p *postgressDBRepo) CreateBlock(ctx context.Context, roomID int, start, end time.Time) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var blockID int
+	err := p.auditedChange(ctx, AuditBlockCreate, entityRoomRestriction, 0, func(tx *sql.Tx) (int, error) {
+		if err := lockBookableRoom(ctx, tx, roomID); err != nil {
+			return 0, err
+		}
+
+		taken, err := roomTaken(ctx, tx, roomID, start, end, 0)
+		if err != nil {
+			return 0, err
+		}
+		if taken {
+			return 0, fmt.Errorf("%w: the room is already booked or blocked for these dates", models.ErrConflict)
+		}
+
+		now := time.Now()
+		err = tx.QueryRowContext(ctx, `
+			insert into room_restrictions (start_date, end_date, room_id, restriction_id, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $5) returning id`,
+			start, end, roomID, 2, now).Scan(&blockID)
+		return blockID, mapError(err)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return blockID, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReservation(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	res := models.Reservation{
+		FirstName: "Khanh",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 1),
+		RoomID:    2,
+		Status:    models.StatusPending,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("select count").WithArgs(2, start, start.AddDate(0, 0, 1), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into reservations").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+	mock.ExpectExec("insert into room_restrictions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(41).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":41}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditReservationCreate, "reservations", 41, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	id, err := repo.CreateReservation(context.Background(), res)
+	assert.NoError(t, err)
+	assert.Equal(t, 41, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateReservation_Conflict(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	res := models.Reservation{StartDate: start, EndDate: start.AddDate(0, 0, 1), RoomID: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("select count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	_, err := repo.CreateReservation(context.Background(), res)
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// a room that does not exist is reported on the form field
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateReservation(context.Background(), res)
+	var verr *models.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "room_id", verr.Field)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveReservation(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":3,"room_id":1}`))
+	mock.ExpectQuery("select status from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("confirmed"))
+	mock.ExpectQuery("select id from rooms").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	// the reservation's own restriction does not count as a clash
+	mock.ExpectQuery("select count").WithArgs(2, start, end, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("update reservations set room_id").WithArgs(2, start, end, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("update room_restrictions set room_id").WithArgs(2, start, end, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":3,"room_id":2}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditReservationMove, "reservations", 3, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.MoveReservation(context.Background(), 3, 2, start, end)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveReservation_NotMovable(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":3}`))
+	mock.ExpectQuery("select status from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("checked_out"))
+	mock.ExpectRollback()
+
+	err := repo.MoveReservation(context.Background(), 3, 2, start, start.AddDate(0, 0, 1))
+	assert.True(t, errors.Is(err, models.ErrValidation))
+	assert.EqualError(t, err, "a checked out reservation cannot be moved")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateBlock(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 4)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(0).WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}))
+	mock.ExpectQuery("select id from rooms").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("select count").WithArgs(1, start, end, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into room_restrictions").WithArgs(start, end, 1, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery("select row_to_json").WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":12}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditBlockCreate, "room_restrictions", 12, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	id, err := repo.CreateBlock(context.Background(), 1, start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// blocks cannot cover a reservation
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(0).WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}))
+	mock.ExpectQuery("select id from rooms").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("select count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateBlock(context.Background(), 1, start, end)
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
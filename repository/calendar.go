@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"time"
+)
+
+// RestrictionsInRange returns the reservations and blocks of every room overlapping the days from from up to
+// but not including to, ordered by room and start. Reservations come with their guest name and status.
+func (p *postgressDBRepo) RestrictionsInRange(ctx context.Context, from, to time.Time) ([]models.RoomRestriction, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := inProperty(ctx, "rr.room_id", []interface{}{from, to})
+	query := `
+		select rr.id, coalesce(rr.reservation_id, 0), rr.restriction_id, rr.room_id, rr.start_date, rr.end_date,
+			coalesce(r.first_name, ''), coalesce(r.last_name, ''), coalesce(r.status, '')
+		from room_restrictions rr
+		left join reservations r on (r.id = rr.reservation_id)
+		where ` + overlaps("rr.", 1, 2) + ` and ` + cond + `
+		order by rr.room_id, rr.start_date`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var restrictions []models.RoomRestriction
+	for rows.Next() {
+		var rs models.RoomRestriction
+		err := rows.Scan(
+			&rs.ID,
+			&rs.ReservationID,
+			&rs.RestrictionID,
+			&rs.RoomID,
+			&rs.StartDate,
+			&rs.EndDate,
+			&rs.Reservation.FirstName,
+			&rs.Reservation.LastName,
+			&rs.Reservation.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rs.Reservation.ID = rs.ReservationID
+		restrictions = append(restrictions, rs)
+	}
+
+	return restrictions, rows.Err()
+}
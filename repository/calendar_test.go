@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrictionsInRange(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	rows := sqlmock.NewRows([]string{"id", "reservation_id", "restriction_id", "room_id", "start_date", "end_date",
+		"first_name", "last_name", "status"}).
+		AddRow(7, 3, 1, 1, from, from.AddDate(0, 0, 2), "Khanh", "Nguyen", "confirmed").
+		AddRow(8, 0, 2, 2, from.AddDate(0, 0, 5), from.AddDate(0, 0, 6), "", "", "")
+	mock.ExpectQuery(`from room_restrictions rr left join reservations r .* where rr.start_date < \$2 and rr.end_date > \$1`).
+		WithArgs(from, to).WillReturnRows(rows)
+
+	restrictions, err := repo.RestrictionsInRange(context.Background(), from, to)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Len(t, restrictions, 2)
+	assert.Equal(t, 3, restrictions[0].Reservation.ID)
+	assert.Equal(t, "Khanh", restrictions[0].Reservation.FirstName)
+	assert.Equal(t, models.StatusConfirmed, restrictions[0].Reservation.Status)
+	assert.Equal(t, 0, restrictions[1].ReservationID)
+}
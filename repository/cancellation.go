@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"time"
+)
+
+// cancellationPolicyColumns is the select list of cancellation policies read by scanCancellationPolicy
+const cancellationPolicyColumns = `id, property_id, name, free_days, penalty_percent, non_refundable, created_at,
+	updated_at`
+
+// scanCancellationPolicy reads a row selected with cancellationPolicyColumns
+func scanCancellationPolicy(row rowScanner, c *models.CancellationPolicy) error {
+	return row.Scan(&c.ID, &c.PropertyID, &c.Name, &c.FreeDays, &c.PenaltyPercent, &c.NonRefundable, &c.CreatedAt,
+		&c.UpdatedAt)
+}
+
+// CancellationPolicies returns the cancellation policies of the property, by name
+func (p *postgressDBRepo) CancellationPolicies(ctx context.Context) ([]models.CancellationPolicy, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "property_id", nil)
+	rows, err := p.DB.SQL.QueryContext(ctx,
+		"select "+cancellationPolicyColumns+" from cancellation_policies where "+cond+" order by name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []models.CancellationPolicy
+	for rows.Next() {
+		var c models.CancellationPolicy
+		if err := scanCancellationPolicy(rows, &c); err != nil {
+			return nil, err
+		}
+		policies = append(policies, c)
+	}
+
+	return policies, rows.Err()
+}
+
+// GetCancellationPolicy returns a cancellation policy of the property
+func (p *postgressDBRepo) GetCancellationPolicy(ctx context.Context, id int) (models.CancellationPolicy, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var c models.CancellationPolicy
+	cond, args := ofProperty(ctx, "property_id", []interface{}{id})
+	row := p.DB.SQL.QueryRowContext(ctx,
+		"select "+cancellationPolicyColumns+" from cancellation_policies where id = $1 and "+cond, args...)
+	return c, mapError(scanCancellationPolicy(row, &c))
+}
+
+// CreateCancellationPolicy adds a cancellation policy to the property of ctx, ErrConflict when the property
+// has a policy of that name already
+func (p *postgressDBRepo) CreateCancellationPolicy(ctx context.Context, c models.CancellationPolicy) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		insert into cancellation_policies (property_id, name, free_days, penalty_percent, non_refundable,
+			created_at, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $6) returning id`
+
+	var id int
+	err := p.DB.SQL.QueryRowContext(ctx, query, models.PropertyFromContext(ctx).ID, c.Name, c.FreeDays,
+		c.PenaltyPercent, c.NonRefundable, time.Now()).Scan(&id)
+	return id, mapError(err)
+}
+
+// DeleteCancellationPolicy removes a cancellation policy of the property. Its room types are booked with free
+// cancellation from then on, reservations keep the terms they were booked on.
+func (p *postgressDBRepo) DeleteCancellationPolicy(ctx context.Context, id int) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "property_id", []interface{}{id})
+	return expectAffected(p.DB.SQL.ExecContext(ctx, "delete from cancellation_policies where id = $1 and "+cond, args...))
+}
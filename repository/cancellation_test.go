@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCancellationPolicy(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+
+	mock.ExpectQuery("insert into cancellation_policies").
+		WithArgs(2, "Flexible", 7, 50, false, sqlmock.AnyArg()).
+		WillReturnError(pgx.PgError{Code: pgUniqueViolation})
+
+	_, err := repo.CreateCancellationPolicy(ctx, models.CancellationPolicy{Name: "Flexible", FreeDays: 7,
+		PenaltyPercent: 50})
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelReservation_RecordsPenalty(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	arrival := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"status":"confirmed"}`))
+	mock.ExpectQuery("select status from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.StatusConfirmed))
+	mock.ExpectExec("update reservations set status").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("select start_date, total, cancel_free_days").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"start_date", "total", "cancel_free_days", "cancel_penalty_percent",
+			"cancel_non_refundable"}).AddRow(arrival, 21600, 7, 50, false))
+	mock.ExpectExec("update reservations set cancel_reason").
+		WithArgs("guest called", models.Money(10800), models.Money(10800), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("delete from room_restrictions").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"status":"cancelled"}`))
+	mock.ExpectExec("insert into audit_log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// five days before arrival is past the free week
+	c, err := repo.CancelReservation(context.Background(), 3, "guest called", arrival.AddDate(0, 0, -5))
+	assert.NoError(t, err)
+	assert.Equal(t, models.Cancellation{Penalty: 10800, Refund: 10800}, c)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,15 +2,22 @@ package repository
 
 import (
 	"booking/config"
+	"booking/dates"
 	"booking/models"
 	sqldriver "booking/sql_driver"
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// defaultQueryTimeout is used when the app config does not set DBTimeout
+const defaultQueryTimeout = 3 * time.Second
+
 type postgressDBRepo struct {
 	App *config.AppConfig
 	DB  *sqldriver.DB
@@ -22,12 +29,32 @@ func NewPostgresRepo(a *config.AppConfig, db *sqldriver.DB) DatabaseRepo {
 		DB:  db,
 	}
 }
-func (p *postgressDBRepo) AllUsers() bool {
+
+// withTimeout bounds a query by the configured timeout on top of the caller's context,
+// so queries stop as soon as the client goes away
+func (p *postgressDBRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := defaultQueryTimeout
+	if p.App != nil && p.App.DBTimeout > 0 {
+		timeout = p.App.DBTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// overlaps is the condition selecting the rows of alias (with its dot, or empty) whose stay shares a night
+// with the stay from the date in parameter number start up to the one in end. This is the one overlap rule
+// of the application and every query looking for clashes uses it: stays run from their arrival up to but
+// not including their departure, so the day of departure is free for the next guest to arrive and two
+// stays clash when each arrives before the other leaves.
+func overlaps(alias string, start, end int) string {
+	return fmt.Sprintf("%[1]sstart_date < $%[3]d and %[1]send_date > $%[2]d", alias, start, end)
+}
+
+func (p *postgressDBRepo) AllUsers(ctx context.Context) bool {
 	return true
 }
 
-func (p *postgressDBRepo) InsertReservation(res models.Reservation) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) InsertReservation(ctx context.Context, res models.Reservation) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	stmt := `insert into reservations (first_name, last_name, email, phone, start_date, end_date, room_id, created_at, updated_at)
@@ -45,11 +72,11 @@ func (p *postgressDBRepo) InsertReservation(res models.Reservation) (int, error)
 		time.Now(),
 		time.Now()).Scan(&newID)
 
-	return newID, err
+	return newID, mapError(err)
 }
 
-func (p *postgressDBRepo) InsertRoomRestriction(r models.RoomRestriction) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) InsertRoomRestriction(ctx context.Context, r models.RoomRestriction) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	stmt := `insert into room_restrictions (start_date, end_date, room_id, reservation_id, created_at, updated_at, restriction_id) 
@@ -64,49 +91,52 @@ func (p *postgressDBRepo) InsertRoomRestriction(r models.RoomRestriction) (int,
 		time.Now(),
 		r.RestrictionID)
 
-	return 0, err
+	return 0, mapError(err)
 }
 
-func (p *postgressDBRepo) SearchAvailabilityByDatesByRoomID(roomID int, start, end time.Time) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) SearchAvailabilityByDatesByRoomID(ctx context.Context, roomID int, start, end time.Time) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
+	// rooms of other properties are never available
+	cond, args := inProperty(ctx, "id", []interface{}{roomID, start, end})
 	query := `
-		select 
-			count(id)
-		from
-			room_restrictions
-		where 
-			room_id = $1 
-			and $2 <= end_date and $3 >= start_date;
-	`
+		select
+			exists (select 1 from rooms where id = $1 and ` + cond + `)
+			and not exists (select 1 from room_restrictions where room_id = $1 and ` + overlaps("", 2, 3) + `)`
 
-	var numRows int
-	err := p.DB.SQL.QueryRowContext(ctx, query, roomID, start, end).Scan(&numRows)
+	var available bool
+	err := p.DB.SQL.QueryRowContext(ctx, query, args...).Scan(&available)
 
 	if err != nil {
 		return false, err
 	}
 
-	return numRows == 0, nil
+	return available, nil
 }
 
-func (p *postgressDBRepo) SearchAvailabilityForAllRooms(start, end time.Time) ([]models.Room, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// SearchAvailabilityForAllRooms returns the rooms of the property free for the whole stay from start to end
+// whose type sleeps guests, 0 guests returns them whatever their size
+func (p *postgressDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time, guests int) ([]models.Room, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var rooms []models.Room
 
+	cond, args := inProperty(ctx, "r.id", []interface{}{start, end, guests})
 	query := `
 	select 
 		r.id, r.room_name
 	from 
 		rooms r
+		join room_types t on (t.id = r.room_type_id)
 	where 
-		r.id not in (select room_id from room_restrictions rr where $1 < rr.end_date and $2 > rr.start_date)
+		` + cond + `
+		and t.max_occupancy >= $3
+		and r.id not in (select room_id from room_restrictions rr where ` + overlaps("rr.", 1, 2) + `)
 	`
 
-	rows, err := p.DB.SQL.QueryContext(ctx, query, start, end)
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -129,27 +159,30 @@ func (p *postgressDBRepo) SearchAvailabilityForAllRooms(start, end time.Time) ([
 	return rooms, nil
 }
 
-func (p *postgressDBRepo) GetRoomByID(id int) (models.Room, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) GetRoomByID(ctx context.Context, id int) (models.Room, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var room models.Room
 
-	query := `select id, room_name, created_at, updated_at from rooms where id = $1`
+	cond, args := inProperty(ctx, "id", []interface{}{id})
+	query := `select id, property_id, room_type_id, room_name, created_at, updated_at from rooms where id = $1 and ` + cond
 
-	row := p.DB.SQL.QueryRowContext(ctx, query, id)
+	row := p.DB.SQL.QueryRowContext(ctx, query, args...)
 	err := row.Scan(
 		&room.ID,
+		&room.PropertyID,
+		&room.RoomTypeID,
 		&room.RoomName,
 		&room.CreatedAt,
 		&room.UpdatedAt,
 	)
 
-	return room, err
+	return room, mapError(err)
 }
 
-func (p *postgressDBRepo) GetUserByID(id int) (models.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	query := `select id, first_name, last_name, email, password, acces_level, created_at, updated_at
@@ -168,14 +201,14 @@ func (p *postgressDBRepo) GetUserByID(id int) (models.User, error) {
 		&u.UpdatedAt)
 
 	if err != nil {
-		return models.User{}, err
+		return models.User{}, mapError(err)
 	}
 
 	return u, nil
 }
 
-func (p *postgressDBRepo) UpdateUser(u models.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) UpdateUser(ctx context.Context, u models.User) error {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	query := `
@@ -192,8 +225,8 @@ func (p *postgressDBRepo) UpdateUser(u models.User) error {
 	return err
 }
 
-func (p *postgressDBRepo) Authenticate(email, testPassword string) (int, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) Authenticate(ctx context.Context, email, testPassword string) (int, string, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var id int
@@ -202,13 +235,15 @@ func (p *postgressDBRepo) Authenticate(email, testPassword string) (int, string,
 	row := p.DB.SQL.QueryRowContext(ctx, "select id, password from users where email = $1", email)
 
 	err := row.Scan(&id, &hashedPassword)
-	if err != nil {
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("%w: unknown email", models.ErrUnauthorized)
+	} else if err != nil {
 		return id, "", err
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(testPassword))
 	if err == bcrypt.ErrMismatchedHashAndPassword {
-		return 0, "", errors.New("incorrect password")
+		return 0, "", fmt.Errorf("%w: incorrect password", models.ErrUnauthorized)
 	} else if err != nil {
 		return 0, "", err
 	}
@@ -216,203 +251,416 @@ func (p *postgressDBRepo) Authenticate(email, testPassword string) (int, string,
 	return id, hashedPassword, nil
 }
 
-func (p *postgressDBRepo) AllReservations() ([]models.Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	var reservations []models.Reservation
+// reservationColumns is the select list read by scanReservation, from reservations r and reservationJoins
+const reservationColumns = `r.id, r.first_name, r.last_name, r.email, r.phone,
+	r.start_date, r.end_date, r.room_id, r.created_at, r.updated_at, r.status,
+	r.confirmed_at, r.checked_in_at, r.checked_out_at, r.cancelled_at, r.no_show_at, r.cancel_reason,
+	r.adults, r.children, r.subtotal, r.discount, r.total, coalesce(r.promo_code_id, 0), r.promo_code,
+	r.cancellation_policy, r.cancel_free_days, r.cancel_penalty_percent, r.cancel_non_refundable, r.penalty, r.refund,
+	rm.id, rm.room_name, coalesce(rt.id, 0), coalesce(rt.name, '')`
+
+// reservationJoins adds the room of the reservation and its type to reservations r
+const reservationJoins = `
+		left join rooms rm on (r.room_id = rm.id)
+		left join room_types rt on (rm.room_type_id = rt.id)`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	query := `
-		select r.id, r.first_name, r.last_name, r.email, r.phone, r.start_date, r.end_date, r.room_id, r.created_at, r.updated_at, r.processed, rm.id, rm.room_name
-		from reservations r
-		left join rooms rm 
-		on (r.room_id = rm.id)
-		order by r.start_date asc
-	`
+// scanReservation reads a row selected with reservationColumns
+func scanReservation(row rowScanner, res *models.Reservation) error {
+	var confirmedAt, checkedInAt, checkedOutAt, cancelledAt, noShowAt sql.NullTime
 
-	rows, err := p.DB.SQL.QueryContext(ctx, query)
+	err := row.Scan(
+		&res.ID,
+		&res.FirstName,
+		&res.LastName,
+		&res.Email,
+		&res.Phone,
+		&res.StartDate,
+		&res.EndDate,
+		&res.RoomID,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+		&res.Status,
+		&confirmedAt,
+		&checkedInAt,
+		&checkedOutAt,
+		&cancelledAt,
+		&noShowAt,
+		&res.CancelReason,
+		&res.Adults,
+		&res.Children,
+		&res.Subtotal,
+		&res.Discount,
+		&res.Total,
+		&res.PromoCode.ID,
+		&res.PromoCode.Code,
+		&res.CancellationPolicy.Name,
+		&res.CancellationPolicy.FreeDays,
+		&res.CancellationPolicy.PenaltyPercent,
+		&res.CancellationPolicy.NonRefundable,
+		&res.Penalty,
+		&res.Refund,
+		&res.Room.ID,
+		&res.Room.RoomName,
+		&res.RoomType.ID,
+		&res.RoomType.Name,
+	)
 	if err != nil {
-		return reservations, err
+		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var m models.Reservation
-		err = rows.Scan(
-			&m.ID,
-			&m.FirstName,
-			&m.LastName,
-			&m.Email,
-			&m.Phone,
-			&m.StartDate,
-			&m.EndDate,
-			&m.RoomID,
-			&m.CreatedAt,
-			&m.UpdatedAt,
-			&m.Processed,
-			&m.Room.ID,
-			&m.Room.RoomName,
-		)
+	res.ConfirmedAt = confirmedAt.Time
+	res.CheckedInAt = checkedInAt.Time
+	res.CheckedOutAt = checkedOutAt.Time
+	res.CancelledAt = cancelledAt.Time
+	res.NoShowAt = noShowAt.Time
+	res.Room.RoomTypeID = res.RoomType.ID
 
-		if err != nil {
-			return reservations, err
-		}
+	return nil
+}
 
-		reservations = append(reservations, m)
+// reservationSortColumns maps the sort keys of models.ReservationFilter to columns,
+// only these are ever put into the order by clause
+var reservationSortColumns = map[string]string{
+	models.SortByID:        "r.id",
+	models.SortByName:      "r.last_name",
+	models.SortByRoom:      "rm.room_name",
+	models.SortByArrival:   "r.start_date",
+	models.SortByDeparture: "r.end_date",
+	models.SortByStatus:    "r.status",
+	models.SortByCreated:   "r.created_at",
+}
+
+// likeEscaper makes user input match literally in a like pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// reservationQuery returns the from and where clauses selecting the reservations of the property of ctx
+// matching f, with their arguments
+func reservationQuery(ctx context.Context, f models.ReservationFilter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
 	}
 
-	if err = rows.Err(); err != nil {
-		return reservations, err
+	if f.Status != "" {
+		add("r.status = $%d", f.Status)
+	} else {
+		where = append(where, "r.status <> 'cancelled'")
+	}
+	if f.Search != "" {
+		add("(r.first_name ilike $%[1]d or r.last_name ilike $%[1]d or r.email ilike $%[1]d)", "%"+likeEscaper.Replace(f.Search)+"%")
 	}
+	if f.RoomID > 0 {
+		add("r.room_id = $%d", f.RoomID)
+	}
+	if !f.From.IsZero() {
+		add("r.end_date > $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("r.start_date <= $%d", f.To)
+	}
+	cond, args := inProperty(ctx, "r.room_id", args)
+	where = append(where, cond)
+
+	from := `
+		from reservations r` + reservationJoins + `
+		where ` + strings.Join(where, " and ")
+
+	return from, args
+}
 
-	return reservations, nil
+// reservationOrder returns the order by clause for f, ties are broken by id so pages are stable
+func reservationOrder(f models.ReservationFilter) string {
+	column, ok := reservationSortColumns[f.Sort]
+	if !ok {
+		column = reservationSortColumns[models.SortByArrival]
+	}
+	direction := "asc"
+	if f.Desc {
+		direction = "desc"
+	}
+	return fmt.Sprintf(" order by %s %s, r.id %s", column, direction, direction)
 }
 
-func (p *postgressDBRepo) AllNewReservations() ([]models.Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// Reservations returns one page of the reservations matching filter along with the total number of matches,
+// cancelled reservations are only listed when filtering on that status
+func (p *postgressDBRepo) Reservations(ctx context.Context, f models.ReservationFilter) (models.ReservationPage, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
-	var reservations []models.Reservation
+	page := models.ReservationPage{Page: f.Page, PerPage: f.PerPage}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PerPage <= 0 {
+		page.PerPage = models.DefaultPerPage
+	}
+	if page.PerPage > models.MaxPerPage {
+		page.PerPage = models.MaxPerPage
+	}
 
-	query := `
-		select r.id, r.first_name, r.last_name, r.email, r.phone, r.start_date, r.end_date, r.room_id, r.created_at, r.updated_at, rm.id, rm.room_name
-		from reservations r
-		left join rooms rm 
-		on (r.room_id = rm.id)
-		where processed = 0
-		order by r.start_date asc
-	`
+	from, args := reservationQuery(ctx, f)
 
-	rows, err := p.DB.SQL.QueryContext(ctx, query)
+	err := p.DB.SQL.QueryRowContext(ctx, "select count(*) "+from, args...).Scan(&page.Total)
 	if err != nil {
-		return reservations, err
+		return page, err
+	}
+
+	args = append(args, page.PerPage, (page.Page-1)*page.PerPage)
+	query := fmt.Sprintf("select %s %s %s limit $%d offset $%d",
+		reservationColumns, from, reservationOrder(f), len(args)-1, len(args))
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return page, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var m models.Reservation
-		err = rows.Scan(
-			&m.ID,
-			&m.FirstName,
-			&m.LastName,
-			&m.Email,
-			&m.Phone,
-			&m.StartDate,
-			&m.EndDate,
-			&m.RoomID,
-			&m.CreatedAt,
-			&m.UpdatedAt,
-			&m.Room.ID,
-			&m.Room.RoomName,
-		)
-
-		if err != nil {
-			return reservations, err
+		if err := scanReservation(rows, &m); err != nil {
+			return page, err
 		}
+		page.Reservations = append(page.Reservations, m)
+	}
 
-		reservations = append(reservations, m)
+	return page, rows.Err()
+}
+
+// EachReservation calls fn for every reservation matching filter, ignoring its page, without loading them
+// all in memory. It stops at the first error returned by fn. Exports can run for a while so the
+// query is only bounded by ctx, not by the configured query timeout.
+func (p *postgressDBRepo) EachReservation(ctx context.Context, f models.ReservationFilter, fn func(models.Reservation) error) error {
+	from, args := reservationQuery(ctx, f)
+
+	rows, err := p.DB.SQL.QueryContext(ctx, "select "+reservationColumns+from+reservationOrder(f), args...)
+	if err != nil {
+		return err
 	}
+	defer rows.Close()
 
-	if err = rows.Err(); err != nil {
-		return reservations, err
+	for rows.Next() {
+		var m models.Reservation
+		if err := scanReservation(rows, &m); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
 	}
 
-	return reservations, nil
+	return rows.Err()
 }
 
-func (p *postgressDBRepo) GetReservationByID(id int) (models.Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) GetReservationByID(ctx context.Context, id int) (models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var res models.Reservation
 
+	cond, args := inProperty(ctx, "r.room_id", []interface{}{id})
 	query := `
-		select r.id, r.first_name, r.last_name, r.email, r.phone, 
-			r.start_date, r.end_date, r.room_id, r.created_at, r.updated_at, r.processed,
-			rm.id, rm.room_name
-		from reservations r
-		left join rooms rm 
-		on (r.room_id = rm.id)
-		where r.id = $1
-	`
-
-	row := p.DB.SQL.QueryRowContext(ctx, query, id)
-	err := row.Scan(
-		&res.ID,
-		&res.FirstName,
-		&res.LastName,
-		&res.Email,
-		&res.Phone,
-		&res.StartDate,
-		&res.EndDate,
-		&res.RoomID,
-		&res.CreatedAt,
-		&res.UpdatedAt,
-		&res.Processed,
-		&res.Room.ID,
-		&res.Room.RoomName,
-	)
+		select ` + reservationColumns + `
+		from reservations r` + reservationJoins + `
+		where r.id = $1 and ` + cond
 
+	err := scanReservation(p.DB.SQL.QueryRowContext(ctx, query, args...), &res)
 	if err != nil {
-		return res, err
+		return res, mapError(err)
 	}
 
 	return res, nil
 }
 
-func (p *postgressDBRepo) UpdateReservation(r models.Reservation) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) UpdateReservation(ctx context.Context, r models.Reservation) error {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	query := `
-		update reservations set first_name = $1, last_name = $2, email = $3, phone = $4, updated_at = $5
-		where id = $6
+		update reservations set first_name = $1, last_name = $2, email = $3, phone = $4, adults = $5,
+			children = $6, updated_at = $7
+		where id = $8
 	`
 
-	_, err := p.DB.SQL.ExecContext(ctx, query,
-		r.FirstName,
-		r.LastName,
-		r.Email,
-		r.Phone,
-		time.Now(),
-		r.ID)
+	return p.auditedChange(ctx, AuditReservationUpdate, entityReservation, r.ID, func(tx *sql.Tx) (int, error) {
+		res, err := tx.ExecContext(ctx, query,
+			r.FirstName,
+			r.LastName,
+			r.Email,
+			r.Phone,
+			r.Adults,
+			r.Children,
+			time.Now(),
+			r.ID)
+
+		return r.ID, expectAffected(res, err)
+	})
+}
 
-	return err
+// CancelReservation moves a reservation to the trash and releases its room restriction. The penalty and
+// refund of cancelling on day on are worked out from the policy the reservation was booked on and recorded.
+func (p *postgressDBRepo) CancelReservation(ctx context.Context, id int, reason string, on time.Time) (models.Cancellation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var c models.Cancellation
+	err := p.auditedChange(ctx, AuditReservationCancel, entityReservation, id, func(tx *sql.Tx) (int, error) {
+		err := transitionStatus(ctx, tx, id, models.StatusCancelled)
+		if err != nil {
+			return id, err
+		}
+
+		// the row is locked by the transition
+		var res models.Reservation
+		policy := &res.CancellationPolicy
+		err = tx.QueryRowContext(ctx, `
+			select start_date, total, cancel_free_days, cancel_penalty_percent, cancel_non_refundable
+			from reservations where id = $1`, id).
+			Scan(&res.StartDate, &res.Total, &policy.FreeDays, &policy.PenaltyPercent, &policy.NonRefundable)
+		if err != nil {
+			return id, err
+		}
+		c = res.Cancellation(dates.Of(on))
+
+		_, err = tx.ExecContext(ctx, `update reservations set cancel_reason = $1, penalty = $2, refund = $3 where id = $4`,
+			reason, c.Penalty, c.Refund, id)
+		if err != nil {
+			return id, err
+		}
+
+		_, err = tx.ExecContext(ctx, `delete from room_restrictions where reservation_id = $1`, id)
+		return id, err
+	})
+	if err != nil {
+		return models.Cancellation{}, err
+	}
+	return c, nil
+}
+
+// RestoreReservation takes a reservation out of the trash if its room is still free for its dates
+func (p *postgressDBRepo) RestoreReservation(ctx context.Context, id int) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return p.auditedChange(ctx, AuditReservationRestore, entityReservation, id, func(tx *sql.Tx) (int, error) {
+		var res models.Reservation
+		var confirmedAt sql.NullTime
+
+		err := tx.QueryRowContext(ctx, `select room_id, start_date, end_date, status, confirmed_at from reservations where id = $1 for update`, id).
+			Scan(&res.RoomID, &res.StartDate, &res.EndDate, &res.Status, &confirmedAt)
+		if err != nil {
+			return id, mapError(err)
+		}
+		if res.Status != models.StatusCancelled {
+			return id, models.NewValidationError("", "the reservation is not cancelled")
+		}
+
+		// the reservation goes back to where it was before being cancelled
+		status := models.StatusPending
+		if confirmedAt.Valid {
+			status = models.StatusConfirmed
+		}
+
+		if err := lockRoom(ctx, tx, res.RoomID); err != nil {
+			return id, err
+		}
+
+		taken, err := roomTaken(ctx, tx, res.RoomID, res.StartDate, res.EndDate, 0)
+		if err != nil {
+			return id, err
+		}
+		if taken {
+			return id, fmt.Errorf("%w: the room is no longer available for these dates", models.ErrConflict)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			update reservations set status = $1, cancelled_at = null, cancel_reason = '', penalty = 0, refund = 0,
+				updated_at = $2
+			where id = $3`, status, time.Now(), id)
+		if err != nil {
+			return id, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			insert into room_restrictions (start_date, end_date, room_id, reservation_id, created_at, updated_at, restriction_id)
+			values ($1, $2, $3, $4, $5, $6, $7)`,
+			res.StartDate, res.EndDate, res.RoomID, id, time.Now(), time.Now(), 1)
+		return id, mapError(err)
+	})
 }
 
-func (p *postgressDBRepo) DeleteReservation(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// CancelledReservations returns the reservations in the trash, most recently cancelled first
+func (p *postgressDBRepo) CancelledReservations(ctx context.Context) ([]models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
+	var reservations []models.Reservation
+
+	cond, args := inProperty(ctx, "r.room_id", nil)
 	query := `
-		delete from reservations where id = $1
+		select ` + reservationColumns + `
+		from reservations r` + reservationJoins + `
+		where r.status = 'cancelled' and ` + cond + `
+		order by r.cancelled_at desc
 	`
 
-	_, err := p.DB.SQL.ExecContext(ctx, query, id)
-	return err
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return reservations, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.Reservation
+		if err := scanReservation(rows, &m); err != nil {
+			return reservations, err
+		}
+		reservations = append(reservations, m)
+	}
+
+	return reservations, rows.Err()
 }
 
-func (p *postgressDBRepo) UpdateProcessedForReservation(id, processed int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// PurgeCancelledReservations permanently deletes reservations cancelled before the given time,
+// keeping their last state in the audit log
+func (p *postgressDBRepo) PurgeCancelledReservations(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	query := `
-		update reservations set processed = $1 where id = $2
+		with purged as (
+			delete from reservations where status = 'cancelled' and cancelled_at < $1 returning *
+		)
+		insert into audit_log (user_id, action, entity_type, entity_id, before_data, after_data, created_at, property_id)
+		select null, $2, $3, purged.id, row_to_json(purged)::jsonb, null, $4,
+			(select property_id from rooms where id = purged.room_id)
+		from purged
 	`
 
-	_, err := p.DB.SQL.ExecContext(ctx, query, processed, id)
-	return err
+	res, err := p.DB.SQL.ExecContext(ctx, query, before, AuditReservationPurge, entityReservation, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
 }
 
-func (p *postgressDBRepo) AllRooms() ([]models.Room, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) AllRooms(ctx context.Context) ([]models.Room, error) {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var rooms []models.Room
 
-	query := `select id, room_name, created_at, updated_at from rooms order by room_name`
+	cond, args := inProperty(ctx, "id", nil)
+	query := `select id, property_id, room_type_id, room_name, created_at, updated_at from rooms where ` + cond + ` order by room_name`
 
-	rows, err := p.DB.SQL.QueryContext(ctx, query)
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
 	if err != nil {
 		return rooms, err
 	}
@@ -422,6 +670,8 @@ func (p *postgressDBRepo) AllRooms() ([]models.Room, error) {
 		var r models.Room
 		err = rows.Scan(
 			&r.ID,
+			&r.PropertyID,
+			&r.RoomTypeID,
 			&r.RoomName,
 			&r.CreatedAt,
 			&r.UpdatedAt,
@@ -437,62 +687,32 @@ func (p *postgressDBRepo) AllRooms() ([]models.Room, error) {
 	return rooms, rows.Err()
 }
 
-func (p *postgressDBRepo) GetRestrictionsForRoomByDate(roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) InsertBlockForRoom(ctx context.Context, id int, startDate time.Time) error {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
-	var restrictions []models.RoomRestriction
-	query := `select id, coalesce(reservation_id, 0), restriction_id, room_id, start_date, end_date
-			  from room_restrictions where $1 < end_date and $2 >= start_date and room_id = $3`
-
-	rows, err := p.DB.SQL.QueryContext(ctx, query, start, end, roomID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var r models.RoomRestriction
-		err = rows.Scan(
-			&r.ID,
-			&r.ReservationID,
-			&r.RestrictionID,
-			&r.RoomID,
-			&r.StartDate,
-			&r.EndDate,
-		)
+	query := `insert into room_restrictions (start_date, end_date, room_id, restriction_id, created_at, updated_at) 
+			  values ($1, $2, $3, $4, $5, $6) returning id`
 
-		if err != nil {
-			return nil, err
+	return p.auditedChange(ctx, AuditBlockCreate, entityRoomRestriction, 0, func(tx *sql.Tx) (int, error) {
+		if err := lockRoom(ctx, tx, id); err != nil {
+			return 0, err
 		}
 
-		restrictions = append(restrictions, r)
-	}
-
-	if rows.Err() != nil {
-		return nil, err
-	}
-
-	return restrictions, nil
+		var blockID int
+		err := tx.QueryRowContext(ctx, query, startDate, startDate.AddDate(0, 0, 1), id, 2, time.Now(), time.Now()).Scan(&blockID)
+		return blockID, mapError(err)
+	})
 }
 
-func (p *postgressDBRepo) InsertBlockForRoom(id int, startDate time.Time) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (p *postgressDBRepo) DeleteBlockByID(ctx context.Context, id int) error {
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
-	query := `insert into room_restrictions (start_date, end_date, room_id, restriction_id, created_at, updated_at) 
-			  values ($1, $2, $3, $4, $5, $6)`
+	// only blocks, the restriction of a reservation goes away with its cancellation
+	query := `delete from room_restrictions where id = $1 and reservation_id is null`
 
-	_, err := p.DB.SQL.ExecContext(ctx, query, startDate, startDate.AddDate(0, 0, 1), id, 2, time.Now(), time.Now())
-	return err
-}
-
-func (p *postgressDBRepo) DeleteBlockByID(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	query := `delete from room_restrictions where id = $1`
-
-	_, err := p.DB.SQL.ExecContext(ctx, query, id)
-	return err
+	return p.auditedChange(ctx, AuditBlockDelete, entityRoomRestriction, id, func(tx *sql.Tx) (int, error) {
+		return id, expectAffected(tx.ExecContext(ctx, query, id))
+	})
 }
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservations_FilterSortAndPage(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.ReservationFilter{
+		Status:  models.StatusConfirmed,
+		Search:  "50%_off",
+		RoomID:  2,
+		From:    from,
+		Sort:    models.SortByName,
+		Desc:    true,
+		Page:    3,
+		PerPage: 10,
+	}
+
+	mock.ExpectQuery(`select count\(\*\)`).
+		WithArgs(models.StatusConfirmed, `%50\%\_off%`, 2, from).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+	mock.ExpectQuery(`order by r.last_name desc, r.id desc limit \$5 offset \$6`).
+		WithArgs(models.StatusConfirmed, `%50\%\_off%`, 2, from, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	page, err := repo.Reservations(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 21, page.Total)
+	assert.Equal(t, 3, page.Pages())
+	assert.False(t, page.HasNext())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReservations_Defaults(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	// unknown sort keys fall back to the arrival date, page sizes are capped
+	mock.ExpectQuery(`select count\(\*\) .* where r.status <> 'cancelled'`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`order by r.start_date asc, r.id asc limit \$1 offset \$2`).
+		WithArgs(models.MaxPerPage, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	page, err := repo.Reservations(context.Background(), models.ReservationFilter{Sort: "password", PerPage: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, 1, page.Pages())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOverlaps(t *testing.T) {
+	// a stay arriving the day another leaves does not clash with it
+	assert.Equal(t, "r.start_date < $2 and r.end_date > $1", overlaps("r.", 1, 2))
+	assert.Equal(t, "start_date < $4 and end_date > $3", overlaps("", 3, 4))
+}
+
+// reservationRows returns rows shaped like reservationColumns
+func reservationRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "phone",
+		"start_date", "end_date", "room_id", "created_at", "updated_at", "status",
+		"confirmed_at", "checked_in_at", "checked_out_at", "cancelled_at", "no_show_at", "cancel_reason",
+		"adults", "children", "subtotal", "discount", "total", "promo_code_id", "promo_code",
+		"cancellation_policy", "cancel_free_days", "cancel_penalty_percent", "cancel_non_refundable", "penalty", "refund",
+		"room_id", "room_name", "room_type_id", "room_type_name"})
+}
+
+// addReservationRow adds a reservation of room 1 to rows
+func addReservationRow(rows *sqlmock.Rows, id int, start, end time.Time, status models.ReservationStatus) *sqlmock.Rows {
+	return rows.AddRow(id, "Khanh", "Nguyen", "khanh@example.com", "555-0100",
+		start, end, 1, start, start, status,
+		nil, nil, nil, nil, nil, "",
+		2, 0, 24000, 2400, 21600, 5, "WINTER10",
+		"Flexible", 7, 50, false, 0, 0, 1, "Major Suite", 1, "Major Suite")
+}
+
+func TestSearchAvailability_SameOverlapRule(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+
+	// both searches use the half-open rule, a stay leaving on start does not clash
+	mock.ExpectQuery(`where room_id = \$1 and start_date < \$3 and end_date > \$2`).
+		WithArgs(1, start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"available"}).AddRow(true))
+	mock.ExpectQuery(`t.max_occupancy >= \$3 and r.id not in \(select room_id from room_restrictions rr where rr.start_date < \$2 and rr.end_date > \$1\)`).
+		WithArgs(start, end, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "room_name"}).AddRow(1, "General's Quarters"))
+
+	available, err := repo.SearchAvailabilityByDatesByRoomID(context.Background(), 1, start, end)
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	rooms, err := repo.SearchAvailabilityForAllRooms(context.Background(), start, end, 1)
+	assert.NoError(t, err)
+	assert.Len(t, rooms, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"booking/models"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgExclusionViolation  = "23P01"
+)
+
+// mapError translates driver errors into the domain errors of the models package
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %v", models.ErrNotFound, err)
+	}
+
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		switch pgErr.SQLState() {
+		case pgUniqueViolation, pgExclusionViolation:
+			return fmt.Errorf("%w: %v", models.ErrConflict, err)
+		case pgForeignKeyViolation:
+			return fmt.Errorf("%w: %v", models.ErrValidation, err)
+		}
+	}
+
+	return err
+}
+
+// expectAffected returns ErrNotFound when an update or delete did not touch any row
+func expectAffected(res sql.Result, err error) error {
+	if err != nil {
+		return mapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"booking/models"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"no rows", sql.ErrNoRows, models.ErrNotFound},
+		{"unique violation", pgx.PgError{Code: pgUniqueViolation}, models.ErrConflict},
+		{"exclusion violation", pgx.PgError{Code: pgExclusionViolation}, models.ErrConflict},
+		{"foreign key violation", pgx.PgError{Code: pgForeignKeyViolation}, models.ErrValidation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.True(t, errors.Is(mapError(test.err), test.expected))
+		})
+	}
+
+	other := errors.New("connection refused")
+	assert.Equal(t, other, mapError(other))
+	assert.NoError(t, mapError(nil))
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// noteColumns is the select list read by scanNote, the author is joined as u
+const noteColumns = `n.id, n.reservation_id, coalesce(n.user_id, 0), coalesce(u.first_name || ' ' || u.last_name, ''),
+	n.body, n.created_at`
+
+// scanNote reads a row selected with noteColumns
+func scanNote(row rowScanner, n *models.ReservationNote) error {
+	return row.Scan(&n.ID, &n.ReservationID, &n.UserID, &n.UserName, &n.Body, &n.CreatedAt)
+}
+
+// frontDeskWhere returns the where clause selecting the reservations of the property of ctx arriving,
+// staying or leaving on day, with its arguments
+func frontDeskWhere(ctx context.Context, day time.Time) (string, []interface{}) {
+	cond, args := inProperty(ctx, "r.room_id", []interface{}{day})
+	return `
+	where r.status not in ('cancelled', 'no_show') and r.start_date <= $1 and r.end_date >= $1 and ` + cond, args
+}
+
+// FrontDesk returns the arrivals, departures and in-house guests of a day with their notes
+func (p *postgressDBRepo) FrontDesk(ctx context.Context, day time.Time) (models.FrontDesk, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	desk := models.FrontDesk{Date: day}
+
+	where, args := frontDeskWhere(ctx, day)
+	query := `
+		select ` + reservationColumns + `
+		from reservations r` + reservationJoins + where + `
+		order by rm.room_name, r.start_date, r.id`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return desk, err
+	}
+	defer rows.Close()
+
+	var reservations []models.Reservation
+	for rows.Next() {
+		var res models.Reservation
+		if err := scanReservation(rows, &res); err != nil {
+			return desk, err
+		}
+		reservations = append(reservations, res)
+	}
+	if err := rows.Err(); err != nil {
+		return desk, err
+	}
+
+	notes, err := p.frontDeskNotes(ctx, day)
+	if err != nil {
+		return desk, err
+	}
+
+	for _, res := range reservations {
+		res.Notes = notes[res.ID]
+		switch {
+		case res.StartDate.Equal(day):
+			desk.Arrivals = append(desk.Arrivals, res)
+		case res.EndDate.Equal(day):
+			desk.Departures = append(desk.Departures, res)
+		default:
+			desk.InHouse = append(desk.InHouse, res)
+		}
+	}
+
+	return desk, nil
+}
+
+// frontDeskNotes loads the notes of the front desk reservations of the day, by reservation id
+func (p *postgressDBRepo) frontDeskNotes(ctx context.Context, day time.Time) (map[int][]models.ReservationNote, error) {
+	where, args := frontDeskWhere(ctx, day)
+	query := `
+		select ` + noteColumns + `
+		from reservation_notes n
+		join reservations r on (r.id = n.reservation_id)
+		left join users u on (u.id = n.user_id)` + where + `
+		order by n.created_at, n.id`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make(map[int][]models.ReservationNote)
+	for rows.Next() {
+		var n models.ReservationNote
+		if err := scanNote(rows, &n); err != nil {
+			return nil, err
+		}
+		notes[n.ReservationID] = append(notes[n.ReservationID], n)
+	}
+
+	return notes, rows.Err()
+}
+
+// AddReservationNote saves a note written by the actor in ctx, ErrNotFound if the reservation does not exist
+// in the property of ctx
+func (p *postgressDBRepo) AddReservationNote(ctx context.Context, reservationID int, body string) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var userID sql.NullInt64
+	if actor := ActorFromContext(ctx); actor > 0 {
+		userID = sql.NullInt64{Int64: int64(actor), Valid: true}
+	}
+
+	cond, args := inProperty(ctx, "room_id", []interface{}{reservationID, userID, body, time.Now()})
+	query := `
+		insert into reservation_notes (reservation_id, user_id, body, created_at)
+		select $1::integer, $2::integer, $3::text, $4::timestamp
+		where exists (select 1 from reservations where id = $1 and ` + cond + `)
+		returning id`
+
+	var id int
+	err := p.DB.SQL.QueryRowContext(ctx, query, args...).Scan(&id)
+	return id, mapError(err)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrontDesk(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	day := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	rows := reservationRows()
+	addReservationRow(rows, 1, day, day.AddDate(0, 0, 2), models.StatusConfirmed)
+	addReservationRow(rows, 2, day.AddDate(0, 0, -3), day, models.StatusCheckedIn)
+	addReservationRow(rows, 3, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1), models.StatusCheckedIn)
+
+	mock.ExpectQuery("from reservations r left join rooms rm").WithArgs(day).WillReturnRows(rows)
+	mock.ExpectQuery("from reservation_notes n").WithArgs(day).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reservation_id", "user_id", "user_name", "body", "created_at"}).
+			AddRow(1, 2, 7, "Khanh Nguyen", "Late checkout", day))
+
+	desk, err := repo.FrontDesk(context.Background(), day)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Len(t, desk.Arrivals, 1)
+	assert.Equal(t, 1, desk.Arrivals[0].ID)
+	assert.Len(t, desk.Departures, 1)
+	assert.Equal(t, "Late checkout", desk.Departures[0].Notes[0].Body)
+	assert.Len(t, desk.InHouse, 1)
+	assert.Equal(t, 3, desk.InHouse[0].ID)
+}
+
+func TestAddReservationNote(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := WithActor(context.Background(), 7)
+
+	mock.ExpectQuery("insert into reservation_notes").
+		WithArgs(3, int64(7), "Late checkout", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	id, err := repo.AddReservationNote(ctx, 3, "Late checkout")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	// the reservation does not exist
+	mock.ExpectQuery("insert into reservation_notes").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = repo.AddReservationNote(ctx, 4, "Late checkout")
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// lockRoom takes a row lock on the room so that no other transaction can book it until tx ends,
+// a room of another property than the one of ctx is not found
+func lockRoom(ctx context.Context, tx *sql.Tx, roomID int) error {
+	cond, args := inProperty(ctx, "id", []interface{}{roomID})
+	var id int
+	err := tx.QueryRowContext(ctx, `select id from rooms where id = $1 and `+cond+` for update`, args...).Scan(&id)
+	return mapError(err)
+}
+
+// roomTaken tells whether a restriction of the room overlaps the stay from start to end.
+// The restriction of reservation except is ignored so that a reservation does not clash with itself, 0 checks them all.
+func roomTaken(ctx context.Context, tx *sql.Tx, roomID int, start, end time.Time, except int) (bool, error) {
+	var overlapping int
+	err := tx.QueryRowContext(ctx, `
+		select count(id) from room_restrictions
+		where room_id = $1 and `+overlaps("", 2, 3)+` and reservation_id is distinct from $4`,
+		roomID, start, end, except).Scan(&overlapping)
+	return overlapping > 0, err
+}
+
+// ImportReservations inserts the accepted rows with their room restrictions in a single transaction.
+// Rows clashing with existing reservations, or with earlier rows of the same import, get an error and
+// are skipped. Nothing is written unless commit is set, so a dry run reports exactly what a commit would do.
+// Imports can be large so they are only bounded by ctx, not by the configured query timeout.
+func (p *postgressDBRepo) ImportReservations(ctx context.Context, rows []models.ImportRow, commit bool) error {
+	tx, err := p.DB.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// lock the rooms in a stable order so concurrent imports cannot deadlock
+	rooms := make(map[int]bool)
+	var roomIDs []int
+	for _, row := range rows {
+		if row.Accepted() && !rooms[row.Reservation.RoomID] {
+			rooms[row.Reservation.RoomID] = true
+			roomIDs = append(roomIDs, row.Reservation.RoomID)
+		}
+	}
+	sort.Ints(roomIDs)
+
+	missing := make(map[int]bool)
+	for _, id := range roomIDs {
+		err := lockRoom(ctx, tx, id)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			return err
+		}
+		missing[id] = true
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if !row.Accepted() {
+			continue
+		}
+		res := &row.Reservation
+
+		if missing[res.RoomID] {
+			row.Errors = append(row.Errors, fmt.Sprintf("room_id: Room %d does not exist", res.RoomID))
+			continue
+		}
+
+		if res.Status != models.StatusCancelled {
+			taken, err := roomTaken(ctx, tx, res.RoomID, res.StartDate, res.EndDate, 0)
+			if err != nil {
+				return err
+			}
+			if taken {
+				row.Errors = append(row.Errors, "The room is already booked or blocked for these dates")
+				continue
+			}
+		}
+
+		if err := insertReservation(ctx, tx, res, AuditReservationImport); err != nil {
+			return err
+		}
+	}
+
+	if !commit {
+		// the rows were rolled back, their ids do not exist
+		for i := range rows {
+			rows[i].Reservation.ID = 0
+		}
+		return nil
+	}
+	return tx.Commit()
+}
+
+// insertReservation writes a reservation in its status, its restriction and an audit entry for action
+func insertReservation(ctx context.Context, tx *sql.Tx, res *models.Reservation, action string) error {
+	now := time.Now()
+
+	var promoCodeID sql.NullInt64
+	if res.PromoCode.ID != 0 {
+		promoCodeID = sql.NullInt64{Int64: int64(res.PromoCode.ID), Valid: true}
+	}
+
+	query := `insert into reservations (first_name, last_name, email, phone, start_date, end_date, room_id, status,
+				adults, children, subtotal, discount, total, promo_code_id, promo_code, cancellation_policy,
+				cancel_free_days, cancel_penalty_percent, cancel_non_refundable, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $20)
+			returning id`
+	err := tx.QueryRowContext(ctx, query,
+		res.FirstName,
+		res.LastName,
+		res.Email,
+		res.Phone,
+		res.StartDate,
+		res.EndDate,
+		res.RoomID,
+		res.Status,
+		res.Adults,
+		res.Children,
+		res.Subtotal,
+		res.Discount,
+		res.Total,
+		promoCodeID,
+		res.PromoCode.Code,
+		res.CancellationPolicy.Name,
+		res.CancellationPolicy.FreeDays,
+		res.CancellationPolicy.PenaltyPercent,
+		res.CancellationPolicy.NonRefundable,
+		now).Scan(&res.ID)
+	if err != nil {
+		return mapError(err)
+	}
+
+	if column, ok := statusTimestamps[res.Status]; ok {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`update reservations set %s = $1 where id = $2`, column), now, res.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if res.Status != models.StatusCancelled {
+		_, err := tx.ExecContext(ctx, `
+			insert into room_restrictions (start_date, end_date, room_id, reservation_id, created_at, updated_at, restriction_id)
+			values ($1, $2, $3, $4, $5, $5, $6)`,
+			res.StartDate, res.EndDate, res.RoomID, res.ID, now, 1)
+		if err != nil {
+			return mapError(err)
+		}
+	}
+
+	after, err := snapshot(ctx, tx, entityReservation, res.ID)
+	if err != nil {
+		return err
+	}
+	return audit(ctx, tx, action, entityReservation, res.ID, nil, after)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func importRow(line, roomID int, start time.Time) models.ImportRow {
+	return models.ImportRow{Line: line, Reservation: models.Reservation{
+		FirstName: "Khanh",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 2),
+		RoomID:    roomID,
+		Status:    models.StatusPending,
+	}}
+}
+
+func TestImportReservations_DryRun(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	rows := []models.ImportRow{
+		importRow(2, 2, start),
+		importRow(3, 9, start),
+		importRow(4, 2, start),
+		{Line: 5, Errors: []string{"email: Invalid email address"}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("select id from rooms").WithArgs(9).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("select count").WithArgs(2, start, start.AddDate(0, 0, 2), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into reservations").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	mock.ExpectExec("insert into room_restrictions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(40).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":40}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditReservationImport, "reservations", 40, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// the second stay in room 2 clashes with the first one of the same file
+	mock.ExpectQuery("select count").WithArgs(2, start, start.AddDate(0, 0, 2), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := repo.ImportReservations(context.Background(), rows, false)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.True(t, rows[0].Accepted())
+	assert.Equal(t, 0, rows[0].Reservation.ID)
+	assert.Equal(t, []string{"room_id: Room 9 does not exist"}, rows[1].Errors)
+	assert.Equal(t, []string{"The room is already booked or blocked for these dates"}, rows[2].Errors)
+	assert.Len(t, rows[3].Errors, 1)
+}
+
+func TestImportReservations_Commit(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	rows := []models.ImportRow{importRow(2, 2, start)}
+	rows[0].Reservation.Status = models.StatusConfirmed
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("select count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into reservations").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	mock.ExpectExec("update reservations set confirmed_at").WithArgs(sqlmock.AnyArg(), 40).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("insert into room_restrictions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(40).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":40}`))
+	mock.ExpectExec("insert into audit_log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.ImportReservations(context.Background(), rows, true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 40, rows[0].Reservation.ID)
+}
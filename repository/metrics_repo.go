@@ -0,0 +1,437 @@
+package repository
+
+import (
+	"booking/logging"
+	"booking/metrics"
+	"booking/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// metricsDBRepo records latency, errors and business counters around another DatabaseRepo
+type metricsDBRepo struct {
+	next DatabaseRepo
+}
+
+// NewMetricsRepo wraps repo so that every call is observed by the metrics package
+func NewMetricsRepo(repo DatabaseRepo) DatabaseRepo {
+	return &metricsDBRepo{next: repo}
+}
+
+// observe records the latency and errors of a call to method and logs its failure with the logger of ctx.
+// Records not found, conflicts and refused input are answers rather than failures, they are logged at debug level.
+func observe(ctx context.Context, method string, start time.Time, err error) {
+	metrics.DBQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+	metrics.DBQueryErrors.WithLabelValues(method).Inc()
+
+	log := logging.FromContext(ctx).WithError(err).WithField("method", method)
+	if errors.Is(err, models.ErrNotFound) || errors.Is(err, models.ErrConflict) || errors.Is(err, models.ErrValidation) {
+		log.Debug("database call refused")
+		return
+	}
+	log.Error("database call failed")
+}
+
+func (m *metricsDBRepo) AllUsers(ctx context.Context) bool {
+	return m.next.AllUsers(ctx)
+}
+
+func (m *metricsDBRepo) InsertReservation(ctx context.Context, res models.Reservation) (int, error) {
+	start := time.Now()
+	id, err := m.next.InsertReservation(ctx, res)
+	observe(ctx, "InsertReservation", start, err)
+	if err == nil {
+		metrics.ReservationsCreated.Inc()
+	}
+	return id, err
+}
+
+func (m *metricsDBRepo) InsertRoomRestriction(ctx context.Context, r models.RoomRestriction) (int, error) {
+	start := time.Now()
+	id, err := m.next.InsertRoomRestriction(ctx, r)
+	observe(ctx, "InsertRoomRestriction", start, err)
+	return id, err
+}
+
+func (m *metricsDBRepo) SearchAvailabilityByDatesByRoomID(ctx context.Context, roomID int, start, end time.Time) (bool, error) {
+	t := time.Now()
+	ok, err := m.next.SearchAvailabilityByDatesByRoomID(ctx, roomID, start, end)
+	observe(ctx, "SearchAvailabilityByDatesByRoomID", t, err)
+	return ok, err
+}
+
+func (m *metricsDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time, guests int) ([]models.Room, error) {
+	t := time.Now()
+	rooms, err := m.next.SearchAvailabilityForAllRooms(ctx, start, end, guests)
+	observe(ctx, "SearchAvailabilityForAllRooms", t, err)
+	return rooms, err
+}
+
+func (m *metricsDBRepo) GetRoomByID(ctx context.Context, id int) (models.Room, error) {
+	start := time.Now()
+	room, err := m.next.GetRoomByID(ctx, id)
+	observe(ctx, "GetRoomByID", start, err)
+	return room, err
+}
+
+func (m *metricsDBRepo) UpdateUser(ctx context.Context, u models.User) error {
+	start := time.Now()
+	err := m.next.UpdateUser(ctx, u)
+	observe(ctx, "UpdateUser", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) Authenticate(ctx context.Context, email, testPassword string) (int, string, error) {
+	start := time.Now()
+	id, hash, err := m.next.Authenticate(ctx, email, testPassword)
+	observe(ctx, "Authenticate", start, err)
+	return id, hash, err
+}
+
+func (m *metricsDBRepo) Reservations(ctx context.Context, f models.ReservationFilter) (models.ReservationPage, error) {
+	start := time.Now()
+	res, err := m.next.Reservations(ctx, f)
+	observe(ctx, "Reservations", start, err)
+	return res, err
+}
+
+func (m *metricsDBRepo) EachReservation(ctx context.Context, f models.ReservationFilter, fn func(models.Reservation) error) error {
+	start := time.Now()
+	err := m.next.EachReservation(ctx, f, fn)
+	observe(ctx, "EachReservation", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) ImportReservations(ctx context.Context, rows []models.ImportRow, commit bool) error {
+	start := time.Now()
+	err := m.next.ImportReservations(ctx, rows, commit)
+	observe(ctx, "ImportReservations", start, err)
+	if err == nil && commit {
+		for _, row := range rows {
+			if row.Accepted() {
+				metrics.ReservationsCreated.Inc()
+			}
+		}
+	}
+	return err
+}
+
+func (m *metricsDBRepo) GetReservationByID(ctx context.Context, id int) (models.Reservation, error) {
+	start := time.Now()
+	res, err := m.next.GetReservationByID(ctx, id)
+	observe(ctx, "GetReservationByID", start, err)
+	return res, err
+}
+
+func (m *metricsDBRepo) UpdateReservation(ctx context.Context, r models.Reservation) error {
+	start := time.Now()
+	err := m.next.UpdateReservation(ctx, r)
+	observe(ctx, "UpdateReservation", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) CancelReservation(ctx context.Context, id int, reason string, on time.Time) (models.Cancellation, error) {
+	t := time.Now()
+	c, err := m.next.CancelReservation(ctx, id, reason, on)
+	observe(ctx, "CancelReservation", t, err)
+	if err == nil {
+		metrics.ReservationsCancelled.Inc()
+	}
+	return c, err
+}
+
+func (m *metricsDBRepo) RestoreReservation(ctx context.Context, id int) error {
+	start := time.Now()
+	err := m.next.RestoreReservation(ctx, id)
+	observe(ctx, "RestoreReservation", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) CancelledReservations(ctx context.Context) ([]models.Reservation, error) {
+	start := time.Now()
+	res, err := m.next.CancelledReservations(ctx)
+	observe(ctx, "CancelledReservations", start, err)
+	return res, err
+}
+
+func (m *metricsDBRepo) PurgeCancelledReservations(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+	n, err := m.next.PurgeCancelledReservations(ctx, before)
+	observe(ctx, "PurgeCancelledReservations", start, err)
+	return n, err
+}
+
+func (m *metricsDBRepo) TransitionReservation(ctx context.Context, id int, to models.ReservationStatus) error {
+	start := time.Now()
+	err := m.next.TransitionReservation(ctx, id, to)
+	observe(ctx, "TransitionReservation", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) CreateReservation(ctx context.Context, res models.Reservation) (int, error) {
+	start := time.Now()
+	id, err := m.next.CreateReservation(ctx, res)
+	observe(ctx, "CreateReservation", start, err)
+	if err == nil {
+		metrics.ReservationsCreated.Inc()
+	}
+	return id, err
+}
+
+func (m *metricsDBRepo) MoveReservation(ctx context.Context, id, roomID int, start, end time.Time) error {
+	t := time.Now()
+	err := m.next.MoveReservation(ctx, id, roomID, start, end)
+	observe(ctx, "MoveReservation", t, err)
+	return err
+}
+
+func (m *metricsDBRepo) AllRooms(ctx context.Context) ([]models.Room, error) {
+	start := time.Now()
+	rooms, err := m.next.AllRooms(ctx)
+	observe(ctx, "AllRooms", start, err)
+	return rooms, err
+}
+
+func (m *metricsDBRepo) InsertBlockForRoom(ctx context.Context, id int, startDate time.Time) error {
+	start := time.Now()
+	err := m.next.InsertBlockForRoom(ctx, id, startDate)
+	observe(ctx, "InsertBlockForRoom", start, err)
+	if err == nil {
+		metrics.BlocksCreated.Inc()
+	}
+	return err
+}
+
+func (m *metricsDBRepo) CreateBlock(ctx context.Context, roomID int, start, end time.Time) (int, error) {
+	t := time.Now()
+	id, err := m.next.CreateBlock(ctx, roomID, start, end)
+	observe(ctx, "CreateBlock", t, err)
+	if err == nil {
+		metrics.BlocksCreated.Inc()
+	}
+	return id, err
+}
+
+func (m *metricsDBRepo) RestrictionsInRange(ctx context.Context, from, to time.Time) ([]models.RoomRestriction, error) {
+	start := time.Now()
+	restrictions, err := m.next.RestrictionsInRange(ctx, from, to)
+	observe(ctx, "RestrictionsInRange", start, err)
+	return restrictions, err
+}
+
+func (m *metricsDBRepo) DeleteBlockByID(ctx context.Context, id int) error {
+	start := time.Now()
+	err := m.next.DeleteBlockByID(ctx, id)
+	observe(ctx, "DeleteBlockByID", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) AuditLog(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	start := time.Now()
+	entries, err := m.next.AuditLog(ctx, f)
+	observe(ctx, "AuditLog", start, err)
+	return entries, err
+}
+
+func (m *metricsDBRepo) Occupancy(ctx context.Context, period models.ReportPeriod) (models.OccupancyReport, error) {
+	start := time.Now()
+	report, err := m.next.Occupancy(ctx, period)
+	observe(ctx, "Occupancy", start, err)
+	return report, err
+}
+
+func (m *metricsDBRepo) Movements(ctx context.Context, period models.ReportPeriod) (models.Movements, error) {
+	start := time.Now()
+	movements, err := m.next.Movements(ctx, period)
+	observe(ctx, "Movements", start, err)
+	return movements, err
+}
+
+func (m *metricsDBRepo) StayStats(ctx context.Context, period models.ReportPeriod) (models.StayStats, error) {
+	start := time.Now()
+	stats, err := m.next.StayStats(ctx, period)
+	observe(ctx, "StayStats", start, err)
+	return stats, err
+}
+
+func (m *metricsDBRepo) FrontDesk(ctx context.Context, day time.Time) (models.FrontDesk, error) {
+	start := time.Now()
+	desk, err := m.next.FrontDesk(ctx, day)
+	observe(ctx, "FrontDesk", start, err)
+	return desk, err
+}
+
+func (m *metricsDBRepo) AddReservationNote(ctx context.Context, reservationID int, body string) (int, error) {
+	start := time.Now()
+	id, err := m.next.AddReservationNote(ctx, reservationID, body)
+	observe(ctx, "AddReservationNote", start, err)
+	return id, err
+}
+
+func (m *metricsDBRepo) ReservationTimeline(ctx context.Context, id int) ([]models.TimelineEntry, error) {
+	start := time.Now()
+	entries, err := m.next.ReservationTimeline(ctx, id)
+	observe(ctx, "ReservationTimeline", start, err)
+	return entries, err
+}
+
+func (m *metricsDBRepo) QueueMail(ctx context.Context, msg models.MailData) (int, error) {
+	start := time.Now()
+	id, err := m.next.QueueMail(ctx, msg)
+	observe(ctx, "QueueMail", start, err)
+	return id, err
+}
+
+func (m *metricsDBRepo) MarkMailSent(ctx context.Context, id int, sendErr error) error {
+	start := time.Now()
+	err := m.next.MarkMailSent(ctx, id, sendErr)
+	observe(ctx, "MarkMailSent", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) GetOutboxMail(ctx context.Context, id int) (models.OutboxMail, error) {
+	start := time.Now()
+	mail, err := m.next.GetOutboxMail(ctx, id)
+	observe(ctx, "GetOutboxMail", start, err)
+	return mail, err
+}
+
+func (m *metricsDBRepo) GetPropertyByID(ctx context.Context, id int) (models.Property, error) {
+	start := time.Now()
+	prop, err := m.next.GetPropertyByID(ctx, id)
+	observe(ctx, "GetPropertyByID", start, err)
+	return prop, err
+}
+
+func (m *metricsDBRepo) UserProperties(ctx context.Context, userID int) ([]models.Property, error) {
+	start := time.Now()
+	properties, err := m.next.UserProperties(ctx, userID)
+	observe(ctx, "UserProperties", start, err)
+	return properties, err
+}
+
+func (m *metricsDBRepo) UpdateProperty(ctx context.Context, p models.Property) error {
+	start := time.Now()
+	err := m.next.UpdateProperty(ctx, p)
+	observe(ctx, "UpdateProperty", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) RoomTypes(ctx context.Context) ([]models.RoomType, error) {
+	start := time.Now()
+	types, err := m.next.RoomTypes(ctx)
+	observe(ctx, "RoomTypes", start, err)
+	return types, err
+}
+
+func (m *metricsDBRepo) GetRoomTypeByID(ctx context.Context, id int) (models.RoomType, error) {
+	start := time.Now()
+	t, err := m.next.GetRoomTypeByID(ctx, id)
+	observe(ctx, "GetRoomTypeByID", start, err)
+	return t, err
+}
+
+func (m *metricsDBRepo) SearchAvailabilityByType(ctx context.Context, start, end time.Time, guests int) ([]models.TypeAvailability, error) {
+	t := time.Now()
+	availability, err := m.next.SearchAvailabilityByType(ctx, start, end, guests)
+	observe(ctx, "SearchAvailabilityByType", t, err)
+	return availability, err
+}
+
+func (m *metricsDBRepo) ReserveRoomType(ctx context.Context, typeID int, res models.Reservation) (models.Reservation, error) {
+	start := time.Now()
+	res, err := m.next.ReserveRoomType(ctx, typeID, res)
+	observe(ctx, "ReserveRoomType", start, err)
+	if err == nil {
+		metrics.ReservationsCreated.Inc()
+	}
+	return res, err
+}
+
+func (m *metricsDBRepo) StayRules(ctx context.Context) (models.StayRules, error) {
+	start := time.Now()
+	rules, err := m.next.StayRules(ctx)
+	observe(ctx, "StayRules", start, err)
+	return rules, err
+}
+
+func (m *metricsDBRepo) CreateStayRule(ctx context.Context, r models.StayRule) (int, error) {
+	start := time.Now()
+	id, err := m.next.CreateStayRule(ctx, r)
+	observe(ctx, "CreateStayRule", start, err)
+	return id, err
+}
+
+func (m *metricsDBRepo) DeleteStayRule(ctx context.Context, id int) error {
+	start := time.Now()
+	err := m.next.DeleteStayRule(ctx, id)
+	observe(ctx, "DeleteStayRule", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) SetRate(ctx context.Context, typeID int, rate models.Money, policyID int) error {
+	start := time.Now()
+	err := m.next.SetRate(ctx, typeID, rate, policyID)
+	observe(ctx, "SetRate", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) PromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	start := time.Now()
+	codes, err := m.next.PromoCodes(ctx)
+	observe(ctx, "PromoCodes", start, err)
+	return codes, err
+}
+
+func (m *metricsDBRepo) GetPromoCode(ctx context.Context, code string) (models.PromoCode, error) {
+	start := time.Now()
+	c, err := m.next.GetPromoCode(ctx, code)
+	observe(ctx, "GetPromoCode", start, err)
+	return c, err
+}
+
+func (m *metricsDBRepo) CreatePromoCode(ctx context.Context, c models.PromoCode) (int, error) {
+	start := time.Now()
+	id, err := m.next.CreatePromoCode(ctx, c)
+	observe(ctx, "CreatePromoCode", start, err)
+	return id, err
+}
+
+func (m *metricsDBRepo) SetPromoCodeActive(ctx context.Context, id int, active bool) error {
+	start := time.Now()
+	err := m.next.SetPromoCodeActive(ctx, id, active)
+	observe(ctx, "SetPromoCodeActive", start, err)
+	return err
+}
+
+func (m *metricsDBRepo) CancellationPolicies(ctx context.Context) ([]models.CancellationPolicy, error) {
+	start := time.Now()
+	policies, err := m.next.CancellationPolicies(ctx)
+	observe(ctx, "CancellationPolicies", start, err)
+	return policies, err
+}
+
+func (m *metricsDBRepo) GetCancellationPolicy(ctx context.Context, id int) (models.CancellationPolicy, error) {
+	start := time.Now()
+	c, err := m.next.GetCancellationPolicy(ctx, id)
+	observe(ctx, "GetCancellationPolicy", start, err)
+	return c, err
+}
+
+func (m *metricsDBRepo) CreateCancellationPolicy(ctx context.Context, c models.CancellationPolicy) (int, error) {
+	start := time.Now()
+	id, err := m.next.CreateCancellationPolicy(ctx, c)
+	observe(ctx, "CreateCancellationPolicy", start, err)
+	return id, err
+}
+
+func (m *metricsDBRepo) DeleteCancellationPolicy(ctx context.Context, id int) error {
+	start := time.Now()
+	err := m.next.DeleteCancellationPolicy(ctx, id)
+	observe(ctx, "DeleteCancellationPolicy", start, err)
+	return err
+}
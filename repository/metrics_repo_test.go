@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"booking/logging"
+	"booking/metrics"
+	"booking/mocks"
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsRepo_InsertReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	repo := NewMetricsRepo(mockDB)
+
+	created := testutil.ToFloat64(metrics.ReservationsCreated)
+	failed := testutil.ToFloat64(metrics.DBQueryErrors.WithLabelValues("InsertReservation"))
+
+	mockDB.EXPECT().InsertReservation(gomock.Any(), gomock.Any()).Return(1, nil)
+	id, err := repo.InsertReservation(context.Background(), models.Reservation{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.ReservationsCreated))
+
+	mockDB.EXPECT().InsertReservation(gomock.Any(), gomock.Any()).Return(0, errors.New("insert failed"))
+	_, err = repo.InsertReservation(context.Background(), models.Reservation{})
+	assert.Error(t, err)
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.ReservationsCreated))
+	assert.Equal(t, failed+1, testutil.ToFloat64(metrics.DBQueryErrors.WithLabelValues("InsertReservation")))
+}
+
+func TestMetricsRepo_CancelReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	repo := NewMetricsRepo(mockDB)
+
+	cancelled := testutil.ToFloat64(metrics.ReservationsCancelled)
+
+	mockDB.EXPECT().CancelReservation(gomock.Any(), 1, "duplicate", gomock.Any()).Return(models.Cancellation{}, nil)
+	_, err := repo.CancelReservation(context.Background(), 1, "duplicate", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, cancelled+1, testutil.ToFloat64(metrics.ReservationsCancelled))
+}
+
+func TestMetricsRepo_LogsWithRequestLogger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	repo := NewMetricsRepo(mockDB)
+
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	ctx := logging.WithLogger(context.Background(), logger.WithField("request_id", "req-7"))
+
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 3).Return(models.Reservation{}, errors.New("connection reset"))
+	_, _ = repo.GetReservationByID(ctx, 3)
+	if assert.Len(t, hook.Entries, 1) {
+		assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+		assert.Equal(t, "req-7", hook.LastEntry().Data["request_id"])
+		assert.Equal(t, "GetReservationByID", hook.LastEntry().Data["method"])
+	}
+
+	// a reservation that does not exist is an answer, not a failure
+	mockDB.EXPECT().GetReservationByID(gomock.Any(), 4).Return(models.Reservation{}, models.ErrNotFound)
+	_, _ = repo.GetReservationByID(ctx, 4)
+	assert.Equal(t, logrus.DebugLevel, hook.LastEntry().Level)
+}
+
+func TestMetricsRepo_CreateBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	repo := NewMetricsRepo(mockDB)
+
+	blocks := testutil.ToFloat64(metrics.BlocksCreated)
+	start := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+
+	// blocks drawn on the timeline count like those ticked on the calendar
+	mockDB.EXPECT().CreateBlock(gomock.Any(), 1, start, start.AddDate(0, 0, 2)).Return(12, nil)
+	_, err := repo.CreateBlock(context.Background(), 1, start, start.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, blocks+1, testutil.ToFloat64(metrics.BlocksCreated))
+
+	mockDB.EXPECT().CreateBlock(gomock.Any(), 1, start, start).Return(0, models.ErrValidation)
+	_, err = repo.CreateBlock(context.Background(), 1, start, start)
+	assert.Error(t, err)
+	assert.Equal(t, blocks+1, testutil.ToFloat64(metrics.BlocksCreated))
+}
+
+func TestMetricsRepo_ReserveRoomType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabaseRepo(ctrl)
+	repo := NewMetricsRepo(mockDB)
+
+	created := testutil.ToFloat64(metrics.ReservationsCreated)
+
+	// guests book through ReserveRoomType, their reservations are counted like the others
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).Return(models.Reservation{ID: 41}, nil)
+	_, err := repo.ReserveRoomType(context.Background(), 1, models.Reservation{})
+	assert.NoError(t, err)
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.ReservationsCreated))
+
+	mockDB.EXPECT().ReserveRoomType(gomock.Any(), 1, gomock.Any()).Return(models.Reservation{}, models.ErrConflict)
+	_, err = repo.ReserveRoomType(context.Background(), 1, models.Reservation{})
+	assert.Error(t, err)
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.ReservationsCreated))
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// outboxColumns is the select list read by scanOutboxMail
+const outboxColumns = `id, coalesce(reservation_id, 0), recipient, sender, subject, content, template,
+	status, error, created_at, sent_at`
+
+// scanOutboxMail reads a row selected with outboxColumns
+func scanOutboxMail(row rowScanner, m *models.OutboxMail) error {
+	var sentAt sql.NullTime
+	err := row.Scan(&m.ID, &m.ReservationID, &m.To, &m.From, &m.Subject, &m.Content, &m.Template,
+		&m.Status, &m.Error, &m.CreatedAt, &sentAt)
+	m.SentAt = sentAt.Time
+	return err
+}
+
+// QueueMail records an email in the outbox before it is handed to the mail listener
+func (p *postgressDBRepo) QueueMail(ctx context.Context, msg models.MailData) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var reservationID sql.NullInt64
+	if msg.ReservationID > 0 {
+		reservationID = sql.NullInt64{Int64: int64(msg.ReservationID), Valid: true}
+	}
+
+	query := `insert into mail_outbox (reservation_id, recipient, sender, subject, content, template, status, created_at)
+			  values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+
+	var id int
+	err := p.DB.SQL.QueryRowContext(ctx, query,
+		reservationID, msg.To, msg.From, msg.Subject, msg.Content, msg.Template, models.MailQueued, time.Now()).Scan(&id)
+	return id, mapError(err)
+}
+
+// MarkMailSent records the outcome of sending an outbox email, sendErr is nil when it was delivered
+func (p *postgressDBRepo) MarkMailSent(ctx context.Context, id int, sendErr error) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	status, message := models.MailSent, ""
+	var sentAt sql.NullTime
+	if sendErr != nil {
+		status, message = models.MailFailed, sendErr.Error()
+	} else {
+		sentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	query := `update mail_outbox set status = $1, error = $2, sent_at = $3 where id = $4`
+	return expectAffected(p.DB.SQL.ExecContext(ctx, query, status, message, sentAt, id))
+}
+
+// GetOutboxMail returns an email of the outbox about a reservation of the property of ctx
+func (p *postgressDBRepo) GetOutboxMail(ctx context.Context, id int) (models.OutboxMail, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := "select " + outboxColumns + " from mail_outbox where id = $1"
+	cond, args := inProperty(ctx, "r.room_id", []interface{}{id})
+	if cond != "true" {
+		// emails that are not about a reservation, such as exports, belong to no property
+		query += " and reservation_id in (select r.id from reservations r where " + cond + ")"
+	}
+
+	var m models.OutboxMail
+	row := p.DB.SQL.QueryRowContext(ctx, query, args...)
+	return m, mapError(scanOutboxMail(row, &m))
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueMail(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	mock.ExpectQuery("insert into mail_outbox").
+		WithArgs(int64(3), "guest@example.com", "me@email.com", "Welcome", "<p>Hi</p>", "basic.html", models.MailQueued, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+
+	id, err := repo.QueueMail(context.Background(), models.MailData{
+		ReservationID: 3,
+		To:            "guest@example.com",
+		From:          "me@email.com",
+		Subject:       "Welcome",
+		Content:       "<p>Hi</p>",
+		Template:      "basic.html",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 9, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkMailSent(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	mock.ExpectExec("update mail_outbox").WithArgs(models.MailSent, "", sqlmock.AnyArg(), 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkMailSent(context.Background(), 9, nil))
+
+	mock.ExpectExec("update mail_outbox").WithArgs(models.MailFailed, "connection refused", nil, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkMailSent(context.Background(), 9, errors.New("connection refused")))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReservationTimeline(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	at := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("from audit_log").WithArgs("reservations", 3, defaultAuditLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "user_name", "action", "entity_type", "entity_id", "before", "after", "created_at"}).
+			AddRow(1, 7, "Khanh Nguyen", AuditReservationStatus, "reservations", 3, `{"status":"pending"}`, `{"status":"confirmed"}`, at))
+	mock.ExpectQuery("from reservation_notes n").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reservation_id", "user_id", "user_name", "body", "created_at"}).
+			AddRow(1, 3, 7, "Khanh Nguyen", "Late arrival", at.Add(time.Hour)))
+	mock.ExpectQuery("from mail_outbox").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reservation_id", "recipient", "sender", "subject", "content", "template", "status", "error", "created_at", "sent_at"}).
+			AddRow(9, 3, "guest@example.com", "me@email.com", "Welcome", "<p>Hi</p>", "", models.MailFailed, "connection refused", at.Add(2*time.Hour), nil))
+
+	entries, err := repo.ReservationTimeline(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Len(t, entries, 3)
+	assert.Equal(t, models.TimelineEmail, entries[0].Kind)
+	assert.Equal(t, models.MailFailed, entries[0].MailStatus)
+	assert.Equal(t, "Confirmed", entries[2].Title)
+}
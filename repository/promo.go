@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// promoCodeColumns is the select list of promo codes c, with the name of their room type t and the number of
+// reservations booked with them that are not cancelled, read by scanPromoCode
+const promoCodeColumns = `c.id, c.property_id, c.code, c.kind, c.amount, c.valid_from, c.valid_until,
+	coalesce(t.id, 0), coalesce(t.name, ''), c.min_nights, c.max_uses, c.max_uses_per_guest, c.active,
+	(select count(*) from reservations r where r.promo_code_id = c.id and r.status <> 'cancelled'),
+	c.created_at, c.updated_at`
+
+// scanPromoCode reads a row selected with promoCodeColumns
+func scanPromoCode(row rowScanner, c *models.PromoCode) error {
+	var from, until sql.NullTime
+	err := row.Scan(&c.ID, &c.PropertyID, &c.Code, &c.Kind, &c.Amount, &from, &until, &c.RoomType.ID,
+		&c.RoomType.Name, &c.MinNights, &c.MaxUses, &c.MaxUsesPerGuest, &c.Active, &c.Uses, &c.CreatedAt,
+		&c.UpdatedAt)
+	c.ValidFrom, c.ValidUntil = from.Time, until.Time
+	return err
+}
+
+// PromoCodes returns the promo codes of the property, by code
+func (p *postgressDBRepo) PromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "c.property_id", nil)
+	query := `
+		select ` + promoCodeColumns + `
+		from promo_codes c
+		left join room_types t on (t.id = c.room_type_id)
+		where ` + cond + `
+		order by c.code`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []models.PromoCode
+	for rows.Next() {
+		var c models.PromoCode
+		if err := scanPromoCode(rows, &c); err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+	}
+
+	return codes, rows.Err()
+}
+
+// GetPromoCode returns the promo code of the property guests typed as code, in any case
+func (p *postgressDBRepo) GetPromoCode(ctx context.Context, code string) (models.PromoCode, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var c models.PromoCode
+	cond, args := ofProperty(ctx, "c.property_id", []interface{}{models.NormalizePromoCode(code)})
+	query := `
+		select ` + promoCodeColumns + `
+		from promo_codes c
+		left join room_types t on (t.id = c.room_type_id)
+		where c.code = $1 and ` + cond
+
+	return c, mapError(scanPromoCode(p.DB.SQL.QueryRowContext(ctx, query, args...), &c))
+}
+
+// CreatePromoCode adds a promo code to the property of ctx, ErrConflict when the property has the code already
+func (p *postgressDBRepo) CreatePromoCode(ctx context.Context, c models.PromoCode) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var roomTypeID sql.NullInt64
+	if c.RoomType.ID != 0 {
+		roomTypeID = sql.NullInt64{Int64: int64(c.RoomType.ID), Valid: true}
+	}
+
+	query := `
+		insert into promo_codes (property_id, code, kind, amount, valid_from, valid_until, room_type_id, min_nights,
+			max_uses, max_uses_per_guest, active, created_at, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, true, $11, $11) returning id`
+
+	var id int
+	err := p.DB.SQL.QueryRowContext(ctx, query, models.PropertyFromContext(ctx).ID,
+		models.NormalizePromoCode(c.Code), c.Kind, c.Amount, nullTime(c.ValidFrom), nullTime(c.ValidUntil), roomTypeID,
+		c.MinNights, c.MaxUses, c.MaxUsesPerGuest, time.Now()).Scan(&id)
+	return id, mapError(err)
+}
+
+// SetPromoCodeActive turns a promo code of the property on or off, reservations booked with it keep their discount
+func (p *postgressDBRepo) SetPromoCodeActive(ctx context.Context, id int, active bool) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "property_id", []interface{}{id, active, time.Now()})
+	return expectAffected(p.DB.SQL.ExecContext(ctx,
+		"update promo_codes set active = $2, updated_at = $3 where id = $1 and "+cond, args...))
+}
+
+// redeemPromoCode locks promo code id for the rest of tx and tells whether guest email may book with it once
+// more, so that two guests booking at once cannot both take its last use
+func redeemPromoCode(ctx context.Context, tx *sql.Tx, id int, email string) error {
+	cond, args := ofProperty(ctx, "property_id", []interface{}{id})
+	var maxUses, maxUsesPerGuest int
+	err := tx.QueryRowContext(ctx,
+		`select max_uses, max_uses_per_guest from promo_codes where id = $1 and active and `+cond+` for update`,
+		args...).Scan(&maxUses, &maxUsesPerGuest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.NewValidationError("promo_code", "This promo code is no longer available")
+	}
+	if err != nil {
+		return err
+	}
+
+	var uses, guestUses int
+	err = tx.QueryRowContext(ctx, `
+		select count(*), count(*) filter (where lower(email) = lower($2))
+		from reservations
+		where promo_code_id = $1 and status <> 'cancelled'`, id, email).Scan(&uses, &guestUses)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case maxUses > 0 && uses >= maxUses:
+		return models.ErrPromoUsedUp
+	case maxUsesPerGuest > 0 && guestUses >= maxUsesPerGuest:
+		return models.ErrPromoUsedByGuest
+	}
+	return nil
+}
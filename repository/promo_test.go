@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPromoCode(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// codes are looked up the way they are stored, in upper case
+	mock.ExpectQuery(`from promo_codes c left join room_types t on \(t.id = c.room_type_id\) where c.code = \$1 and c.property_id = \$2`).
+		WithArgs("WINTER10", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "property_id", "code", "kind", "amount", "valid_from",
+			"valid_until", "room_type_id", "room_type_name", "min_nights", "max_uses", "max_uses_per_guest", "active",
+			"uses", "created_at", "updated_at"}).
+			AddRow(5, 2, "WINTER10", "percent", 10, nil, until, 0, "", 2, 100, 1, true, 12, at, at))
+
+	c, err := repo.GetPromoCode(ctx, " winter10")
+	assert.NoError(t, err)
+	assert.Equal(t, models.DiscountPercent, c.Kind)
+	assert.True(t, c.ValidFrom.IsZero())
+	assert.Equal(t, until, c.ValidUntil)
+	assert.Equal(t, 12, c.Uses)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+
+	mock.ExpectQuery("insert into promo_codes").
+		WithArgs(2, "SPRING", models.DiscountFixed, 2000, nil, nil, nil, 0, 0, 1, sqlmock.AnyArg()).
+		WillReturnError(pgx.PgError{Code: pgUniqueViolation})
+
+	_, err := repo.CreatePromoCode(ctx, models.PromoCode{Code: "spring", Kind: models.DiscountFixed, Amount: 2000,
+		MaxUsesPerGuest: 1})
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// propertyColumns is the select list read by scanProperty
+const propertyColumns = `p.id, p.name, p.contact_email, p.time_zone, p.currency, p.brand_color, p.logo_url,
+	p.created_at, p.updated_at`
+
+// scanProperty reads a row selected with propertyColumns
+func scanProperty(row rowScanner, p *models.Property) error {
+	return row.Scan(&p.ID, &p.Name, &p.ContactEmail, &p.TimeZone, &p.Currency, &p.BrandColor, &p.LogoURL,
+		&p.CreatedAt, &p.UpdatedAt)
+}
+
+// inProperty returns the condition keeping the rows whose room, in column, belongs to the property of ctx.
+// The property id is appended to args and referenced as the next parameter. Without a property in ctx,
+// e.g. in background jobs, every property is included and the condition is "true".
+func inProperty(ctx context.Context, column string, args []interface{}) (string, []interface{}) {
+	id := models.PropertyFromContext(ctx).ID
+	if id == 0 {
+		return "true", args
+	}
+	args = append(args, id)
+	return fmt.Sprintf("%s in (select id from rooms where property_id = $%d)", column, len(args)), args
+}
+
+// ofProperty returns the condition keeping the rows whose property, in column, is the property of ctx.
+// Like inProperty, the id is appended to args and every property is included without one.
+func ofProperty(ctx context.Context, column string, args []interface{}) (string, []interface{}) {
+	id := models.PropertyFromContext(ctx).ID
+	if id == 0 {
+		return "true", args
+	}
+	args = append(args, id)
+	return fmt.Sprintf("%s = $%d", column, len(args)), args
+}
+
+// queryRower is a database or a transaction
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// checkProperty returns ErrNotFound when row id of table, reservations or room_restrictions, is not in
+// the property of ctx, so that changes made by id cannot reach another property
+func checkProperty(ctx context.Context, q queryRower, table string, id int) error {
+	cond, args := inProperty(ctx, "t.room_id", []interface{}{id})
+	if cond == "true" {
+		return nil
+	}
+
+	// table is one of the entity constants, never user input
+	query := fmt.Sprintf("select count(*) from %s t where t.id = $1 and %s", table, cond)
+
+	var n int
+	if err := q.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// GetPropertyByID returns a property with its settings
+func (p *postgressDBRepo) GetPropertyByID(ctx context.Context, id int) (models.Property, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var prop models.Property
+	row := p.DB.SQL.QueryRowContext(ctx, "select "+propertyColumns+" from properties p where p.id = $1", id)
+	return prop, mapError(scanProperty(row, &prop))
+}
+
+// UserProperties returns the properties a user manages, by name
+func (p *postgressDBRepo) UserProperties(ctx context.Context, userID int) ([]models.Property, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		select ` + propertyColumns + `
+		from properties p
+		join user_properties up on (up.property_id = p.id)
+		where up.user_id = $1
+		order by p.name, p.id`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var properties []models.Property
+	for rows.Next() {
+		var prop models.Property
+		if err := scanProperty(rows, &prop); err != nil {
+			return nil, err
+		}
+		properties = append(properties, prop)
+	}
+
+	return properties, rows.Err()
+}
+
+// UpdateProperty saves the settings of a property
+func (p *postgressDBRepo) UpdateProperty(ctx context.Context, prop models.Property) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		update properties set name = $1, contact_email = $2, time_zone = $3, currency = $4, brand_color = $5,
+			logo_url = $6, updated_at = $7
+		where id = $8`
+
+	return expectAffected(p.DB.SQL.ExecContext(ctx, query, prop.Name, prop.ContactEmail, prop.TimeZone,
+		prop.Currency, prop.BrandColor, prop.LogoURL, time.Now(), prop.ID))
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReservationByID_ScopedToProperty(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+
+	mock.ExpectQuery(`where r.id = \$1 and r.room_id in \(select id from rooms where property_id = \$2\)`).
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetReservationByID(ctx, 3)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateReservation_OtherProperty(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+
+	// the reservation belongs to a room of another property, nothing is changed
+	mock.ExpectBegin()
+	mock.ExpectQuery(`select count\(\*\) from reservations t where t.id = \$1 and t.room_id in \(select id from rooms where property_id = \$2\)`).
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	err := repo.UpdateReservation(ctx, models.Reservation{ID: 3, FirstName: "John"})
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateBlock_RecordsProperty(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+	start := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(0).WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}))
+	mock.ExpectQuery(`select id from rooms where id = \$1 and id in \(select id from rooms where property_id = \$2\) for update`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("select count").WithArgs(1, start, start.AddDate(0, 0, 1), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into room_restrictions").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery("select row_to_json").WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":12}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditBlockCreate, "room_restrictions", 12, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	id, err := repo.CreateBlock(ctx, 1, start, start.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, 12, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserProperties(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`join user_properties up on \(up.property_id = p.id\) where up.user_id = \$1`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "contact_email", "time_zone", "currency", "brand_color",
+			"logo_url", "created_at", "updated_at"}).
+			AddRow(2, "Lakeside", "lake@example.com", "Europe/Paris", "EUR", "#336699", "", at, at).
+			AddRow(1, "My Guesthouse", "me@email.com", "UTC", "EUR", "", "", at, at))
+
+	properties, err := repo.UserProperties(context.Background(), 7)
+	assert.NoError(t, err)
+	if assert.Len(t, properties, 2) {
+		assert.Equal(t, "Lakeside", properties[0].Name)
+		assert.Equal(t, "Europe/Paris", properties[0].Location().String())
+		assert.Equal(t, "UTC", properties[1].Location().String())
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
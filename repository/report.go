@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+)
+
+// Occupancy returns the nights each room is booked and blocked during the period
+func (p *postgressDBRepo) Occupancy(ctx context.Context, period models.ReportPeriod) (models.OccupancyReport, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	// only the nights inside the period count, restriction 1 is a reservation and the others are blocks
+	cond, args := inProperty(ctx, "r.id", []interface{}{period.From, period.To})
+	query := `
+		select r.id, r.room_name,
+			coalesce(sum(least(rr.end_date, $2::date) - greatest(rr.start_date, $1::date)) filter (where rr.restriction_id = 1), 0),
+			coalesce(sum(least(rr.end_date, $2::date) - greatest(rr.start_date, $1::date)) filter (where rr.restriction_id <> 1), 0)
+		from rooms r
+		left join room_restrictions rr on (rr.room_id = r.id and ` + overlaps("rr.", 1, 2) + `)
+		where ` + cond + `
+		group by r.id, r.room_name
+		order by r.room_name`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.OccupancyReport{}, err
+	}
+	defer rows.Close()
+
+	var rooms []models.RoomOccupancy
+	for rows.Next() {
+		var o models.RoomOccupancy
+		if err := rows.Scan(&o.RoomID, &o.RoomName, &o.Nights, &o.Blocked); err != nil {
+			return models.OccupancyReport{}, err
+		}
+		rooms = append(rooms, o)
+	}
+	if err := rows.Err(); err != nil {
+		return models.OccupancyReport{}, err
+	}
+
+	return models.NewOccupancyReport(period, rooms), nil
+}
+
+// Movements counts the arrivals and departures of reservations that are still expected or staying
+func (p *postgressDBRepo) Movements(ctx context.Context, period models.ReportPeriod) (models.Movements, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := inProperty(ctx, "room_id", []interface{}{period.From, period.To})
+	query := `
+		select
+			count(*) filter (where start_date >= $1 and start_date < $2),
+			count(*) filter (where end_date >= $1 and end_date < $2)
+		from reservations
+		where status not in ('cancelled', 'no_show') and start_date < $2 and end_date >= $1 and ` + cond
+
+	var m models.Movements
+	err := p.DB.SQL.QueryRowContext(ctx, query, args...).Scan(&m.Arrivals, &m.Departures)
+	return m, err
+}
+
+// StayStats summarises the reservations arriving during the period
+func (p *postgressDBRepo) StayStats(ctx context.Context, period models.ReportPeriod) (models.StayStats, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	stats := models.StayStats{Period: period, LeadTimes: models.NewLeadTimes()}
+
+	cond, args := inProperty(ctx, "room_id", []interface{}{period.From, period.To})
+	query := `
+		select
+			count(*) filter (where status <> 'cancelled'),
+			coalesce(sum(end_date - start_date) filter (where status <> 'cancelled'), 0),
+			count(*) filter (where status = 'cancelled'),
+			count(*) filter (where status = 'no_show'),
+			coalesce(sum(total) filter (where status <> 'cancelled'), 0),
+			coalesce(sum(discount) filter (where status <> 'cancelled'), 0)
+		from reservations
+		where start_date >= $1 and start_date < $2 and ` + cond
+
+	err := p.DB.SQL.QueryRowContext(ctx, query, args...).
+		Scan(&stats.Reservations, &stats.Nights, &stats.Cancellations, &stats.NoShows, &stats.Revenue, &stats.Discounts)
+	if err != nil {
+		return stats, err
+	}
+
+	// lead time is counted in days from booking to arrival
+	query = `
+		select greatest(start_date - created_at::date, 0) as lead, count(*)
+		from reservations
+		where start_date >= $1 and start_date < $2 and status <> 'cancelled' and ` + cond + `
+		group by lead`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var days, n int
+		if err := rows.Scan(&days, &n); err != nil {
+			return stats, err
+		}
+		stats.LeadTimes.Add(days, n)
+	}
+
+	return stats, rows.Err()
+}
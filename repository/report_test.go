@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOccupancy(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	period := models.ReportPeriod{
+		From: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 11, 11, 0, 0, 0, 0, time.UTC),
+	}
+	mock.ExpectQuery("from rooms r left join room_restrictions rr").WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "room_name", "nights", "blocked"}).
+			AddRow(1, "General's Quarters", 5, 0).
+			AddRow(2, "Major Suite", 0, 10))
+
+	report, err := repo.Occupancy(context.Background(), period)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Len(t, report.Rooms, 2)
+	assert.Equal(t, 0.5, report.Rooms[0].Rate)
+	assert.Equal(t, 0.5, report.Overall.Rate)
+}
+
+func TestStayStats(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	period := models.ReportPeriod{
+		From: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mock.ExpectQuery("select count").WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"reservations", "nights", "cancellations", "no_shows", "revenue",
+			"discounts"}).AddRow(3, 9, 1, 0, 108000, 4500))
+	mock.ExpectQuery("select greatest").WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"lead", "count"}).AddRow(0, 1).AddRow(45, 2))
+
+	stats, err := repo.StayStats(context.Background(), period)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 3.0, stats.AverageStay())
+	assert.Equal(t, models.Money(12000), stats.AverageDailyRate())
+	assert.Equal(t, 1, stats.LeadTimes[0].Count)
+	assert.Equal(t, 2, stats.LeadTimes[3].Count)
+}
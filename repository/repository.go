@@ -2,27 +2,64 @@ package repository
 
 import (
 	"booking/models"
+	"context"
 	"time"
 )
 
 //go:generate mockgen -destination=../mocks/mock_database_repo.go -package=mocks -source=${GOFILE}
 type DatabaseRepo interface {
-	AllUsers() bool
-	InsertReservation(res models.Reservation) (int, error)
-	InsertRoomRestriction(r models.RoomRestriction) (int, error)
-	SearchAvailabilityByDatesByRoomID(roomID int, start, end time.Time) (bool, error)
-	SearchAvailabilityForAllRooms(start, end time.Time) ([]models.Room, error)
-	GetRoomByID(id int) (models.Room, error)
-	UpdateUser(u models.User) error
-	Authenticate(email, testPassword string) (int, string, error)
-	AllReservations() ([]models.Reservation, error)
-	AllNewReservations() ([]models.Reservation, error)
-	GetReservationByID(id int) (models.Reservation, error)
-	UpdateReservation(r models.Reservation) error
-	DeleteReservation(id int) error
-	UpdateProcessedForReservation(id, processed int) error
-	AllRooms() ([]models.Room, error)
-	GetRestrictionsForRoomByDate(roomID int, start, end time.Time) ([]models.RoomRestriction, error)
-	InsertBlockForRoom(id int, startDate time.Time) error
-	DeleteBlockByID(id int) error
+	AllUsers(ctx context.Context) bool
+	InsertReservation(ctx context.Context, res models.Reservation) (int, error)
+	InsertRoomRestriction(ctx context.Context, r models.RoomRestriction) (int, error)
+	SearchAvailabilityByDatesByRoomID(ctx context.Context, roomID int, start, end time.Time) (bool, error)
+	SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time, guests int) ([]models.Room, error)
+	GetRoomByID(ctx context.Context, id int) (models.Room, error)
+	UpdateUser(ctx context.Context, u models.User) error
+	Authenticate(ctx context.Context, email, testPassword string) (int, string, error)
+	Reservations(ctx context.Context, filter models.ReservationFilter) (models.ReservationPage, error)
+	EachReservation(ctx context.Context, filter models.ReservationFilter, fn func(models.Reservation) error) error
+	ImportReservations(ctx context.Context, rows []models.ImportRow, commit bool) error
+	GetReservationByID(ctx context.Context, id int) (models.Reservation, error)
+	UpdateReservation(ctx context.Context, r models.Reservation) error
+	CancelReservation(ctx context.Context, id int, reason string, on time.Time) (models.Cancellation, error)
+	RestoreReservation(ctx context.Context, id int) error
+	CancelledReservations(ctx context.Context) ([]models.Reservation, error)
+	PurgeCancelledReservations(ctx context.Context, before time.Time) (int, error)
+	TransitionReservation(ctx context.Context, id int, to models.ReservationStatus) error
+	CreateReservation(ctx context.Context, res models.Reservation) (int, error)
+	MoveReservation(ctx context.Context, id, roomID int, start, end time.Time) error
+	AllRooms(ctx context.Context) ([]models.Room, error)
+	InsertBlockForRoom(ctx context.Context, id int, startDate time.Time) error
+	CreateBlock(ctx context.Context, roomID int, start, end time.Time) (int, error)
+	RestrictionsInRange(ctx context.Context, from, to time.Time) ([]models.RoomRestriction, error)
+	DeleteBlockByID(ctx context.Context, id int) error
+	AuditLog(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error)
+	Occupancy(ctx context.Context, period models.ReportPeriod) (models.OccupancyReport, error)
+	Movements(ctx context.Context, period models.ReportPeriod) (models.Movements, error)
+	StayStats(ctx context.Context, period models.ReportPeriod) (models.StayStats, error)
+	FrontDesk(ctx context.Context, day time.Time) (models.FrontDesk, error)
+	AddReservationNote(ctx context.Context, reservationID int, body string) (int, error)
+	ReservationTimeline(ctx context.Context, id int) ([]models.TimelineEntry, error)
+	QueueMail(ctx context.Context, msg models.MailData) (int, error)
+	MarkMailSent(ctx context.Context, id int, sendErr error) error
+	GetOutboxMail(ctx context.Context, id int) (models.OutboxMail, error)
+	GetPropertyByID(ctx context.Context, id int) (models.Property, error)
+	UserProperties(ctx context.Context, userID int) ([]models.Property, error)
+	UpdateProperty(ctx context.Context, p models.Property) error
+	RoomTypes(ctx context.Context) ([]models.RoomType, error)
+	GetRoomTypeByID(ctx context.Context, id int) (models.RoomType, error)
+	SearchAvailabilityByType(ctx context.Context, start, end time.Time, guests int) ([]models.TypeAvailability, error)
+	ReserveRoomType(ctx context.Context, typeID int, res models.Reservation) (models.Reservation, error)
+	StayRules(ctx context.Context) (models.StayRules, error)
+	CreateStayRule(ctx context.Context, r models.StayRule) (int, error)
+	DeleteStayRule(ctx context.Context, id int) error
+	SetRate(ctx context.Context, typeID int, rate models.Money, policyID int) error
+	PromoCodes(ctx context.Context) ([]models.PromoCode, error)
+	GetPromoCode(ctx context.Context, code string) (models.PromoCode, error)
+	CreatePromoCode(ctx context.Context, c models.PromoCode) (int, error)
+	SetPromoCodeActive(ctx context.Context, id int, active bool) error
+	CancellationPolicies(ctx context.Context) ([]models.CancellationPolicy, error)
+	GetCancellationPolicy(ctx context.Context, id int) (models.CancellationPolicy, error)
+	CreateCancellationPolicy(ctx context.Context, c models.CancellationPolicy) (int, error)
+	DeleteCancellationPolicy(ctx context.Context, id int) error
 }
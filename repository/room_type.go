@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// roomTypeColumns is the select list of room types t read by scanRoomType
+const roomTypeColumns = `t.id, t.property_id, t.name, t.description, t.max_occupancy, t.nightly_rate,
+	coalesce(t.cancellation_policy_id, 0), t.created_at, t.updated_at`
+
+// scanRoomType reads a row selected with roomTypeColumns followed by the columns in rest
+func scanRoomType(row rowScanner, t *models.RoomType, rest ...interface{}) error {
+	dest := []interface{}{&t.ID, &t.PropertyID, &t.Name, &t.Description, &t.MaxOccupancy, &t.NightlyRate,
+		&t.CancellationPolicyID, &t.CreatedAt, &t.UpdatedAt}
+	return row.Scan(append(dest, rest...)...)
+}
+
+// RoomTypes returns the room types of the property with their rooms, by name
+func (p *postgressDBRepo) RoomTypes(ctx context.Context) ([]models.RoomType, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "t.property_id", nil)
+	query := `
+		select ` + roomTypeColumns + `, r.id, r.room_name
+		from room_types t
+		left join rooms r on (r.room_type_id = t.id)
+		where ` + cond + `
+		order by t.name, t.id, r.room_name, r.id`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []models.RoomType
+	for rows.Next() {
+		var t models.RoomType
+		var roomID sql.NullInt64
+		var roomName sql.NullString
+		if err := scanRoomType(rows, &t, &roomID, &roomName); err != nil {
+			return nil, err
+		}
+
+		// one row per room, a type without rooms has a single row of nulls
+		if len(types) == 0 || types[len(types)-1].ID != t.ID {
+			types = append(types, t)
+		}
+		if roomID.Valid {
+			last := &types[len(types)-1]
+			last.Rooms = append(last.Rooms, models.Room{
+				ID:         int(roomID.Int64),
+				PropertyID: t.PropertyID,
+				RoomTypeID: t.ID,
+				RoomName:   roomName.String,
+			})
+		}
+	}
+
+	return types, rows.Err()
+}
+
+// GetRoomTypeByID returns a room type of the property, without its rooms
+func (p *postgressDBRepo) GetRoomTypeByID(ctx context.Context, id int) (models.RoomType, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var t models.RoomType
+	cond, args := ofProperty(ctx, "t.property_id", []interface{}{id})
+	row := p.DB.SQL.QueryRowContext(ctx, "select "+roomTypeColumns+" from room_types t where t.id = $1 and "+cond, args...)
+	return t, mapError(scanRoomType(row, &t))
+}
+
+// SetRate changes the price of a night in a room type of the property and the cancellation policy stays in
+// it are booked on, 0 for free cancellation. Booked stays keep their price and policy.
+func (p *postgressDBRepo) SetRate(ctx context.Context, typeID int, rate models.Money, policyID int) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var cancellationPolicyID sql.NullInt64
+	if policyID != 0 {
+		cancellationPolicyID = sql.NullInt64{Int64: int64(policyID), Valid: true}
+	}
+
+	cond, args := ofProperty(ctx, "property_id", []interface{}{typeID, rate, cancellationPolicyID, time.Now()})
+	query := `
+		update room_types set nightly_rate = $2, cancellation_policy_id = $3, updated_at = $4
+		where id = $1 and ` + cond
+	return expectAffected(p.DB.SQL.ExecContext(ctx, query, args...))
+}
+
+// SearchAvailabilityByType returns, for every room type of the property with rooms sleeping guests, how many
+// rooms it has and how many of them have no reservation or block overlapping the stay from start to end
+func (p *postgressDBRepo) SearchAvailabilityByType(ctx context.Context, start, end time.Time, guests int) ([]models.TypeAvailability, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "t.property_id", []interface{}{start, end, guests})
+	query := `
+		select ` + roomTypeColumns + `,
+			count(r.id),
+			count(r.id) filter (where not exists (
+				select 1 from room_restrictions rr where rr.room_id = r.id and ` + overlaps("rr.", 1, 2) + `))
+		from room_types t
+		join rooms r on (r.room_type_id = t.id)
+		where t.max_occupancy >= $3 and ` + cond + `
+		group by t.id
+		order by t.name, t.id`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var availability []models.TypeAvailability
+	for rows.Next() {
+		var a models.TypeAvailability
+		if err := scanRoomType(rows, &a.RoomType, &a.Units, &a.Available); err != nil {
+			return nil, err
+		}
+		availability = append(availability, a)
+	}
+
+	return availability, rows.Err()
+}
+
+// ReserveRoomType books a stay for a guest in a room of type typeID, the system picks the room: the first
+// one of the type that is free for the whole stay is assigned to the reservation. The rooms of the type are
+// locked while choosing, so when two guests book the last room at once the second one gets an ErrConflict.
+// A promo code of the reservation is locked as well and models.ErrPromoUsedUp or
+// models.ErrPromoUsedByGuest returned past its limits.
+func (p *postgressDBRepo) ReserveRoomType(ctx context.Context, typeID int, res models.Reservation) (models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		if res.PromoCode.ID != 0 {
+			if err := redeemPromoCode(ctx, tx, res.PromoCode.ID, res.Email); err != nil {
+				return err
+			}
+		}
+
+		cond, args := ofProperty(ctx, "property_id", []interface{}{typeID})
+		rows, err := tx.QueryContext(ctx, `select id from rooms where room_type_id = $1 and `+cond+` order by id for update`, args...)
+		if err != nil {
+			return err
+		}
+		var roomIDs []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			roomIDs = append(roomIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(roomIDs) == 0 {
+			return models.NewValidationError("room_type_id", fmt.Sprintf("room type %d does not exist", typeID))
+		}
+
+		for _, id := range roomIDs {
+			taken, err := roomTaken(ctx, tx, id, res.StartDate, res.EndDate, 0)
+			if err != nil {
+				return err
+			}
+			if !taken {
+				res.RoomID = id
+				return insertReservation(ctx, tx, &res, AuditReservationCreate)
+			}
+		}
+		return fmt.Errorf("%w: every room of this type is booked or blocked for these dates", models.ErrConflict)
+	})
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	return res, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// roomTypeRows returns rows shaped like roomTypeColumns followed by extra columns
+func roomTypeRows(extra ...string) *sqlmock.Rows {
+	return sqlmock.NewRows(append([]string{"id", "property_id", "name", "description", "max_occupancy", "nightly_rate",
+		"cancellation_policy_id", "created_at", "updated_at"}, extra...))
+}
+
+func TestRoomTypes(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`left join rooms r on \(r.room_type_id = t.id\) where t.property_id = \$1`).
+		WithArgs(2).
+		WillReturnRows(roomTypeRows("room_id", "room_name").
+			AddRow(3, 2, "Standard Double", "", 2, 9500, 0, at, at, 11, "101").
+			AddRow(3, 2, "Standard Double", "", 2, 9500, 0, at, at, 12, "102").
+			AddRow(4, 2, "Suite", "", 4, 18000, 0, at, at, nil, nil))
+
+	types, err := repo.RoomTypes(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, types, 2) {
+		assert.Equal(t, []models.Room{
+			{ID: 11, PropertyID: 2, RoomTypeID: 3, RoomName: "101"},
+			{ID: 12, PropertyID: 2, RoomTypeID: 3, RoomName: "102"},
+		}, types[0].Rooms)
+		assert.Empty(t, types[1].Rooms)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchAvailabilityByType(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+
+	// free rooms are counted with the same overlap rule as every other availability query, among the types
+	// sleeping every guest
+	mock.ExpectQuery(`rr.room_id = r.id and rr.start_date < \$2 and rr.end_date > \$1\)\) .* where t.max_occupancy >= \$3 and t.property_id = \$4 group by t.id`).
+		WithArgs(start, end, 3, 2).
+		WillReturnRows(roomTypeRows("units", "available").
+			AddRow(3, 2, "Standard Double", "", 2, 9500, 0, at, at, 4, 1).
+			AddRow(4, 2, "Suite", "", 4, 18000, 0, at, at, 1, 0))
+
+	availability, err := repo.SearchAvailabilityByType(ctx, start, end, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, availability, 2) {
+		assert.Equal(t, "Standard Double", availability[0].RoomType.Name)
+		assert.Equal(t, 4, availability[0].Units)
+		assert.Equal(t, 1, availability[0].Available)
+		assert.Equal(t, 0, availability[1].Available)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveRoomType(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	res := models.Reservation{FirstName: "Khanh", StartDate: start, EndDate: end, Adults: 2, Children: 1,
+		Status: models.StatusPending}
+
+	// the first room of the type is taken, the second one is assigned
+	mock.ExpectBegin()
+	mock.ExpectQuery(`select id from rooms where room_type_id = \$1 and true order by id for update`).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+	mock.ExpectQuery("select count").WithArgs(11, start, end, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("select count").WithArgs(12, start, end, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into reservations").
+		WithArgs("Khanh", "", "", "", start, end, 12, models.StatusPending, 2, 1, models.Money(0), models.Money(0),
+			models.Money(0), sql.NullInt64{}, "", "", 0, 0, false,
+			sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+	mock.ExpectExec("insert into room_restrictions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(41).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":41}`))
+	mock.ExpectExec("insert into audit_log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	booked, err := repo.ReserveRoomType(context.Background(), 3, res)
+	assert.NoError(t, err)
+	assert.Equal(t, 41, booked.ID)
+	assert.Equal(t, 12, booked.RoomID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// every room is taken
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery("select count").WithArgs(11, start, end, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	_, err = repo.ReserveRoomType(context.Background(), 3, res)
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// a type without rooms, or of another property, is reported on the form field
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from rooms").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err = repo.ReserveRoomType(context.Background(), 3, res)
+	var verr *models.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "room_type_id", verr.Field)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveRoomType_PromoCode(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	res := models.Reservation{FirstName: "Khanh", Email: "Khanh@example.com", StartDate: start, EndDate: end,
+		Adults: 2, Status: models.StatusPending}
+	res.Price(models.Quote{Subtotal: 19000, Discount: 1900, Total: 17100,
+		PromoCode: models.PromoCode{ID: 5, Code: "WINTER10"}})
+
+	// the code is locked before its uses are counted, overall and for the email of the guest
+	mock.ExpectBegin()
+	mock.ExpectQuery(`select max_uses, max_uses_per_guest from promo_codes where id = \$1 and active and true for update`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"max_uses", "max_uses_per_guest"}).AddRow(100, 1))
+	mock.ExpectQuery(`filter \(where lower\(email\) = lower\(\$2\)\)`).WithArgs(5, "Khanh@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(12, 0))
+	mock.ExpectQuery("select id from rooms").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery("select count").WithArgs(11, start, end, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("insert into reservations").
+		WithArgs("Khanh", "", "Khanh@example.com", "", start, end, 11, models.StatusPending, 2, 0, models.Money(19000),
+			models.Money(1900), models.Money(17100), sql.NullInt64{Int64: 5, Valid: true}, "WINTER10", "", 0, 0, false,
+			sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+	mock.ExpectExec("insert into room_restrictions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(41).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"id":41}`))
+	mock.ExpectExec("insert into audit_log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.ReserveRoomType(context.Background(), 3, res)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// the guest used the code already
+	mock.ExpectBegin()
+	mock.ExpectQuery("from promo_codes").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"max_uses", "max_uses_per_guest"}).AddRow(100, 1))
+	mock.ExpectQuery("from reservations").WithArgs(5, "Khanh@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(13, 1))
+	mock.ExpectRollback()
+
+	_, err = repo.ReserveRoomType(context.Background(), 3, res)
+	assert.Equal(t, models.ErrPromoUsedByGuest, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// statusTimestamps names the column recording when a reservation entered each status
+var statusTimestamps = map[models.ReservationStatus]string{
+	models.StatusConfirmed:  "confirmed_at",
+	models.StatusCheckedIn:  "checked_in_at",
+	models.StatusCheckedOut: "checked_out_at",
+	models.StatusCancelled:  "cancelled_at",
+	models.StatusNoShow:     "no_show_at",
+}
+
+// transitionStatus moves a reservation to status to if the lifecycle allows it from its current status
+func transitionStatus(ctx context.Context, tx *sql.Tx, id int, to models.ReservationStatus) error {
+	column, ok := statusTimestamps[to]
+	if !ok {
+		return models.NewValidationError("status", fmt.Sprintf("a reservation cannot be moved back to %s", to))
+	}
+
+	var from models.ReservationStatus
+	err := tx.QueryRowContext(ctx, `select status from reservations where id = $1 for update`, id).Scan(&from)
+	if err != nil {
+		return mapError(err)
+	}
+
+	if err := from.ValidateTransition(to); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`update reservations set status = $1, %s = $2, updated_at = $2 where id = $3`, column)
+	return expectAffected(tx.ExecContext(ctx, query, to, time.Now(), id))
+}
+
+// TransitionReservation moves a reservation along its lifecycle. Cancellations go through CancelReservation,
+// which needs the day at the property to work out the penalty.
+func (p *postgressDBRepo) TransitionReservation(ctx context.Context, id int, to models.ReservationStatus) error {
+	if to == models.StatusCancelled {
+		return models.NewValidationError("status", "reservations are cancelled with CancelReservation")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return p.auditedChange(ctx, AuditReservationStatus, entityReservation, id, func(tx *sql.Tx) (int, error) {
+		return id, transitionStatus(ctx, tx, id, to)
+	})
+}
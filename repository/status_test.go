@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionReservation(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"status":"confirmed"}`))
+	mock.ExpectQuery("select status from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("confirmed"))
+	mock.ExpectExec("update reservations set status = \\$1, checked_in_at = \\$2").
+		WithArgs(models.StatusCheckedIn, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"status":"checked_in"}`))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(sqlmock.AnyArg(), AuditReservationStatus, "reservations", 3, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.TransitionReservation(context.Background(), 3, models.StatusCheckedIn)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransitionReservation_NotAllowed(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select row_to_json").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(`{"status":"pending"}`))
+	mock.ExpectQuery("select status from reservations").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending"))
+	mock.ExpectRollback()
+
+	err := repo.TransitionReservation(context.Background(), 3, models.StatusCheckedOut)
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransitionReservation_Cancelled(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+
+	// the penalty needs the day at the property, which only CancelReservation is given
+	err := repo.TransitionReservation(context.Background(), 3, models.StatusCancelled)
+	var validationErr *models.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
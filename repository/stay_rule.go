@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// stayRuleColumns is the select list of stay rules s, with the name of their room type t, read by scanStayRule
+const stayRuleColumns = `s.id, s.property_id, coalesce(t.id, 0), coalesce(t.name, ''), s.start_date, s.end_date,
+	s.weekdays, s.min_nights, s.max_nights, s.closed_to_arrival, s.closed_to_departure, s.cutoff_hour,
+	s.max_advance_days, s.created_at, s.updated_at`
+
+// scanStayRule reads a row selected with stayRuleColumns
+func scanStayRule(row rowScanner, r *models.StayRule) error {
+	var start, end sql.NullTime
+	err := row.Scan(&r.ID, &r.PropertyID, &r.RoomType.ID, &r.RoomType.Name, &start, &end, &r.Weekdays,
+		&r.MinNights, &r.MaxNights, &r.ClosedToArrival, &r.ClosedToDeparture, &r.CutoffHour, &r.MaxAdvanceDays,
+		&r.CreatedAt, &r.UpdatedAt)
+	r.StartDate, r.EndDate = start.Time, end.Time
+	return err
+}
+
+// nullTime is null for the zero time, e.g. an open end of a period
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// StayRules returns the stay rules of the property, the rules of every room type first
+func (p *postgressDBRepo) StayRules(ctx context.Context) (models.StayRules, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "s.property_id", nil)
+	query := `
+		select ` + stayRuleColumns + `
+		from stay_rules s
+		left join room_types t on (t.id = s.room_type_id)
+		where ` + cond + `
+		order by s.room_type_id nulls first, s.start_date nulls first, s.id`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules models.StayRules
+	for rows.Next() {
+		var r models.StayRule
+		if err := scanStayRule(rows, &r); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+// CreateStayRule adds a stay rule to the property of ctx
+func (p *postgressDBRepo) CreateStayRule(ctx context.Context, r models.StayRule) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var roomTypeID sql.NullInt64
+	if r.RoomType.ID != 0 {
+		roomTypeID = sql.NullInt64{Int64: int64(r.RoomType.ID), Valid: true}
+	}
+
+	query := `
+		insert into stay_rules (property_id, room_type_id, start_date, end_date, weekdays, min_nights, max_nights,
+			closed_to_arrival, closed_to_departure, cutoff_hour, max_advance_days, created_at, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12) returning id`
+
+	var id int
+	err := p.DB.SQL.QueryRowContext(ctx, query, models.PropertyFromContext(ctx).ID, roomTypeID,
+		nullTime(r.StartDate), nullTime(r.EndDate), r.Weekdays, r.MinNights, r.MaxNights, r.ClosedToArrival,
+		r.ClosedToDeparture, r.CutoffHour, r.MaxAdvanceDays, time.Now()).Scan(&id)
+	return id, mapError(err)
+}
+
+// DeleteStayRule removes a stay rule of the property
+func (p *postgressDBRepo) DeleteStayRule(ctx context.Context, id int) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	cond, args := ofProperty(ctx, "property_id", []interface{}{id})
+	return expectAffected(p.DB.SQL.ExecContext(ctx, "delete from stay_rules where id = $1 and "+cond, args...))
+}
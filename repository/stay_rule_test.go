@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStayRules(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`from stay_rules s left join room_types t on \(t.id = s.room_type_id\) where s.property_id = \$1`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "property_id", "room_type_id", "room_type_name", "start_date",
+			"end_date", "weekdays", "min_nights", "max_nights", "closed_to_arrival", "closed_to_departure",
+			"cutoff_hour", "max_advance_days", "created_at", "updated_at"}).
+			AddRow(1, 2, 0, "", nil, nil, 0, 0, 0, false, false, 18, 365, at, at).
+			AddRow(2, 2, 3, "Standard Double", start, start.AddDate(0, 0, 14), 64, 3, 0, true, false, 0, 0, at, at))
+
+	rules, err := repo.StayRules(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.True(t, rules[0].StartDate.IsZero())
+		assert.Equal(t, 18, rules[0].CutoffHour)
+		assert.Equal(t, "Standard Double", rules[1].RoomType.Name)
+		assert.Equal(t, start, rules[1].StartDate)
+		assert.True(t, rules[1].Weekdays.Has(time.Saturday))
+		assert.False(t, rules[1].Weekdays.Has(time.Sunday))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStayRule(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+
+	// open dates and every room type are stored as nulls
+	mock.ExpectQuery("insert into stay_rules").
+		WithArgs(2, nil, nil, nil, 0, 2, 0, false, false, 0, 0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+	id, err := repo.CreateStayRule(ctx, models.StayRule{MinNights: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteStayRule_OtherProperty(t *testing.T) {
+	repo, mock := newTestPostgresRepo(t)
+	ctx := models.WithProperty(context.Background(), models.Property{ID: 2})
+
+	mock.ExpectExec(`delete from stay_rules where id = \$1 and property_id = \$2`).
+		WithArgs(5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteStayRule(ctx, 5)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
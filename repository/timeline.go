@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"booking/models"
+	"context"
+)
+
+// ReservationTimeline returns the notes, emails and audited changes of a reservation, newest first
+func (p *postgressDBRepo) ReservationTimeline(ctx context.Context, id int) ([]models.TimelineEntry, error) {
+	changes, err := p.AuditLog(ctx, models.AuditFilter{EntityType: entityReservation, EntityID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		select ` + noteColumns + `
+		from reservation_notes n
+		left join users u on (u.id = n.user_id)
+		where n.reservation_id = $1
+		order by n.created_at`
+
+	rows, err := p.DB.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []models.ReservationNote
+	for rows.Next() {
+		var n models.ReservationNote
+		if err := scanNote(rows, &n); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `select ` + outboxColumns + ` from mail_outbox where reservation_id = $1 order by created_at`
+
+	mailRows, err := p.DB.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer mailRows.Close()
+
+	var mails []models.OutboxMail
+	for mailRows.Next() {
+		var m models.OutboxMail
+		if err := scanOutboxMail(mailRows, &m); err != nil {
+			return nil, err
+		}
+		mails = append(mails, m)
+	}
+	if err := mailRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return models.NewTimeline(notes, mails, changes), nil
+}
@@ -6,4 +6,6 @@ then
     rm -f booking
 fi
 go build -o booking app/web/*.go
+./booking migrate -dbname=booking -dbuser=postgres -dbpass=postgres up
+./booking migrate -dbname=booking -dbuser=postgres -dbpass=postgres -set=seed up
 ./booking -dbname=booking -dbuser=postgres -dbpass=postgres -cache=false
@@ -151,7 +151,7 @@ function Prompt() {
     }
 }
 
-function buttonHandler(roomID, csrfToken) {
+function buttonHandler(roomTypeID, csrfToken) {
         let html = `
         <form id="check-availability-form" action="/search-availability-json" method="post" novalidate class="needs-validation" autocomplete="off">
             <div class="row">
@@ -167,6 +167,14 @@ function buttonHandler(roomID, csrfToken) {
                     </div>
                 </div>
             </div>
+            <div class="row mt-3">
+                <div class="col">
+                    <input required class="form-control" type="number" name="adults" id="adults" value="1" min="1" placeholder="Adults">
+                </div>
+                <div class="col">
+                    <input required class="form-control" type="number" name="children" id="children" value="0" min="0" placeholder="Children">
+                </div>
+            </div>
         </form>
         `;
         attention.custom({
@@ -188,7 +196,7 @@ function buttonHandler(roomID, csrfToken) {
                 let form = document.getElementById("check-availability-form");
                 let formData = new FormData(form);
                 formData.append("csrf_token", csrfToken);
-                formData.append("room_id", roomID);
+                formData.append("room_type_id", roomTypeID);
 
                     fetch("/search-availability-json", {
                             method: "post",
@@ -201,11 +209,15 @@ function buttonHandler(roomID, csrfToken) {
                                     icon: 'success',
                                     msg: '<p>Room is available!</p>'
                                         + '<p><a href="/book-room?id='
-                                        + data.room_id
+                                        + data.room_type_id
                                         + '&s='
                                         + data.start_date
                                         + '&e='
                                         + data.end_date
+                                        + '&a='
+                                        + data.adults
+                                        + '&c='
+                                        + data.children
                                         + '" class="btn btn-primary">'
                                         + 'Book now!</a></p>',
 
@@ -213,7 +225,7 @@ function buttonHandler(roomID, csrfToken) {
                                 })
                             } else {
                                 attention.error({
-                                    msg: "No availability",
+                                    msg: data.message || "No availability",
                                 });
                             }
                     })